@@ -4,12 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"io/ioutil"
+	"os"
 	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
-	"github.com/spf13/viper"
 )
 
 // CacheDriver defines the interface for different cache drivers.
@@ -29,49 +30,15 @@ type Cache struct {
 	onEvicted         func(key string, value interface{})
 }
 
-var (
-	cacheInstance *Cache
-	once          sync.Once
-	ErrCacheMiss  = errors.New("cache: key not found")
-)
-
-// NewCache initializes a new Cache instance.
-func InitializeCache(defaultExpiration time.Duration, onEvicted func(key string, value interface{})) error {
-	var err error
-	once.Do(func() {
-		var driver CacheDriver
-		cacheType := viper.GetString("cache.type")
-
-		switch cacheType {
-		case "redis":
-			client := redis.NewClient(&redis.Options{
-				Addr:     viper.GetString("cache.redis.addr"),
-				Password: viper.GetString("cache.redis.password"),
-				DB:       viper.GetInt("cache.redis.db"),
-			})
-			driver = NewRedisCache(client, defaultExpiration, onEvicted)
-		case "file":
-			filePath := viper.GetString("cache.file.path")
-			driver = NewFileCache(filePath)
-		case "memory":
-			driver = NewInMemoryCache(onEvicted)
-		default:
-			err = errors.New("unsupported cache type")
-			return
-		}
-
-		cacheInstance = &Cache{
-			driver:            driver,
-			defaultExpiration: defaultExpiration,
-			onEvicted:         onEvicted,
-		}
-	})
-	return err
-}
+// ErrCacheMiss is returned when a key is not found in the cache.
+var ErrCacheMiss = errors.New("cache: key not found")
 
-// GetCache returns the initialized Cache instance.
-func GetCacheInstance() *Cache {
-	return cacheInstance
+// NewCache wraps driver in a Cache.
+func NewCache(driver CacheDriver, defaultExpiration time.Duration) *Cache {
+	return &Cache{
+		driver:            driver,
+		defaultExpiration: defaultExpiration,
+	}
 }
 
 // Get retrieves a value from the cache.
@@ -107,66 +74,102 @@ func (c *Cache) Stats() (CacheStats, error) {
 	return c.driver.Stats()
 }
 
+// Close releases the resources held by the driver, if any.
+func (c *Cache) Close() error {
+	if closer, ok := c.driver.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
 // InMemoryCache is an in-memory cache implementation.
 type InMemoryCache struct {
 	cache     map[string][]byte
 	expiry    map[string]time.Time
 	mutex     sync.RWMutex
+	janitor   sync.Once
 	onEvicted func(string, interface{})
+	stats     statsCounter
+	options   driverOptions
 }
 
 // NewInMemoryCache initializes a new in-memory cache.
-func NewInMemoryCache(onEvicted func(key string, value interface{})) *InMemoryCache {
+func NewInMemoryCache(onEvicted func(key string, value interface{}), opts ...Option) *InMemoryCache {
 	return &InMemoryCache{
 		cache:     make(map[string][]byte),
 		expiry:    make(map[string]time.Time),
 		mutex:     sync.RWMutex{},
 		onEvicted: onEvicted,
+		options:   newDriverOptions(opts),
 	}
 }
 
 // Get retrieves a value from the in-memory cache.
 func (c *InMemoryCache) Get(key string, value interface{}) error {
+	data, err := c.getBytes(key)
+	c.stats.record(err)
+	if err != nil {
+		return err
+	}
+
+	return c.options.serializer.Unmarshal(data, value)
+}
+
+// getBytes returns the encoded value stored under key, ignoring expired entries.
+func (c *InMemoryCache) getBytes(key string) ([]byte, error) {
+	key = c.options.key(key)
+
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
 
-	if data, ok := c.cache[key]; ok {
-		return json.Unmarshal(data, value)
+	data, ok := c.cache[key]
+	if !ok || time.Now().After(c.expiry[key]) {
+		return nil, ErrCacheMiss
 	}
 
-	return ErrCacheMiss
+	return data, nil
 }
 
 // Set stores a value in the in-memory cache.
 func (c *InMemoryCache) Set(key string, value interface{}, expiration time.Duration) error {
-	if expiration == 0 {
-		expiration = time.Minute
-	}
-
-	data, err := json.Marshal(value)
+	data, err := c.options.serializer.Marshal(value)
 	if err != nil {
 		return err
 	}
 
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	c.setBytes(key, data, expiration)
+	c.stats.set()
+	return nil
+}
+
+// setBytes stores an already encoded value in the in-memory cache.
+func (c *InMemoryCache) setBytes(key string, data []byte, expiration time.Duration) {
+	if expiration == 0 {
+		expiration = time.Minute
+	}
+	key = c.options.key(key)
 
+	c.mutex.Lock()
 	c.cache[key] = data
 	c.expiry[key] = time.Now().Add(expiration)
+	c.mutex.Unlock()
 
-	go c.startJanitor()
-
-	return nil
+	c.janitor.Do(func() {
+		go c.startJanitor()
+	})
 }
 
 // Delete removes a value from the in-memory cache.
 func (c *InMemoryCache) Delete(key string) error {
+	key = c.options.key(key)
+
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
 	if _, ok := c.cache[key]; ok {
 		delete(c.cache, key)
 		delete(c.expiry, key)
+		c.stats.delete()
 		return nil
 	}
 
@@ -175,11 +178,13 @@ func (c *InMemoryCache) Delete(key string) error {
 
 // Exists checks if a key exists in the in-memory cache.
 func (c *InMemoryCache) Exists(key string) (bool, error) {
+	key = c.options.key(key)
+
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
 
-	_, ok := c.cache[key]
-	return ok, nil
+	expiry, ok := c.expiry[key]
+	return ok && time.Now().Before(expiry), nil
 }
 
 // Flush clears all items from the in-memory cache.
@@ -198,7 +203,13 @@ func (c *InMemoryCache) Stats() (CacheStats, error) {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
 
-	return CacheStats{ItemsCount: int64(len(c.cache))}, nil
+	stats := c.stats.snapshot()
+	stats.ItemsCount = int64(len(c.cache))
+	for key, data := range c.cache {
+		stats.MemoryUsage += int64(len(key) + len(data))
+	}
+
+	return stats, nil
 }
 
 // startJanitor periodically cleans up expired items.
@@ -210,11 +221,11 @@ func (c *InMemoryCache) startJanitor() {
 		for key, expiry := range c.expiry {
 			if time.Now().After(expiry) {
 				if c.onEvicted != nil {
-					data := c.cache[key]
-					c.onEvicted(key, data)
-					delete(c.cache, key)
-					delete(c.expiry, key)
+					c.onEvicted(key, c.cache[key])
 				}
+				delete(c.cache, key)
+				delete(c.expiry, key)
+				c.stats.expire()
 			}
 		}
 		c.mutex.Unlock()
@@ -226,52 +237,81 @@ type RedisCache struct {
 	client            *redis.Client
 	defaultExpiration time.Duration
 	onEvicted         func(key string, value interface{})
+	stats             statsCounter
+	options           driverOptions
 }
 
 // NewRedisCache initializes a new Redis cache.
-func NewRedisCache(client *redis.Client, defaultExpiration time.Duration, onEvicted func(key string, value interface{})) *RedisCache {
+func NewRedisCache(client *redis.Client, defaultExpiration time.Duration, onEvicted func(key string, value interface{}), opts ...Option) *RedisCache {
 	return &RedisCache{
 		client:            client,
 		defaultExpiration: defaultExpiration,
 		onEvicted:         onEvicted,
+		options:           newDriverOptions(opts),
 	}
 }
 
 // Get retrieves a value from the Redis cache.
 func (r *RedisCache) Get(key string, value interface{}) error {
-	data, err := r.client.Get(context.Background(), key).Bytes()
+	data, err := r.getBytes(key)
+	r.stats.record(err)
+	if err != nil {
+		return err
+	}
+
+	return r.options.serializer.Unmarshal(data, value)
+}
+
+// getBytes returns the encoded value stored under key.
+func (r *RedisCache) getBytes(key string) ([]byte, error) {
+	data, err := r.client.Get(context.Background(), r.options.key(key)).Bytes()
 	if err != nil {
 		if err == redis.Nil {
-			return ErrCacheMiss
+			return nil, ErrCacheMiss
 		}
-		return err
+		return nil, err
 	}
 
-	return json.Unmarshal(data, value)
+	return data, nil
 }
 
 // Set stores a value in the Redis cache.
 func (r *RedisCache) Set(key string, value interface{}, expiration time.Duration) error {
-	if expiration == 0 {
-		expiration = r.defaultExpiration
+	data, err := r.options.serializer.Marshal(value)
+	if err != nil {
+		return err
 	}
 
-	data, err := json.Marshal(value)
-	if err != nil {
+	if err := r.setBytes(key, data, expiration); err != nil {
 		return err
 	}
 
-	return r.client.Set(context.Background(), key, data, expiration).Err()
+	r.stats.set()
+	return nil
+}
+
+// setBytes stores an already encoded value in the Redis cache.
+func (r *RedisCache) setBytes(key string, data []byte, expiration time.Duration) error {
+	if expiration == 0 {
+		expiration = r.defaultExpiration
+	}
+
+	return r.client.Set(context.Background(), r.options.key(key), data, expiration).Err()
 }
 
 // Delete removes a value from the Redis cache.
 func (r *RedisCache) Delete(key string) error {
-	return r.client.Del(context.Background(), key).Err()
+	if err := r.client.Del(context.Background(), r.options.key(key)).Err(); err != nil {
+		return err
+	}
+
+	r.stats.delete()
+	return nil
 }
 
 // Exists checks if a key exists in the Redis cache.
 func (r *RedisCache) Exists(key string) (bool, error) {
-	exists, err := r.client.Exists(context.Background(), key).Result()
+	exists, err := r.client.Exists(context.Background(), r.options.key(key)).Result()
 	if err != nil {
 		return false, err
 	}
@@ -279,40 +319,94 @@ func (r *RedisCache) Exists(key string) (bool, error) {
 	return exists > 0, nil
 }
 
-// Flush clears all items from the Redis cache.
+// Flush clears all items from the Redis cache. With a prefix configured
+// only the keys in that namespace are removed.
 func (r *RedisCache) Flush() error {
-	return r.client.FlushDB(context.Background()).Err()
+	ctx := context.Background()
+	if r.options.prefix == "" {
+		return r.client.FlushDB(ctx).Err()
+	}
+
+	return r.scanPrefix(ctx, func(keys []string) error {
+		return r.client.Del(ctx, keys...).Err()
+	})
+}
+
+// scanPrefix calls fn with every batch of keys in the configured namespace.
+func (r *RedisCache) scanPrefix(ctx context.Context, fn func(keys []string) error) error {
+	var cursor uint64
+	for {
+		keys, next, err := r.client.Scan(ctx, cursor, r.options.prefix+"*", 1000).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := fn(keys); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
 }
 
 // Stats returns statistics of the Redis cache.
 func (r *RedisCache) Stats() (CacheStats, error) {
-	stats := CacheStats{}
-	keys, err := r.client.Keys(context.Background(), "*").Result()
-	if err != nil {
-		return stats, err
+	ctx := context.Background()
+	stats := r.stats.snapshot()
+
+	if r.options.prefix == "" {
+		count, err := r.client.DBSize(ctx).Result()
+		if err != nil {
+			return stats, err
+		}
+		stats.ItemsCount = count
+	} else {
+		err := r.scanPrefix(ctx, func(keys []string) error {
+			stats.ItemsCount += int64(len(keys))
+			return nil
+		})
+		if err != nil {
+			return stats, err
+		}
 	}
-	stats.ItemsCount = int64(len(keys))
+
+	// INFO is best effort: Redis-compatible servers do not all expose it.
+	if info, err := r.client.Info(ctx, "memory").Result(); err == nil {
+		stats.MemoryUsage = parseRedisInfo(info)["used_memory"]
+	}
+	if info, err := r.client.Info(ctx, "stats").Result(); err == nil {
+		values := parseRedisInfo(info)
+		stats.Evictions = values["evicted_keys"]
+		stats.Expired = values["expired_keys"]
+	}
+
 	return stats, nil
 }
 
 // CacheItem represents an item stored in the file cache.
 type CacheItem struct {
-	Value     interface{}
+	Value     []byte
 	ExpiresAt time.Time
 }
 
 // FileCache is a file-based cache implementation.
 type FileCache struct {
-	cache map[string]CacheItem
-	mutex sync.Mutex
-	path  string
+	cache   map[string]CacheItem
+	mutex   sync.Mutex
+	path    string
+	stats   statsCounter
+	options driverOptions
 }
 
 // NewFileCache initializes a new file-based cache.
-func NewFileCache(path string) *FileCache {
+func NewFileCache(path string, opts ...Option) *FileCache {
 	return &FileCache{
-		cache: make(map[string]CacheItem),
-		path:  path,
+		cache:   make(map[string]CacheItem),
+		path:    path,
+		options: newDriverOptions(opts),
 	}
 }
 
@@ -333,27 +427,28 @@ func (f *FileCache) Get(key string, value interface{}) error {
 	}
 
 	// Get the cache item
-	item, ok := f.cache[key]
+	item, ok := f.cache[f.options.key(key)]
 	if !ok || item.ExpiresAt.Before(time.Now()) {
+		f.stats.miss()
 		return ErrCacheMiss
 	}
+	f.stats.hit()
 
-	// Unmarshal the item.Value which is stored as a []byte
-	data, ok := item.Value.([]byte)
-	if !ok {
-		return errors.New("cache: invalid data type")
-	}
-
-	return json.Unmarshal(data, value)
+	return f.options.serializer.Unmarshal(item.Value, value)
 }
 
 // Set stores a value in the file cache.
 func (f *FileCache) Set(key string, value interface{}, expiration time.Duration) error {
+	data, err := f.options.serializer.Marshal(value)
+	if err != nil {
+		return err
+	}
+
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
-	f.cache[key] = CacheItem{
-		Value:     value,
+	f.cache[f.options.key(key)] = CacheItem{
+		Value:     data,
 		ExpiresAt: time.Now().Add(expiration),
 	}
 
@@ -362,7 +457,12 @@ func (f *FileCache) Set(key string, value interface{}, expiration time.Duration)
 		return err
 	}
 
-	return ioutil.WriteFile(f.path, bytes, 0644)
+	if err := ioutil.WriteFile(f.path, bytes, 0644); err != nil {
+		return err
+	}
+
+	f.stats.set()
+	return nil
 }
 
 // Delete removes a value from the file cache.
@@ -370,14 +470,19 @@ func (f *FileCache) Delete(key string) error {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
-	delete(f.cache, key)
+	delete(f.cache, f.options.key(key))
 
 	bytes, err := json.Marshal(f.cache)
 	if err != nil {
 		return err
 	}
 
-	return ioutil.WriteFile(f.path, bytes, 0644)
+	if err := ioutil.WriteFile(f.path, bytes, 0644); err != nil {
+		return err
+	}
+
+	f.stats.delete()
+	return nil
 }
 
 // Exists checks if a key exists in the file cache.
@@ -385,7 +490,7 @@ func (f *FileCache) Exists(key string) (bool, error) {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
-	_, ok := f.cache[key]
+	_, ok := f.cache[f.options.key(key)]
 	return ok, nil
 }
 
@@ -409,10 +514,11 @@ func (f *FileCache) Stats() (CacheStats, error) {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
-	return CacheStats{ItemsCount: int64(len(f.cache))}, nil
-}
+	stats := f.stats.snapshot()
+	stats.ItemsCount = int64(len(f.cache))
+	if info, err := os.Stat(f.path); err == nil {
+		stats.MemoryUsage = info.Size()
+	}
 
-// CacheStats holds statistics about the cache.
-type CacheStats struct {
-	ItemsCount int64
+	return stats, nil
 }
@@ -6,6 +6,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -90,6 +92,48 @@ func TestInMemoryCache_Stats(t *testing.T) {
 	assert.Equal(t, int64(1), stats.ItemsCount)
 }
 
+func TestInMemoryCache_StatsCounters(t *testing.T) {
+	c := NewInMemoryCache(nil)
+	assert.NoError(t, c.Set("key", "test value", time.Minute))
+	assert.NoError(t, c.Set("other", "test value", time.Minute))
+
+	var result string
+	assert.NoError(t, c.Get("key", &result))
+	assert.Equal(t, ErrCacheMiss, c.Get("nonexistent", &result))
+	assert.NoError(t, c.Delete("other"))
+
+	stats, err := c.Stats()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), stats.ItemsCount)
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(1), stats.Misses)
+	assert.Equal(t, int64(2), stats.Sets)
+	assert.Equal(t, int64(1), stats.Deletes)
+	assert.Equal(t, int64(len("key")+len(`"test value"`)), stats.MemoryUsage)
+	assert.Equal(t, 0.5, stats.HitRatio())
+}
+
+func TestRedisCache_Stats(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	c := NewRedisCache(client, time.Minute, nil)
+	assert.NoError(t, c.Set("key", "test value", time.Minute))
+	assert.NoError(t, c.Set("other", "test value", time.Minute))
+
+	var result string
+	assert.NoError(t, c.Get("key", &result))
+	assert.Equal(t, ErrCacheMiss, c.Get("nonexistent", &result))
+
+	stats, err := c.Stats()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), stats.ItemsCount)
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(1), stats.Misses)
+	assert.Equal(t, int64(2), stats.Sets)
+}
+
 func TestFileCache_Get(t *testing.T) {
 	// Create a temporary file for testing
 	file, err := ioutil.TempFile("", "cache_test")
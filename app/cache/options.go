@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Option configures a cache driver.
+type Option func(*driverOptions)
+
+// driverOptions holds the settings shared by every cache driver.
+type driverOptions struct {
+	prefix     string
+	serializer Serializer
+}
+
+// WithPrefix namespaces every key written by the driver, so that several
+// applications can share one backend.
+func WithPrefix(prefix string) Option {
+	return func(o *driverOptions) {
+		if prefix != "" && !strings.HasSuffix(prefix, ":") {
+			prefix += ":"
+		}
+		o.prefix = prefix
+	}
+}
+
+// WithSerializer sets the serializer used to encode values.
+func WithSerializer(serializer Serializer) Option {
+	return func(o *driverOptions) {
+		o.serializer = serializer
+	}
+}
+
+func newDriverOptions(opts []Option) driverOptions {
+	o := driverOptions{serializer: JSONSerializer{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// key returns key with the configured prefix.
+func (o driverOptions) key(key string) string {
+	return o.prefix + key
+}
+
+// optionsFromConfig reads the prefix, serializer and compression settings of
+// a store, falling back to the values shared by every store under cache.
+func optionsFromConfig(section string) ([]Option, error) {
+	serializer, err := NewSerializer(configString(section, "serializer"))
+	if err != nil {
+		return nil, err
+	}
+
+	if threshold := viper.GetInt(configKey(section, "compression.threshold")); threshold > 0 {
+		serializer = NewCompressedSerializer(serializer, threshold)
+	}
+
+	return []Option{
+		WithPrefix(configString(section, "prefix")),
+		WithSerializer(serializer),
+	}, nil
+}
+
+// configKey returns section.name if it is set, or cache.name otherwise.
+func configKey(section, name string) string {
+	if key := section + "." + name; viper.IsSet(key) {
+		return key
+	}
+	return "cache." + name
+}
+
+func configString(section, name string) string {
+	return viper.GetString(configKey(section, name))
+}
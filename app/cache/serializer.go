@@ -0,0 +1,197 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/tinylib/msgp/msgp"
+)
+
+// Serializer encodes values before they are handed to a cache driver.
+type Serializer interface {
+	Marshal(value interface{}) ([]byte, error)
+	Unmarshal(data []byte, value interface{}) error
+}
+
+// NewSerializer returns the serializer registered under name.
+func NewSerializer(name string) (Serializer, error) {
+	switch name {
+	case "", "json":
+		return JSONSerializer{}, nil
+	case "gob":
+		return GobSerializer{}, nil
+	case "msgpack":
+		return MsgpackSerializer{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported cache serializer: %s", name)
+	}
+}
+
+// JSONSerializer encodes values with encoding/json.
+type JSONSerializer struct{}
+
+func (JSONSerializer) Marshal(value interface{}) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+func (JSONSerializer) Unmarshal(data []byte, value interface{}) error {
+	return json.Unmarshal(data, value)
+}
+
+// GobSerializer encodes values with encoding/gob. Concrete types stored
+// behind interfaces must be registered with gob.Register.
+type GobSerializer struct{}
+
+func (GobSerializer) Marshal(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (GobSerializer) Unmarshal(data []byte, value interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(value)
+}
+
+// MsgpackSerializer encodes values as MessagePack. Types generated with msgp
+// are encoded directly; other values are encoded through their JSON
+// representation.
+type MsgpackSerializer struct{}
+
+func (MsgpackSerializer) Marshal(value interface{}) ([]byte, error) {
+	if m, ok := value.(msgp.Marshaler); ok {
+		return m.MarshalMsg(nil)
+	}
+
+	generic, err := toGeneric(value)
+	if err != nil {
+		return nil, err
+	}
+	return msgp.AppendIntf(nil, generic)
+}
+
+func (MsgpackSerializer) Unmarshal(data []byte, value interface{}) error {
+	if u, ok := value.(msgp.Unmarshaler); ok {
+		_, err := u.UnmarshalMsg(data)
+		return err
+	}
+
+	generic, _, err := msgp.ReadIntfBytes(data)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(generic)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, value)
+}
+
+// toGeneric converts value into maps, slices and scalars msgp can encode.
+func toGeneric(value interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return normalizeNumbers(generic), nil
+}
+
+// normalizeNumbers replaces json.Number values with int64 or float64.
+func normalizeNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeNumbers(item)
+		}
+	}
+	return value
+}
+
+const (
+	payloadRaw byte = iota
+	payloadGzip
+)
+
+var errInvalidPayload = errors.New("cache: invalid compressed payload")
+
+// CompressedSerializer gzips payloads produced by another serializer once
+// they grow beyond Threshold bytes.
+type CompressedSerializer struct {
+	Serializer Serializer
+	Threshold  int
+}
+
+// NewCompressedSerializer wraps serializer with gzip compression above threshold bytes.
+func NewCompressedSerializer(serializer Serializer, threshold int) *CompressedSerializer {
+	return &CompressedSerializer{Serializer: serializer, Threshold: threshold}
+}
+
+func (s *CompressedSerializer) Marshal(value interface{}) ([]byte, error) {
+	data, err := s.Serializer.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < s.Threshold {
+		return append([]byte{payloadRaw}, data...), nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(payloadGzip)
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *CompressedSerializer) Unmarshal(data []byte, value interface{}) error {
+	if len(data) == 0 {
+		return errInvalidPayload
+	}
+
+	switch data[0] {
+	case payloadRaw:
+		return s.Serializer.Unmarshal(data[1:], value)
+	case payloadGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data[1:]))
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
+		decompressed, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		return s.Serializer.Unmarshal(decompressed, value)
+	default:
+		return errInvalidPayload
+	}
+}
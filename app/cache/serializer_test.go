@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+type serializerTestItem struct {
+	Name  string
+	Age   int
+	Tags  []string
+	Score float64
+}
+
+func TestSerializers_RoundTrip(t *testing.T) {
+	item := serializerTestItem{Name: "John", Age: 30, Tags: []string{"a", "b"}, Score: 1.5}
+
+	for _, name := range []string{"json", "gob", "msgpack"} {
+		t.Run(name, func(t *testing.T) {
+			s, err := NewSerializer(name)
+			assert.NoError(t, err)
+
+			data, err := s.Marshal(item)
+			assert.NoError(t, err)
+
+			var result serializerTestItem
+			assert.NoError(t, s.Unmarshal(data, &result))
+			assert.Equal(t, item, result)
+		})
+	}
+
+	_, err := NewSerializer("xml")
+	assert.Error(t, err)
+}
+
+func TestCompressedSerializer(t *testing.T) {
+	s := NewCompressedSerializer(JSONSerializer{}, 64)
+
+	small, err := s.Marshal("short")
+	assert.NoError(t, err)
+	assert.Equal(t, payloadRaw, small[0])
+
+	long := strings.Repeat("compress me ", 100)
+	large, err := s.Marshal(long)
+	assert.NoError(t, err)
+	assert.Equal(t, payloadGzip, large[0])
+	assert.Less(t, len(large), len(long))
+
+	var result string
+	assert.NoError(t, s.Unmarshal(small, &result))
+	assert.Equal(t, "short", result)
+	assert.NoError(t, s.Unmarshal(large, &result))
+	assert.Equal(t, long, result)
+}
+
+func TestRedisCache_Prefix(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	appA := NewRedisCache(client, time.Minute, nil, WithPrefix("app_a"))
+	appB := NewRedisCache(client, time.Minute, nil, WithPrefix("app_b"), WithSerializer(MsgpackSerializer{}))
+
+	assert.NoError(t, appA.Set("key", "from a", time.Minute))
+	assert.NoError(t, appB.Set("key", "from b", time.Minute))
+	assert.True(t, mr.Exists("app_a:key"))
+	assert.True(t, mr.Exists("app_b:key"))
+
+	var result string
+	assert.NoError(t, appA.Get("key", &result))
+	assert.Equal(t, "from a", result)
+	assert.NoError(t, appB.Get("key", &result))
+	assert.Equal(t, "from b", result)
+
+	stats, err := appA.Stats()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), stats.ItemsCount)
+
+	assert.NoError(t, appA.Flush())
+	assert.Equal(t, ErrCacheMiss, appA.Get("key", &result))
+	assert.NoError(t, appB.Get("key", &result))
+	assert.Equal(t, "from b", result)
+}
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// CacheStats holds statistics about the cache.
+type CacheStats struct {
+	ItemsCount  int64                `json:"items_count"`
+	Hits        int64                `json:"hits"`
+	Misses      int64                `json:"misses"`
+	Sets        int64                `json:"sets"`
+	Deletes     int64                `json:"deletes"`
+	Evictions   int64                `json:"evictions"`
+	Expired     int64                `json:"expired"`
+	MemoryUsage int64                `json:"memory_usage"`
+	Tiers       map[string]TierStats `json:"tiers,omitempty"`
+}
+
+// TierStats holds hit and miss counts for one level of a tiered cache.
+type TierStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+}
+
+// HitRatio returns the fraction of reads that were served from the cache.
+func (s CacheStats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// statsCounter tracks the operation counters shared by every driver.
+type statsCounter struct {
+	hits      int64
+	misses    int64
+	sets      int64
+	deletes   int64
+	evictions int64
+	expired   int64
+}
+
+func (s *statsCounter) hit()    { atomic.AddInt64(&s.hits, 1) }
+func (s *statsCounter) miss()   { atomic.AddInt64(&s.misses, 1) }
+func (s *statsCounter) set()    { atomic.AddInt64(&s.sets, 1) }
+func (s *statsCounter) delete() { atomic.AddInt64(&s.deletes, 1) }
+func (s *statsCounter) evict()  { atomic.AddInt64(&s.evictions, 1) }
+func (s *statsCounter) expire() { atomic.AddInt64(&s.expired, 1) }
+
+// record counts a read as a hit or a miss depending on its error.
+func (s *statsCounter) record(err error) {
+	switch err {
+	case nil:
+		s.hit()
+	case ErrCacheMiss:
+		s.miss()
+	}
+}
+
+// snapshot returns the current counters as CacheStats.
+func (s *statsCounter) snapshot() CacheStats {
+	return CacheStats{
+		Hits:      atomic.LoadInt64(&s.hits),
+		Misses:    atomic.LoadInt64(&s.misses),
+		Sets:      atomic.LoadInt64(&s.sets),
+		Deletes:   atomic.LoadInt64(&s.deletes),
+		Evictions: atomic.LoadInt64(&s.evictions),
+		Expired:   atomic.LoadInt64(&s.expired),
+	}
+}
+
+// parseRedisInfo parses the "field:value" lines returned by the INFO command.
+func parseRedisInfo(info string) map[string]int64 {
+	values := make(map[string]int64)
+	scanner := bufio.NewScanner(strings.NewReader(info))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if n, err := strconv.ParseInt(parts[1], 10, 64); err == nil {
+			values[parts[0]] = n
+		}
+	}
+	return values
+}
@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mousav1/weiser/database"
+	"github.com/spf13/viper"
+)
+
+var (
+	stores            = make(map[string]*Cache)
+	defaultStore      string
+	defaultExpiration time.Duration
+	onEvicted         func(key string, value interface{})
+	storesMutex       sync.RWMutex
+)
+
+// InitializeCache configures the named stores under cache.stores and
+// creates the default store. Other stores are created on first use.
+func InitializeCache(expiration time.Duration, evicted func(key string, value interface{})) error {
+	Reset()
+
+	name := viper.GetString("cache.default")
+	if name == "" {
+		return fmt.Errorf("default cache store is not configured")
+	}
+
+	storesMutex.Lock()
+	defaultStore = name
+	defaultExpiration = expiration
+	onEvicted = evicted
+	storesMutex.Unlock()
+
+	if _, err := Store(name); err != nil {
+		return err
+	}
+	return nil
+}
+
+// RegisterStore adds a store under the given name, replacing any existing one.
+func RegisterStore(name string, store *Cache) {
+	storesMutex.Lock()
+	defer storesMutex.Unlock()
+
+	stores[name] = store
+}
+
+// SetDefaultStore selects the store returned by GetCacheInstance.
+func SetDefaultStore(name string) error {
+	if _, err := Store(name); err != nil {
+		return err
+	}
+
+	storesMutex.Lock()
+	defer storesMutex.Unlock()
+
+	defaultStore = name
+	return nil
+}
+
+// Store returns the named store, creating it from configuration if needed.
+// The store is created without holding the lock, since connecting to Redis
+// may be slow, so lookups of other stores are never blocked by it. When two
+// callers create the same store at once, the first one registered wins.
+func Store(name string) (*Cache, error) {
+	storesMutex.RLock()
+	store, ok := stores[name]
+	expiration, evicted := defaultExpiration, onEvicted
+	storesMutex.RUnlock()
+	if ok {
+		return store, nil
+	}
+
+	created, err := newStore(name, expiration, evicted)
+	if err != nil {
+		return nil, err
+	}
+
+	storesMutex.Lock()
+	defer storesMutex.Unlock()
+
+	if store, ok := stores[name]; ok {
+		created.Close()
+		return store, nil
+	}
+	stores[name] = created
+	return created, nil
+}
+
+// GetCacheInstance returns the default store, or nil if the cache has not
+// been initialized.
+func GetCacheInstance() *Cache {
+	storesMutex.RLock()
+	defer storesMutex.RUnlock()
+
+	return stores[defaultStore]
+}
+
+// Reset closes and forgets every store. It is mainly useful in tests.
+func Reset() {
+	storesMutex.Lock()
+	defer storesMutex.Unlock()
+
+	for _, store := range stores {
+		store.Close()
+	}
+	stores = make(map[string]*Cache)
+	defaultStore = ""
+}
+
+// newStore builds the store configured under cache.stores.<name>.
+func newStore(name string, defaultExpiration time.Duration, onEvicted func(key string, value interface{})) (*Cache, error) {
+	section := "cache.stores." + name
+	if !viper.IsSet(section) {
+		return nil, fmt.Errorf("cache store not found: %s", name)
+	}
+
+	opts, err := optionsFromConfig(section)
+	if err != nil {
+		return nil, err
+	}
+
+	var driver CacheDriver
+	switch driverName := viper.GetString(section + ".driver"); driverName {
+	case "memory":
+		driver = NewInMemoryCache(onEvicted, opts...)
+	case "file":
+		driver = NewFileCache(viper.GetString(section+".path"), opts...)
+	case "redis":
+		client, err := database.ConnectToRedis(viper.GetStringMapString("database.redis." + viper.GetString(section+".connection")))
+		if err != nil {
+			return nil, err
+		}
+		driver = NewRedisCache(client, defaultExpiration, onEvicted, opts...)
+	case "tiered":
+		client, err := database.ConnectToRedis(viper.GetStringMapString("database.redis." + viper.GetString(section+".connection")))
+		if err != nil {
+			return nil, err
+		}
+		driver, err = NewTieredCache(client, TieredOptions{
+			L1TTL:             viper.GetDuration(section + ".l1_ttl"),
+			Channel:           viper.GetString(section + ".channel"),
+			DefaultExpiration: defaultExpiration,
+			OnEvicted:         onEvicted,
+		}, opts...)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported cache driver %q for store %s", driverName, name)
+	}
+
+	store := NewCache(driver, defaultExpiration)
+	store.onEvicted = onEvicted
+	return store, nil
+}
@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupStoresConfig(t *testing.T) {
+	viper.Set("cache.default", "memory")
+	viper.Set("cache.prefix", "test")
+	viper.Set("cache.stores.memory.driver", "memory")
+	viper.Set("cache.stores.file.driver", "file")
+	viper.Set("cache.stores.file.path", filepath.Join(t.TempDir(), "cache.json"))
+	viper.Set("cache.stores.file.serializer", "gob")
+	t.Cleanup(Reset)
+}
+
+func TestInitializeCache_Stores(t *testing.T) {
+	setupStoresConfig(t)
+
+	assert.NoError(t, InitializeCache(time.Minute, nil))
+
+	memory, err := Store("memory")
+	assert.NoError(t, err)
+	assert.Same(t, memory, GetCacheInstance())
+
+	file, err := Store("file")
+	assert.NoError(t, err)
+	assert.NotSame(t, memory, file)
+
+	assert.NoError(t, file.Set("key", "from file", 0))
+	exists, err := memory.Exists("key")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	var result string
+	assert.NoError(t, file.Get("key", &result))
+	assert.Equal(t, "from file", result)
+
+	_, err = Store("missing")
+	assert.Error(t, err)
+
+	assert.NoError(t, SetDefaultStore("file"))
+	assert.Same(t, file, GetCacheInstance())
+}
+
+func TestReset(t *testing.T) {
+	setupStoresConfig(t)
+
+	assert.NoError(t, InitializeCache(time.Minute, nil))
+	first := GetCacheInstance()
+	assert.NoError(t, first.Set("key", "value", 0))
+
+	Reset()
+	assert.Nil(t, GetCacheInstance())
+
+	assert.NoError(t, InitializeCache(time.Minute, nil))
+	assert.NotSame(t, first, GetCacheInstance())
+
+	var result string
+	assert.Equal(t, ErrCacheMiss, GetCacheInstance().Get("key", &result))
+}
+
+func TestRegisterStore(t *testing.T) {
+	t.Cleanup(Reset)
+
+	store := NewCache(NewInMemoryCache(nil), time.Minute)
+	RegisterStore("custom", store)
+
+	found, err := Store("custom")
+	assert.NoError(t, err)
+	assert.Same(t, store, found)
+}
+
+func TestStore_SlowStoreDoesNotBlockOthers(t *testing.T) {
+	setupStoresConfig(t)
+	assert.NoError(t, InitializeCache(time.Minute, nil))
+
+	// یک سرور Redis که اتصال را می‌پذیرد ولی هرگز پاسخ نمی‌دهد
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	var conns []net.Conn
+	var connsMutex sync.Mutex
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			connsMutex.Lock()
+			conns = append(conns, conn)
+			connsMutex.Unlock()
+		}
+	}()
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	viper.Set("database.redis.slow", map[string]string{"host": host, "port": port, "dbname": "0"})
+	viper.Set("cache.stores.slow.driver", "redis")
+	viper.Set("cache.stores.slow.connection", "slow")
+	defer viper.Set("cache.stores.slow", nil)
+	defer viper.Set("database.redis.slow", nil)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := Store("slow")
+		done <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	start := time.Now()
+	_, err = Store("file")
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+
+	listener.Close()
+	connsMutex.Lock()
+	for _, conn := range conns {
+		conn.Close()
+	}
+	connsMutex.Unlock()
+	assert.Error(t, <-done)
+}
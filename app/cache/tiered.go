@@ -0,0 +1,232 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultL1TTL             = 30 * time.Second
+	defaultInvalidateChannel = "cache:invalidate"
+)
+
+// TieredOptions configures a TieredCache.
+type TieredOptions struct {
+	L1TTL             time.Duration
+	Channel           string
+	DefaultExpiration time.Duration
+	OnEvicted         func(key string, value interface{})
+}
+
+// TieredCache keeps hot keys in an in-process InMemoryCache (L1) in front of
+// a shared RedisCache (L2). Writes and deletes are broadcast over Redis
+// pub/sub so that other nodes drop their stale L1 copies.
+type TieredCache struct {
+	l1      *InMemoryCache
+	l2      *RedisCache
+	l1TTL   time.Duration
+	client  *redis.Client
+	channel string
+	nodeID  string
+	pubsub  *redis.PubSub
+	stats   statsCounter
+	options driverOptions
+
+	l1Hits   int64
+	l1Misses int64
+	l2Hits   int64
+	l2Misses int64
+}
+
+// invalidation is the message published when a node changes a key.
+type invalidation struct {
+	Node  string `json:"node"`
+	Key   string `json:"key,omitempty"`
+	Flush bool   `json:"flush,omitempty"`
+}
+
+// NewTieredCache initializes a new tiered cache and subscribes to the
+// invalidation channel. The driver options apply to both tiers.
+func NewTieredCache(client *redis.Client, tiered TieredOptions, opts ...Option) (*TieredCache, error) {
+	if tiered.L1TTL <= 0 {
+		tiered.L1TTL = defaultL1TTL
+	}
+	if tiered.Channel == "" {
+		tiered.Channel = defaultInvalidateChannel
+	}
+	options := newDriverOptions(opts)
+
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	t := &TieredCache{
+		l1:      NewInMemoryCache(tiered.OnEvicted, opts...),
+		l2:      NewRedisCache(client, tiered.DefaultExpiration, tiered.OnEvicted, opts...),
+		l1TTL:   tiered.L1TTL,
+		client:  client,
+		channel: options.key(tiered.Channel),
+		nodeID:  hex.EncodeToString(b),
+		options: options,
+	}
+
+	t.pubsub = client.Subscribe(context.Background(), t.channel)
+	// Wait for the subscription to be confirmed so no invalidation is missed.
+	if _, err := t.pubsub.Receive(context.Background()); err != nil {
+		t.pubsub.Close()
+		return nil, err
+	}
+
+	go t.listen()
+
+	return t, nil
+}
+
+// listen drops L1 entries invalidated by other nodes.
+func (t *TieredCache) listen() {
+	for msg := range t.pubsub.Channel() {
+		var inv invalidation
+		if err := json.Unmarshal([]byte(msg.Payload), &inv); err != nil {
+			log.Println("cache: invalid invalidation message:", err)
+			continue
+		}
+		if inv.Node == t.nodeID {
+			continue
+		}
+
+		if inv.Flush {
+			t.l1.Flush()
+		} else {
+			t.l1.Delete(inv.Key)
+		}
+	}
+}
+
+// publish notifies other nodes that their L1 copy is stale.
+func (t *TieredCache) publish(inv invalidation) error {
+	inv.Node = t.nodeID
+	payload, err := json.Marshal(inv)
+	if err != nil {
+		return err
+	}
+	return t.client.Publish(context.Background(), t.channel, payload).Err()
+}
+
+// Get retrieves a value from L1, falling back to L2 and refilling L1 on a hit.
+func (t *TieredCache) Get(key string, value interface{}) error {
+	data, err := t.l1.getBytes(key)
+	if err == nil {
+		atomic.AddInt64(&t.l1Hits, 1)
+		t.stats.hit()
+		return t.options.serializer.Unmarshal(data, value)
+	}
+	atomic.AddInt64(&t.l1Misses, 1)
+
+	data, err = t.l2.getBytes(key)
+	t.stats.record(err)
+	if err != nil {
+		if err == ErrCacheMiss {
+			atomic.AddInt64(&t.l2Misses, 1)
+		}
+		return err
+	}
+	atomic.AddInt64(&t.l2Hits, 1)
+
+	t.l1.setBytes(key, data, t.l1TTL)
+
+	return t.options.serializer.Unmarshal(data, value)
+}
+
+// Set stores a value in both tiers and invalidates other nodes' L1 copies.
+func (t *TieredCache) Set(key string, value interface{}, expiration time.Duration) error {
+	data, err := t.options.serializer.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	if err := t.l2.setBytes(key, data, expiration); err != nil {
+		return err
+	}
+
+	l1Expiration := t.l1TTL
+	if expiration > 0 && expiration < l1Expiration {
+		l1Expiration = expiration
+	}
+	t.l1.setBytes(key, data, l1Expiration)
+	t.stats.set()
+
+	return t.publish(invalidation{Key: key})
+}
+
+// Delete removes a value from both tiers and invalidates other nodes' L1 copies.
+func (t *TieredCache) Delete(key string) error {
+	t.l1.Delete(key)
+
+	if err := t.l2.Delete(key); err != nil {
+		return err
+	}
+	t.stats.delete()
+
+	return t.publish(invalidation{Key: key})
+}
+
+// Exists checks if a key exists in either tier.
+func (t *TieredCache) Exists(key string) (bool, error) {
+	if ok, _ := t.l1.Exists(key); ok {
+		return true, nil
+	}
+	return t.l2.Exists(key)
+}
+
+// Flush clears both tiers on every node.
+func (t *TieredCache) Flush() error {
+	t.l1.Flush()
+
+	if err := t.l2.Flush(); err != nil {
+		return err
+	}
+
+	return t.publish(invalidation{Flush: true})
+}
+
+// Stats returns the L2 item count and memory use along with the tiered
+// cache's own counters and hit and miss counts per tier.
+func (t *TieredCache) Stats() (CacheStats, error) {
+	l2Stats, err := t.l2.Stats()
+	if err != nil {
+		return l2Stats, err
+	}
+	l1Stats, _ := t.l1.Stats()
+
+	stats := t.stats.snapshot()
+	stats.ItemsCount = l2Stats.ItemsCount
+	stats.MemoryUsage = l1Stats.MemoryUsage + l2Stats.MemoryUsage
+	stats.Evictions = l2Stats.Evictions
+	stats.Expired = l1Stats.Expired + l2Stats.Expired
+
+	stats.Tiers = map[string]TierStats{
+		"l1": {
+			Hits:   atomic.LoadInt64(&t.l1Hits),
+			Misses: atomic.LoadInt64(&t.l1Misses),
+		},
+		"l2": {
+			Hits:   atomic.LoadInt64(&t.l2Hits),
+			Misses: atomic.LoadInt64(&t.l2Misses),
+		},
+	}
+
+	return stats, nil
+}
+
+// Close stops listening for invalidation messages.
+func (t *TieredCache) Close() error {
+	return t.pubsub.Close()
+}
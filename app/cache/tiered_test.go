@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTieredCache(t *testing.T, addr string) *TieredCache {
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	c, err := NewTieredCache(client, TieredOptions{L1TTL: time.Minute})
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		c.Close()
+		client.Close()
+	})
+	return c
+}
+
+func TestTieredCache_GetFillsL1(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := newTestTieredCache(t, mr.Addr())
+
+	err := c.Set("key", "test value", time.Minute)
+	assert.NoError(t, err)
+
+	// Drop the L1 copy so the next read has to go to Redis.
+	c.l1.Delete("key")
+
+	var result string
+	assert.NoError(t, c.Get("key", &result))
+	assert.Equal(t, "test value", result)
+
+	assert.NoError(t, c.Get("key", &result))
+	assert.Equal(t, "test value", result)
+
+	err = c.Get("nonexistent", &result)
+	assert.Equal(t, ErrCacheMiss, err)
+
+	stats, err := c.Stats()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), stats.ItemsCount)
+	assert.Equal(t, TierStats{Hits: 1, Misses: 2}, stats.Tiers["l1"])
+	assert.Equal(t, TierStats{Hits: 1, Misses: 1}, stats.Tiers["l2"])
+}
+
+func TestTieredCache_InvalidatesOtherNodes(t *testing.T) {
+	mr := miniredis.RunT(t)
+	nodeA := newTestTieredCache(t, mr.Addr())
+	nodeB := newTestTieredCache(t, mr.Addr())
+
+	assert.NoError(t, nodeA.Set("key", "first", time.Minute))
+
+	var result string
+	assert.NoError(t, nodeB.Get("key", &result))
+	assert.Equal(t, "first", result)
+
+	assert.NoError(t, nodeA.Set("key", "second", time.Minute))
+	assert.Eventually(t, func() bool {
+		_, err := nodeB.l1.getBytes("key")
+		return err == ErrCacheMiss
+	}, time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, nodeB.Get("key", &result))
+	assert.Equal(t, "second", result)
+
+	assert.NoError(t, nodeA.Delete("key"))
+	assert.Eventually(t, func() bool {
+		return nodeB.Get("key", &result) == ErrCacheMiss
+	}, time.Second, 10*time.Millisecond)
+}
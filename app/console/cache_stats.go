@@ -0,0 +1,58 @@
+package console
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/mousav1/weiser/app/cache"
+)
+
+// CacheStatsCommand prints the statistics of the configured cache.
+type CacheStatsCommand struct{}
+
+func (c *CacheStatsCommand) Name() string {
+	return "cache:stats"
+}
+
+func (c *CacheStatsCommand) Description() string {
+	return "Display cache hit, miss and memory statistics [store]"
+}
+
+func (c *CacheStatsCommand) Services() []Service {
+	return []Service{ServiceCache}
+}
+
+func (c *CacheStatsCommand) Handle(args []string) error {
+	instance := cache.GetCacheInstance()
+	if len(args) > 0 {
+		store, err := cache.Store(args[0])
+		if err != nil {
+			return err
+		}
+		instance = store
+	}
+	if instance == nil {
+		return fmt.Errorf("cache is not initialized")
+	}
+
+	stats, err := instance.Stats()
+	if err != nil {
+		return fmt.Errorf("failed to read cache stats: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Items\t%d\n", stats.ItemsCount)
+	fmt.Fprintf(w, "Hits\t%d\n", stats.Hits)
+	fmt.Fprintf(w, "Misses\t%d\n", stats.Misses)
+	fmt.Fprintf(w, "Hit ratio\t%.2f%%\n", stats.HitRatio()*100)
+	fmt.Fprintf(w, "Sets\t%d\n", stats.Sets)
+	fmt.Fprintf(w, "Deletes\t%d\n", stats.Deletes)
+	fmt.Fprintf(w, "Evictions\t%d\n", stats.Evictions)
+	fmt.Fprintf(w, "Expired\t%d\n", stats.Expired)
+	fmt.Fprintf(w, "Memory\t%d bytes\n", stats.MemoryUsage)
+	for name, tier := range stats.Tiers {
+		fmt.Fprintf(w, "Tier %s\t%d hits, %d misses\n", name, tier.Hits, tier.Misses)
+	}
+	return w.Flush()
+}
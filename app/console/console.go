@@ -0,0 +1,87 @@
+package console
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+)
+
+// Command is a task that can be run from the command line, e.g.
+// `go run main.go cache:stats`.
+type Command interface {
+	Name() string
+	Description() string
+	Handle(args []string) error
+}
+
+// Service is a part of the application that is set up before a command
+// that needs it runs.
+type Service string
+
+const (
+	ServiceCache   Service = "cache"
+	ServiceStorage Service = "storage"
+)
+
+// ServiceUser is implemented by commands that need services of the
+// application. Commands that do not implement it run with only the
+// configuration loaded.
+type ServiceUser interface {
+	Services() []Service
+}
+
+// Run executes the command named by args[0] with the remaining arguments.
+func Run(args []string) error {
+	if len(args) == 0 || args[0] == "list" {
+		printCommands()
+		return nil
+	}
+
+	cmd, ok := find(args[0])
+	if !ok {
+		return fmt.Errorf("command not found: %s", args[0])
+	}
+	return cmd.Handle(args[1:])
+}
+
+// Services returns the services needed by the command named by args[0].
+func Services(args []string) []Service {
+	if len(args) == 0 {
+		return nil
+	}
+	cmd, ok := find(args[0])
+	if !ok {
+		return nil
+	}
+	if user, ok := cmd.(ServiceUser); ok {
+		return user.Services()
+	}
+	return nil
+}
+
+// find returns the registered command with the given name.
+func find(name string) (Command, bool) {
+	for _, cmd := range Commands {
+		if cmd.Name() == name {
+			return cmd, true
+		}
+	}
+	return nil, false
+}
+
+// printCommands prints every registered command with its description.
+func printCommands() {
+	commands := make([]Command, len(Commands))
+	copy(commands, Commands)
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].Name() < commands[j].Name()
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Available commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %s\t%s\n", cmd.Name(), cmd.Description())
+	}
+	w.Flush()
+}
@@ -0,0 +1,15 @@
+package console
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServices(t *testing.T) {
+	assert.Equal(t, []Service{ServiceCache}, Services([]string{"cache:stats", "default"}))
+	assert.Equal(t, []Service{ServiceStorage}, Services([]string{"upload:clean"}))
+	assert.Empty(t, Services([]string{"key:generate"}))
+	assert.Empty(t, Services([]string{"missing"}))
+	assert.Empty(t, Services(nil))
+}
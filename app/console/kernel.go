@@ -0,0 +1,8 @@
+package console
+
+// Commands lists the commands available to the console.
+var Commands = []Command{
+	&CacheStatsCommand{},
+	&KeyGenerateCommand{},
+	&UploadCleanCommand{},
+}
@@ -0,0 +1,77 @@
+package console
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/mousav1/weiser/app/encryption"
+	"github.com/spf13/viper"
+)
+
+// appKeyPattern matches the key line of the app section of the config file.
+var appKeyPattern = regexp.MustCompile(`^(\s+)key:.*$`)
+
+// KeyGenerateCommand generates a new application key and writes it to the
+// configuration file.
+type KeyGenerateCommand struct{}
+
+func (c *KeyGenerateCommand) Name() string {
+	return "key:generate"
+}
+
+func (c *KeyGenerateCommand) Description() string {
+	return "Set the application key used for encryption [--show]"
+}
+
+func (c *KeyGenerateCommand) Handle(args []string) error {
+	key, err := encryption.GenerateKey()
+	if err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	for _, arg := range args {
+		if arg == "--show" {
+			fmt.Println(key)
+			return nil
+		}
+	}
+
+	path := viper.ConfigFileUsed()
+	if path == "" {
+		return fmt.Errorf("no configuration file loaded")
+	}
+	if err := writeAppKey(path, key); err != nil {
+		return err
+	}
+
+	if viper.GetString("app.key") != "" {
+		fmt.Println("Values encrypted with the old key can only be read if it is added to app.previous_keys.")
+	}
+	fmt.Printf("Application key set in %s\n", path)
+	return nil
+}
+
+// writeAppKey replaces app.key in the YAML configuration file at path.
+func writeAppKey(path, key string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	lines := strings.Split(string(content), "\n")
+	inApp := false
+	for i, line := range lines {
+		if line != "" && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "#") {
+			inApp = strings.TrimSpace(line) == "app:"
+			continue
+		}
+		if inApp && appKeyPattern.MatchString(line) {
+			lines[i] = appKeyPattern.ReplaceAllString(line, fmt.Sprintf(`${1}key: "%s"`, key))
+			return os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644)
+		}
+	}
+
+	return fmt.Errorf("app.key not found in %s", path)
+}
@@ -0,0 +1,24 @@
+package console
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteAppKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	config := "app:\n  key: \"\"\n  previous_keys: []\nsession:\n  key: \"session-key\"\n"
+	assert.NoError(t, os.WriteFile(path, []byte(config), 0644))
+
+	assert.NoError(t, writeAppKey(path, "base64:abc"))
+
+	content, _ := os.ReadFile(path)
+	assert.Equal(t, "app:\n  key: \"base64:abc\"\n  previous_keys: []\nsession:\n  key: \"session-key\"\n", string(content))
+
+	missing := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(missing, []byte("session:\n  key: \"session-key\"\n"), 0644)
+	assert.Error(t, writeAppKey(missing, "base64:abc"))
+}
@@ -0,0 +1,35 @@
+package console
+
+import (
+	"fmt"
+
+	"github.com/mousav1/weiser/app/file"
+)
+
+// UploadCleanCommand removes resumable uploads that were never completed.
+type UploadCleanCommand struct{}
+
+func (c *UploadCleanCommand) Name() string {
+	return "upload:clean"
+}
+
+func (c *UploadCleanCommand) Description() string {
+	return "Remove expired incomplete resumable uploads"
+}
+
+func (c *UploadCleanCommand) Services() []Service {
+	return []Service{ServiceStorage}
+}
+
+func (c *UploadCleanCommand) Handle(args []string) error {
+	uploader, err := file.UploaderFromConfig()
+	if err != nil {
+		return err
+	}
+	removed, err := uploader.CleanExpired()
+	if err != nil {
+		return fmt.Errorf("failed to clean uploads: %w", err)
+	}
+	fmt.Printf("Removed %d expired uploads\n", removed)
+	return nil
+}
@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/mousav1/weiser/app/http/response"
+	"github.com/mousav1/weiser/facades"
+)
+
+// MetricsController exposes runtime statistics of the application.
+type MetricsController struct {
+	*BaseController
+}
+
+// NewMetricsController creates a new instance of MetricsController.
+func NewMetricsController() *MetricsController {
+	return &MetricsController{}
+}
+
+// Cache returns the statistics of the default cache store, or of the store
+// named by the "store" query parameter.
+func (c *MetricsController) Cache(ctx *fiber.Ctx) error {
+	cache := facades.Cache()
+	if name := ctx.Query("store"); name != "" {
+		cache = cache.Store(name)
+	}
+
+	stats, err := cache.Stats()
+	if err != nil {
+		res := response.New(nil, err.Error(), fiber.StatusInternalServerError)
+		return response.Send(ctx, res)
+	}
+
+	res := response.New(stats, "Cache statistics retrieved successfully", fiber.StatusOK)
+	return response.Send(ctx, res)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/mousav1/weiser/app/http/request"
+	"github.com/mousav1/weiser/app/http/requests"
 	"github.com/mousav1/weiser/app/http/response"
 
 	"github.com/mousav1/weiser/app/services"
@@ -34,18 +35,9 @@ func NewUserController(us services.UserService) UserController {
 
 // CreateUser creates a new user.
 func (uc *userController) CreateUser(c *fiber.Ctx) error {
-	req, err := request.New(c)
+	user, err := request.Validated[requests.CreateUser](c)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
-	}
-
-	var user services.CreateUserInput
-	if err := req.Bind(&user); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	createdUser, err := uc.userService.CreateUser(user.Username, user.Email, user.Password)
@@ -128,29 +120,12 @@ func (uc *userController) GetUserByEmail(c *fiber.Ctx) error {
 
 // UpdateUser updates an existing user.
 func (uc *userController) UpdateUser(c *fiber.Ctx) error {
-	req, err := request.New(c)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
-	}
-
-	var user services.UpdateUserInput
-	if err := req.Bind(&user); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
-	}
-
-	idString := c.Params("id")
-	id, err := strconv.Atoi(idString)
+	user, err := request.Validated[requests.UpdateUser](c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid user ID",
-		})
+		return err
 	}
 
-	err = uc.userService.UpdateUser(uint(id), user.Username, user.Email, user.Password)
+	err = uc.userService.UpdateUser(user.ID, user.Username, user.Email, user.Password)
 	if err != nil {
 		if errors.Is(err, ErrUserNotFound) {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -5,52 +5,104 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/mousav1/weiser/app/encryption"
 	"github.com/spf13/viper"
 )
 
 type cookieConfig struct {
-	Name     string
-	Value    string
-	Path     string
-	Domain   string
-	Expires  time.Time
-	Secure   bool
-	SameSite http.SameSite
-	HTTPOnly bool
+	Name        string
+	Value       string
+	Path        string
+	Domain      string
+	Expires     time.Time
+	Secure      bool
+	SameSite    http.SameSite
+	HTTPOnly    bool
+	Partitioned bool
 }
 
-var config cookieConfig
-
-func init() {
-	cookie := viper.Get("cookie")
-	if cookie != nil {
-		cookieMap := cookie.(map[string]interface{})
-		config.Name = getStringOrDefault(cookieMap["name"], "cookie_name")
-		config.Value = ""
-		config.Path = getStringOrDefault(cookieMap["path"], "/")
-		config.Domain = getStringOrDefault(cookieMap["domain"], "")
-		config.Expires, _ = time.Parse(time.RFC3339, getStringOrDefault(cookieMap["expires"], "2030-12-31T00:00:00Z"))
-		config.Secure = getBoolOrDefault(cookieMap["secure"], true)
-		sameSite, _ := strconv.Atoi(getStringOrDefault(cookieMap["samesite"], "0"))
-		config.SameSite = http.SameSite(sameSite)
-		config.HTTPOnly = getBoolOrDefault(cookieMap["httponly"], true)
+var (
+	config       cookieConfig
+	configLoaded bool
+	configMutex  sync.RWMutex
+)
+
+// LoadConfig reads the cookie defaults from the cookie section of the
+// configuration. It is called once the configuration file has been loaded;
+// until then the defaults are read on first use.
+func LoadConfig() {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+	loadConfig()
+}
+
+func loadConfig() {
+	cookieMap, _ := viper.Get("cookie").(map[string]interface{})
+	config.Name = getStringOrDefault(cookieMap["name"], "cookie_name")
+	config.Value = ""
+	config.Path = getStringOrDefault(cookieMap["path"], "/")
+	config.Domain = getStringOrDefault(cookieMap["domain"], "")
+	config.Expires, _ = time.Parse(time.RFC3339, getStringOrDefault(cookieMap["expires"], "2030-12-31T00:00:00Z"))
+	config.Secure = getBoolOrDefault(cookieMap["secure"], true)
+	config.SameSite = parseSameSite(cookieMap["samesite"])
+	config.HTTPOnly = getBoolOrDefault(cookieMap["httponly"], true)
+	config.Partitioned = getBoolOrDefault(cookieMap["partitioned"], false)
+	configLoaded = true
+}
+
+// currentConfig returns the cookie defaults, loading them on first use.
+func currentConfig() cookieConfig {
+	configMutex.RLock()
+	if configLoaded {
+		defer configMutex.RUnlock()
+		return config
 	}
+	configMutex.RUnlock()
+
+	configMutex.Lock()
+	defer configMutex.Unlock()
+	if !configLoaded {
+		loadConfig()
+	}
+	return config
 }
 
-func SetCookie(c *fiber.Ctx, name string, value string, expire time.Time) {
-	c.Cookie(&fiber.Cookie{
-		Name:     name,
-		Value:    value,
-		Path:     config.Path,
-		Domain:   config.Domain,
-		Expires:  expire,
-		Secure:   config.Secure,
-		SameSite: sameSiteToString(config.SameSite),
-		HTTPOnly: config.HTTPOnly,
-	})
+// parseSameSite accepts lax, strict and none as well as the numeric
+// http.SameSite values.
+func parseSameSite(value interface{}) http.SameSite {
+	switch v := value.(type) {
+	case int:
+		return http.SameSite(v)
+	case string:
+		switch strings.ToLower(v) {
+		case "lax":
+			return http.SameSiteLaxMode
+		case "strict":
+			return http.SameSiteStrictMode
+		case "none":
+			return http.SameSiteNoneMode
+		}
+		if n, err := strconv.Atoi(v); err == nil {
+			return http.SameSite(n)
+		}
+	}
+	return http.SameSiteDefaultMode
+}
+
+// SetCookie sets a cookie with the configured defaults, which can be
+// overridden per cookie with opts.
+func SetCookie(c *fiber.Ctx, name string, value string, expire time.Time, opts ...Option) {
+	options := defaultOptions()
+	options.Expires = expire
+	for _, opt := range opts {
+		opt(&options)
+	}
+	writeCookie(c, name, value, options)
 }
 
 func GetCookie(c *fiber.Ctx, name string) (string, error) {
@@ -61,12 +113,53 @@ func GetCookie(c *fiber.Ctx, name string) (string, error) {
 	return cookie, nil
 }
 
+// SetEncrypted encrypts value with the application key and sets it as a cookie.
+// The value is bound to the cookie name, so it is not accepted under another name.
+func SetEncrypted(c *fiber.Ctx, name string, value string, expire time.Time) error {
+	encrypted, err := encryption.EncryptStringFor(value, name)
+	if err != nil {
+		return err
+	}
+	SetCookie(c, name, encrypted, expire)
+	return nil
+}
+
+// GetEncrypted retrieves and decrypts a cookie set with SetEncrypted.
+func GetEncrypted(c *fiber.Ctx, name string) (string, error) {
+	cookie, err := GetCookie(c, name)
+	if err != nil {
+		return "", err
+	}
+	return encryption.DecryptStringFor(cookie, name)
+}
+
+// SetSigned signs value with the application key and sets it as a cookie.
+// The value stays readable by the client but cannot be changed or moved to
+// another cookie name.
+func SetSigned(c *fiber.Ctx, name string, value string, expire time.Time) error {
+	signed, err := encryption.SignFor(value, name)
+	if err != nil {
+		return err
+	}
+	SetCookie(c, name, signed, expire)
+	return nil
+}
+
+// GetSigned retrieves a cookie set with SetSigned and verifies its signature.
+func GetSigned(c *fiber.Ctx, name string) (string, error) {
+	cookie, err := GetCookie(c, name)
+	if err != nil {
+		return "", err
+	}
+	return encryption.VerifyFor(cookie, name)
+}
+
 func SetHttpCookie(w http.ResponseWriter, name string, value interface{}, expire time.Duration, secure bool, httpOnly bool, sameSite http.SameSite) {
 	http.SetCookie(w, &http.Cookie{
 		Name:     name,
 		Value:    fmt.Sprint(value),
-		Path:     config.Path,
-		Domain:   config.Domain,
+		Path:     currentConfig().Path,
+		Domain:   currentConfig().Domain,
 		Expires:  time.Now().Add(expire),
 		Secure:   secure,
 		HttpOnly: httpOnly,
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/mousav1/weiser/app/encryption"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 )
@@ -93,6 +94,82 @@ func TestGetCookie(t *testing.T) {
 	assert.Equal(t, "Hello, World!", string(body))
 }
 
+func TestEncryptedAndSignedCookies(t *testing.T) {
+	key, _ := encryption.GenerateKey()
+	parsed, _ := encryption.ParseKey(key)
+	encrypter, _ := encryption.New(parsed)
+	encryption.SetDefault(encrypter)
+	defer encryption.SetDefault(nil)
+
+	app := fiber.New()
+	app.Get("/set", func(c *fiber.Ctx) error {
+		if err := SetEncrypted(c, "encrypted", "secret", time.Now().Add(time.Hour)); err != nil {
+			return err
+		}
+		if err := SetEncrypted(c, "other_encrypted", "other", time.Now().Add(time.Hour)); err != nil {
+			return err
+		}
+		if err := SetSigned(c, "other_signed", "user-1", time.Now().Add(time.Hour)); err != nil {
+			return err
+		}
+		return SetSigned(c, "signed", "user-42", time.Now().Add(time.Hour))
+	})
+	app.Get("/get", func(c *fiber.Ctx) error {
+		encrypted, err := GetEncrypted(c, "encrypted")
+		if err != nil {
+			return c.SendStatus(http.StatusBadRequest)
+		}
+		signed, err := GetSigned(c, "signed")
+		if err != nil {
+			return c.SendStatus(http.StatusBadRequest)
+		}
+		return c.SendString(encrypted + "," + signed)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/set", nil))
+	if err != nil {
+		t.Fatalf("Failed to execute request: %v", err)
+	}
+
+	setCookies := resp.Cookies()
+	req := httptest.NewRequest(http.MethodGet, "/get", nil)
+	for _, cookie := range setCookies {
+		assert.NotContains(t, cookie.Value, "secret")
+		req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+
+	resp, err = app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to execute request: %v", err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(t, "secret,user-42", string(body))
+
+	// A tampered signed cookie is rejected
+	req = httptest.NewRequest(http.MethodGet, "/get", nil)
+	for _, cookie := range setCookies {
+		if cookie.Name == "encrypted" {
+			req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+		}
+	}
+	req.AddCookie(&http.Cookie{Name: "signed", Value: "user-1.invalid"})
+	resp, _ = app.Test(req)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// Values are only accepted under the name they were set with
+	values := make(map[string]string)
+	for _, cookie := range setCookies {
+		values[cookie.Name] = cookie.Value
+	}
+	for _, swapped := range [][2]string{{"other_encrypted", "signed"}, {"encrypted", "other_signed"}} {
+		req = httptest.NewRequest(http.MethodGet, "/get", nil)
+		req.AddCookie(&http.Cookie{Name: "encrypted", Value: values[swapped[0]]})
+		req.AddCookie(&http.Cookie{Name: "signed", Value: values[swapped[1]]})
+		resp, _ = app.Test(req)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
 func TestSetHttpCookie(t *testing.T) {
 	name := "my_cookie"
 	value := "cookie_value"
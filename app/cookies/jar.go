@@ -0,0 +1,105 @@
+package cookies
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// jarLocalsKey is the key of the request locals holding the cookie jar.
+const jarLocalsKey = "cookieJar"
+
+// Jar holds the cookies queued during a request. The queued cookies are
+// added to the response by the AddQueuedCookies middleware once the handler
+// returns.
+type Jar struct {
+	queued []queuedCookie
+}
+
+type queuedCookie struct {
+	name    string
+	value   string
+	options Options
+}
+
+// JarFromContext returns the cookie jar of the request.
+func JarFromContext(c *fiber.Ctx) *Jar {
+	if jar, ok := c.Locals(jarLocalsKey).(*Jar); ok {
+		return jar
+	}
+	jar := &Jar{}
+	c.Locals(jarLocalsKey, jar)
+	return jar
+}
+
+// Queue queues a cookie to be added to the response of the request.
+func Queue(c *fiber.Ctx, name, value string, opts ...Option) {
+	JarFromContext(c).Queue(name, value, opts...)
+}
+
+// Forget queues the removal of a cookie from the client.
+func Forget(c *fiber.Ctx, name string, opts ...Option) {
+	JarFromContext(c).Forget(name, opts...)
+}
+
+// AttachQueued adds the queued cookies to the response and empties the jar.
+func AttachQueued(c *fiber.Ctx) {
+	jar, ok := c.Locals(jarLocalsKey).(*Jar)
+	if !ok {
+		return
+	}
+
+	for _, cookie := range jar.queued {
+		writeCookie(c, cookie.name, cookie.value, cookie.options)
+	}
+	jar.queued = nil
+}
+
+// Queue queues a cookie. A cookie queued earlier with the same name and
+// path is replaced.
+func (j *Jar) Queue(name, value string, opts ...Option) {
+	options := defaultOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	for i, cookie := range j.queued {
+		if cookie.name == name && cookie.options.Path == options.Path {
+			j.queued[i] = queuedCookie{name: name, value: value, options: options}
+			return
+		}
+	}
+	j.queued = append(j.queued, queuedCookie{name: name, value: value, options: options})
+}
+
+// Forget queues an expired cookie, which removes the cookie from the client.
+func (j *Jar) Forget(name string, opts ...Option) {
+	opts = append(opts, WithExpires(fasthttp.CookieExpireDelete), WithMaxAge(0))
+	j.Queue(name, "", opts...)
+}
+
+// Unqueue removes a queued cookie.
+func (j *Jar) Unqueue(name string) {
+	queued := j.queued[:0]
+	for _, cookie := range j.queued {
+		if cookie.name != name {
+			queued = append(queued, cookie)
+		}
+	}
+	j.queued = queued
+}
+
+// Queued returns the value of a queued cookie.
+func (j *Jar) Queued(name string) (string, bool) {
+	for _, cookie := range j.queued {
+		if cookie.name == name {
+			return cookie.value, true
+		}
+	}
+	return "", false
+}
+
+// HasQueued reports whether a cookie with the given name is queued.
+func (j *Jar) HasQueued(name string) bool {
+	_, ok := j.Queued(name)
+	return ok
+}
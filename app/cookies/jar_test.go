@@ -0,0 +1,114 @@
+package cookies
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueueAndForget(t *testing.T) {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		err := c.Next()
+		AttachQueued(c)
+		return err
+	})
+	app.Get("/", func(c *fiber.Ctx) error {
+		Queue(c, "theme", "light")
+		Queue(c, "theme", "dark", WithMaxAge(3600))
+		Queue(c, "remember", "yes", WithPath("/account"), WithDomain("example.org"), WithSameSite(http.SameSiteNoneMode))
+		Forget(c, "old")
+
+		jar := JarFromContext(c)
+		assert.True(t, jar.HasQueued("theme"))
+		value, _ := jar.Queued("theme")
+		assert.Equal(t, "dark", value)
+
+		Queue(c, "temporary", "value")
+		jar.Unqueue("temporary")
+		assert.False(t, jar.HasQueued("temporary"))
+		return nil
+	})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatalf("Failed to execute request: %v", err)
+	}
+
+	cookies := make(map[string]*http.Cookie)
+	for _, cookie := range resp.Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+	assert.Len(t, cookies, 3)
+
+	assert.Equal(t, "dark", cookies["theme"].Value)
+	assert.Equal(t, 3600, cookies["theme"].MaxAge)
+
+	assert.Equal(t, "/account", cookies["remember"].Path)
+	assert.Equal(t, "example.org", cookies["remember"].Domain)
+	assert.Equal(t, http.SameSiteNoneMode, cookies["remember"].SameSite)
+
+	assert.Equal(t, "", cookies["old"].Value)
+	assert.True(t, cookies["old"].Expires.Before(time.Now()))
+}
+
+func TestPartitionedCookie(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		SetCookie(c, "embedded", "value", time.Now().Add(time.Hour), WithPartitioned(true), WithSecure(false))
+		return nil
+	})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatalf("Failed to execute request: %v", err)
+	}
+
+	header := resp.Header.Get(fiber.HeaderSetCookie)
+	assert.True(t, strings.HasPrefix(header, "embedded=value"))
+	assert.Contains(t, header, "; Partitioned")
+	assert.Contains(t, header, "; secure")
+}
+
+func TestLoadConfig(t *testing.T) {
+	previous := viper.Get("cookie")
+	defer func() {
+		viper.Set("cookie", previous)
+		LoadConfig()
+	}()
+
+	for value, expected := range map[interface{}]http.SameSite{
+		"strict": http.SameSiteStrictMode,
+		"Lax":    http.SameSiteLaxMode,
+		"none":   http.SameSiteNoneMode,
+		"3":      http.SameSiteStrictMode,
+		4:        http.SameSiteNoneMode,
+		nil:      http.SameSiteDefaultMode,
+	} {
+		assert.Equal(t, expected, parseSameSite(value), "samesite %v", value)
+	}
+
+	viper.Set("cookie", map[string]interface{}{
+		"path":        "/app",
+		"domain":      "example.org",
+		"secure":      false,
+		"samesite":    "strict",
+		"httponly":    false,
+		"partitioned": true,
+	})
+	LoadConfig()
+
+	options := defaultOptions()
+	assert.Equal(t, "/app", options.Path)
+	assert.Equal(t, "example.org", options.Domain)
+	assert.False(t, options.Secure)
+	assert.False(t, options.HTTPOnly)
+	assert.True(t, options.Partitioned)
+	assert.Equal(t, http.SameSiteStrictMode, options.SameSite)
+}
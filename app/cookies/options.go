@@ -0,0 +1,119 @@
+package cookies
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// Options are the attributes of a cookie.
+type Options struct {
+	Path     string
+	Domain   string
+	Expires  time.Time
+	MaxAge   int
+	Secure   bool
+	HTTPOnly bool
+	SameSite http.SameSite
+	// Partitioned stores the cookie per top-level site (CHIPS). Partitioned
+	// cookies are always secure.
+	Partitioned bool
+}
+
+// Option overrides a default attribute of a cookie.
+type Option func(*Options)
+
+// WithPath sets the path of the cookie.
+func WithPath(path string) Option {
+	return func(o *Options) { o.Path = path }
+}
+
+// WithDomain sets the domain of the cookie.
+func WithDomain(domain string) Option {
+	return func(o *Options) { o.Domain = domain }
+}
+
+// WithExpires sets the expiry time of the cookie.
+func WithExpires(expires time.Time) Option {
+	return func(o *Options) { o.Expires = expires }
+}
+
+// WithMaxAge sets the lifetime of the cookie in seconds.
+func WithMaxAge(seconds int) Option {
+	return func(o *Options) { o.MaxAge = seconds }
+}
+
+// WithSecure sets whether the cookie is only sent over HTTPS.
+func WithSecure(secure bool) Option {
+	return func(o *Options) { o.Secure = secure }
+}
+
+// WithHTTPOnly sets whether the cookie is hidden from JavaScript.
+func WithHTTPOnly(httpOnly bool) Option {
+	return func(o *Options) { o.HTTPOnly = httpOnly }
+}
+
+// WithSameSite sets the SameSite attribute of the cookie.
+func WithSameSite(sameSite http.SameSite) Option {
+	return func(o *Options) { o.SameSite = sameSite }
+}
+
+// WithPartitioned sets whether the cookie is partitioned.
+func WithPartitioned(partitioned bool) Option {
+	return func(o *Options) { o.Partitioned = partitioned }
+}
+
+// defaultOptions returns the options from the cookie configuration.
+func defaultOptions() Options {
+	cfg := currentConfig()
+	return Options{
+		Path:        cfg.Path,
+		Domain:      cfg.Domain,
+		Secure:      cfg.Secure,
+		HTTPOnly:    cfg.HTTPOnly,
+		SameSite:    cfg.SameSite,
+		Partitioned: cfg.Partitioned,
+	}
+}
+
+// writeCookie adds the cookie to the response.
+func writeCookie(c *fiber.Ctx, name, value string, options Options) {
+	cookie := fasthttp.AcquireCookie()
+	defer fasthttp.ReleaseCookie(cookie)
+
+	cookie.SetKey(name)
+	cookie.SetValue(value)
+	cookie.SetPath(options.Path)
+	cookie.SetDomain(options.Domain)
+	if !options.Expires.IsZero() {
+		cookie.SetExpire(options.Expires)
+	}
+	cookie.SetMaxAge(options.MaxAge)
+	cookie.SetSecure(options.Secure || options.Partitioned)
+	cookie.SetHTTPOnly(options.HTTPOnly)
+	switch sameSiteToString(options.SameSite) {
+	case "Strict":
+		cookie.SetSameSite(fasthttp.CookieSameSiteStrictMode)
+	case "None":
+		cookie.SetSameSite(fasthttp.CookieSameSiteNoneMode)
+	case "Lax":
+		cookie.SetSameSite(fasthttp.CookieSameSiteLaxMode)
+	}
+
+	WriteCookie(c, cookie, options.Partitioned)
+}
+
+// WriteCookie adds a cookie to the response, replacing a cookie with the
+// same name. fasthttp does not know the Partitioned attribute, so
+// partitioned cookies are written as a raw header.
+func WriteCookie(c *fiber.Ctx, cookie *fasthttp.Cookie, partitioned bool) {
+	if !partitioned {
+		c.Response().Header.SetCookie(cookie)
+		return
+	}
+
+	c.Response().Header.DelCookieBytes(cookie.Key())
+	c.Response().Header.Add(fiber.HeaderSetCookie, cookie.String()+"; Partitioned")
+}
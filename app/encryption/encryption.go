@@ -0,0 +1,355 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/hkdf"
+)
+
+// KeySize is the size in bytes of an AES-256 key.
+const KeySize = 32
+
+// keyPrefix marks base64 encoded keys, as written by key:generate.
+const keyPrefix = "base64:"
+
+// Labels of the subkeys derived from a key, so that encryption and signing
+// never use the same key.
+const (
+	encryptionLabel = "weiser encryption"
+	signingLabel    = "weiser signing"
+)
+
+var (
+	// ErrInvalidPayload is returned when a value cannot be decrypted or its
+	// signature does not match.
+	ErrInvalidPayload = errors.New("encryption: invalid payload")
+	// ErrNotInitialized is returned when the default encrypter is used
+	// before Initialize was called.
+	ErrNotInitialized = errors.New("encryption: app.key is not set, run key:generate")
+)
+
+// Encrypter encrypts values with AES-256-GCM and signs them with
+// HMAC-SHA256, each with its own subkey of the key. Values sealed with one of
+// the previous keys can still be decrypted and verified, so keys can be
+// rotated.
+type Encrypter struct {
+	signingKeys [][]byte
+	aeads       []cipher.AEAD
+}
+
+// New creates an encrypter for key. previousKeys are only used to decrypt
+// and verify values created before the key was rotated.
+func New(key []byte, previousKeys ...[]byte) (*Encrypter, error) {
+	e := &Encrypter{}
+	for _, k := range append([][]byte{key}, previousKeys...) {
+		if len(k) != KeySize {
+			return nil, fmt.Errorf("encryption: key must be %d bytes, got %d", KeySize, len(k))
+		}
+
+		encryptionKey, err := subkey(k, encryptionLabel)
+		if err != nil {
+			return nil, err
+		}
+		signingKey, err := subkey(k, signingLabel)
+		if err != nil {
+			return nil, err
+		}
+
+		block, err := aes.NewCipher(encryptionKey)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+
+		e.signingKeys = append(e.signingKeys, signingKey)
+		e.aeads = append(e.aeads, aead)
+	}
+	return e, nil
+}
+
+// subkey derives the key for the purpose named by label from key with HKDF.
+func subkey(key []byte, label string) ([]byte, error) {
+	derived := make([]byte, KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, key, nil, []byte(label)), derived); err != nil {
+		return nil, err
+	}
+	return derived, nil
+}
+
+// GenerateKey returns a new random key in the format read by ParseKey.
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return keyPrefix + base64.StdEncoding.EncodeToString(key), nil
+}
+
+// ParseKey decodes a "base64:" prefixed key, or returns a raw key as is.
+func ParseKey(key string) ([]byte, error) {
+	if strings.HasPrefix(key, keyPrefix) {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(key, keyPrefix))
+		if err != nil {
+			return nil, fmt.Errorf("encryption: invalid key: %w", err)
+		}
+		return decoded, nil
+	}
+	return []byte(key), nil
+}
+
+// DeriveKey turns a secret of any length into a key.
+func DeriveKey(secret string) []byte {
+	sum := sha256.Sum256([]byte(secret))
+	return sum[:]
+}
+
+// Encrypt encrypts plaintext with the current key. The result is URL and
+// cookie safe.
+func (e *Encrypter) Encrypt(plaintext []byte) (string, error) {
+	return e.EncryptFor(plaintext, "")
+}
+
+// EncryptFor encrypts plaintext bound to context, e.g. the name of the cookie
+// that holds it, so that it only decrypts with the same context.
+func (e *Encrypter) EncryptFor(plaintext []byte, context string) (string, error) {
+	aead := e.aeads[0]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	ciphertext := aead.Seal(nonce, nonce, plaintext, []byte(context))
+	return base64.RawURLEncoding.EncodeToString(ciphertext), nil
+}
+
+// Decrypt decrypts a value created by Encrypt with the current key or one of
+// the previous keys.
+func (e *Encrypter) Decrypt(payload string) ([]byte, error) {
+	return e.DecryptFor(payload, "")
+}
+
+// DecryptFor decrypts a value created by EncryptFor with the same context.
+func (e *Encrypter) DecryptFor(payload string, context string) ([]byte, error) {
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidPayload
+	}
+
+	for _, aead := range e.aeads {
+		if len(data) < aead.NonceSize() {
+			return nil, ErrInvalidPayload
+		}
+		nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+		if plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(context)); err == nil {
+			return plaintext, nil
+		}
+	}
+	return nil, ErrInvalidPayload
+}
+
+// EncryptString encrypts a string.
+func (e *Encrypter) EncryptString(value string) (string, error) {
+	return e.Encrypt([]byte(value))
+}
+
+// DecryptString decrypts a value created by EncryptString.
+func (e *Encrypter) DecryptString(payload string) (string, error) {
+	return e.DecryptStringFor(payload, "")
+}
+
+// EncryptStringFor encrypts a string bound to context, see EncryptFor.
+func (e *Encrypter) EncryptStringFor(value string, context string) (string, error) {
+	return e.EncryptFor([]byte(value), context)
+}
+
+// DecryptStringFor decrypts a value created by EncryptStringFor with the same
+// context.
+func (e *Encrypter) DecryptStringFor(payload string, context string) (string, error) {
+	plaintext, err := e.DecryptFor(payload, context)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// Sign appends a signature to value. The value itself stays readable.
+func (e *Encrypter) Sign(value string) string {
+	return e.SignFor(value, "")
+}
+
+// SignFor signs value bound to context, e.g. the name of the cookie that
+// holds it, so that the signature only verifies with the same context.
+func (e *Encrypter) SignFor(value string, context string) string {
+	return value + "." + signature(e.signingKeys[0], context, value)
+}
+
+// Verify checks the signature of a value created by Sign and returns the
+// original value.
+func (e *Encrypter) Verify(signed string) (string, error) {
+	return e.VerifyFor(signed, "")
+}
+
+// VerifyFor checks the signature of a value created by SignFor with the same
+// context and returns the original value.
+func (e *Encrypter) VerifyFor(signed string, context string) (string, error) {
+	i := strings.LastIndex(signed, ".")
+	if i < 0 {
+		return "", ErrInvalidPayload
+	}
+	value, mac := signed[:i], signed[i+1:]
+
+	for _, key := range e.signingKeys {
+		if hmac.Equal([]byte(mac), []byte(signature(key, context, value))) {
+			return value, nil
+		}
+	}
+	return "", ErrInvalidPayload
+}
+
+// signature signs the context and the value. The context is length-prefixed,
+// so that no context and value pair signs the same input as another.
+func signature(key []byte, context string, value string) string {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(fmt.Sprintf("%d:%s", len(context), context)))
+	h.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+var (
+	defaultEncrypter *Encrypter
+	defaultMutex     sync.RWMutex
+)
+
+// Initialize creates the default encrypter from app.key and app.previous_keys.
+func Initialize() error {
+	key := viper.GetString("app.key")
+	if key == "" {
+		return ErrNotInitialized
+	}
+
+	parsed, err := ParseKey(key)
+	if err != nil {
+		return err
+	}
+
+	var previous [][]byte
+	for _, k := range viper.GetStringSlice("app.previous_keys") {
+		p, err := ParseKey(k)
+		if err != nil {
+			return err
+		}
+		previous = append(previous, p)
+	}
+
+	e, err := New(parsed, previous...)
+	if err != nil {
+		return err
+	}
+
+	SetDefault(e)
+	return nil
+}
+
+// SetDefault replaces the default encrypter.
+func SetDefault(e *Encrypter) {
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+	defaultEncrypter = e
+}
+
+// Default returns the default encrypter.
+func Default() (*Encrypter, error) {
+	defaultMutex.RLock()
+	defer defaultMutex.RUnlock()
+	if defaultEncrypter == nil {
+		return nil, ErrNotInitialized
+	}
+	return defaultEncrypter, nil
+}
+
+// EncryptString encrypts a string with the default encrypter.
+func EncryptString(value string) (string, error) {
+	e, err := Default()
+	if err != nil {
+		return "", err
+	}
+	return e.EncryptString(value)
+}
+
+// DecryptString decrypts a string with the default encrypter.
+func DecryptString(payload string) (string, error) {
+	e, err := Default()
+	if err != nil {
+		return "", err
+	}
+	return e.DecryptString(payload)
+}
+
+// Sign signs a value with the default encrypter.
+func Sign(value string) (string, error) {
+	e, err := Default()
+	if err != nil {
+		return "", err
+	}
+	return e.Sign(value), nil
+}
+
+// Verify verifies a signed value with the default encrypter.
+func Verify(signed string) (string, error) {
+	e, err := Default()
+	if err != nil {
+		return "", err
+	}
+	return e.Verify(signed)
+}
+
+// EncryptStringFor encrypts a string bound to context with the default
+// encrypter.
+func EncryptStringFor(value string, context string) (string, error) {
+	e, err := Default()
+	if err != nil {
+		return "", err
+	}
+	return e.EncryptStringFor(value, context)
+}
+
+// DecryptStringFor decrypts a string bound to context with the default
+// encrypter.
+func DecryptStringFor(payload string, context string) (string, error) {
+	e, err := Default()
+	if err != nil {
+		return "", err
+	}
+	return e.DecryptStringFor(payload, context)
+}
+
+// SignFor signs a value bound to context with the default encrypter.
+func SignFor(value string, context string) (string, error) {
+	e, err := Default()
+	if err != nil {
+		return "", err
+	}
+	return e.SignFor(value, context), nil
+}
+
+// VerifyFor verifies a value signed for context with the default encrypter.
+func VerifyFor(signed string, context string) (string, error) {
+	e, err := Default()
+	if err != nil {
+		return "", err
+	}
+	return e.VerifyFor(signed, context)
+}
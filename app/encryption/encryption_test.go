@@ -0,0 +1,138 @@
+package encryption
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestKey(t *testing.T) []byte {
+	key, err := GenerateKey()
+	assert.NoError(t, err)
+	parsed, err := ParseKey(key)
+	assert.NoError(t, err)
+	return parsed
+}
+
+func newTestEncrypter(t *testing.T, previous ...[]byte) *Encrypter {
+	e, err := New(newTestKey(t), previous...)
+	assert.NoError(t, err)
+	return e
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	e := newTestEncrypter(t)
+
+	encrypted, err := e.EncryptString("secret value")
+	assert.NoError(t, err)
+	assert.NotContains(t, encrypted, "secret")
+
+	decrypted, err := e.DecryptString(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "secret value", decrypted)
+
+	_, err = e.DecryptString(encrypted[:len(encrypted)-2])
+	assert.ErrorIs(t, err, ErrInvalidPayload)
+
+	_, err = newTestEncrypter(t).DecryptString(encrypted)
+	assert.ErrorIs(t, err, ErrInvalidPayload)
+}
+
+func TestSignVerify(t *testing.T) {
+	e := newTestEncrypter(t)
+
+	signed := e.Sign("user.42")
+	assert.True(t, strings.HasPrefix(signed, "user.42."))
+
+	value, err := e.Verify(signed)
+	assert.NoError(t, err)
+	assert.Equal(t, "user.42", value)
+
+	_, err = e.Verify(strings.Replace(signed, "42", "43", 1))
+	assert.ErrorIs(t, err, ErrInvalidPayload)
+}
+
+func TestContext(t *testing.T) {
+	e := newTestEncrypter(t)
+
+	encrypted, err := e.EncryptStringFor("value", "theme")
+	assert.NoError(t, err)
+	decrypted, err := e.DecryptStringFor(encrypted, "theme")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", decrypted)
+	_, err = e.DecryptStringFor(encrypted, "session")
+	assert.ErrorIs(t, err, ErrInvalidPayload)
+	_, err = e.DecryptString(encrypted)
+	assert.ErrorIs(t, err, ErrInvalidPayload)
+
+	signed := e.SignFor("value", "theme")
+	value, err := e.VerifyFor(signed, "theme")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", value)
+	_, err = e.VerifyFor(signed, "session")
+	assert.ErrorIs(t, err, ErrInvalidPayload)
+	_, err = e.Verify(signed)
+	assert.ErrorIs(t, err, ErrInvalidPayload)
+}
+
+func TestSubkeys(t *testing.T) {
+	key := newTestKey(t)
+	e, err := New(key)
+	assert.NoError(t, err)
+
+	// The signature is not made with the key itself
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("0:value"))
+	assert.NotEqual(t, "value."+base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), e.Sign("value"))
+
+	encryptionKey, err := subkey(key, encryptionLabel)
+	assert.NoError(t, err)
+	signingKey, err := subkey(key, signingLabel)
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, encryptionKey)
+	assert.NotEqual(t, encryptionKey, signingKey)
+}
+
+func TestKeyRotation(t *testing.T) {
+	oldKey := newTestKey(t)
+	old, err := New(oldKey)
+	assert.NoError(t, err)
+	encrypted, _ := old.EncryptString("value")
+	signed := old.Sign("value")
+
+	rotated := newTestEncrypter(t, oldKey)
+
+	decrypted, err := rotated.DecryptString(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "value", decrypted)
+
+	value, err := rotated.Verify(signed)
+	assert.NoError(t, err)
+	assert.Equal(t, "value", value)
+}
+
+func TestNew_InvalidKey(t *testing.T) {
+	_, err := New([]byte("too short"))
+	assert.Error(t, err)
+}
+
+func TestInitialize(t *testing.T) {
+	viper.Set("app.key", "")
+	assert.ErrorIs(t, Initialize(), ErrNotInitialized)
+
+	key, _ := GenerateKey()
+	viper.Set("app.key", key)
+	defer viper.Set("app.key", "")
+	assert.NoError(t, Initialize())
+
+	encrypted, err := EncryptString("value")
+	assert.NoError(t, err)
+	decrypted, err := DecryptString(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "value", decrypted)
+}
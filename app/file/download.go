@@ -0,0 +1,249 @@
+package file
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mousav1/weiser/app/storage"
+)
+
+// Disposition tells the browser whether to display a file or save it.
+type Disposition string
+
+const (
+	// Inline displays the file in the browser when it can, e.g. images and PDFs.
+	Inline Disposition = "inline"
+	// Attachment asks the browser to save the file.
+	Attachment Disposition = "attachment"
+)
+
+// errRangeNotSatisfiable is returned by parseRange for ranges outside the file.
+var errRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// DownloadOptions configures how Respond sends a file.
+type DownloadOptions struct {
+	// Name is the file name offered to the client; defaults to the base name of the path.
+	Name string
+	// Disposition defaults to Attachment.
+	Disposition Disposition
+	// CacheControl is sent as the Cache-Control header when set.
+	CacheControl string
+}
+
+// Respond streams a file from disk to the client. It sets the
+// Content-Type, Content-Disposition, ETag and Last-Modified headers,
+// answers conditional requests (If-None-Match, If-Modified-Since) with 304
+// and serves single byte ranges (Range, If-Range) with 206. Files are sent
+// uncompressed, so that byte ranges and ETags always describe the stored
+// bytes.
+func Respond(c *fiber.Ctx, disk storage.Storage, filePath string, options DownloadOptions) error {
+	meta, err := storage.Stat(disk, filePath)
+	switch {
+	case errors.Is(err, os.ErrNotExist), errors.Is(err, storage.ErrIsDirectory), errors.Is(err, storage.ErrPathOutsideRoot):
+		return c.Status(fiber.StatusNotFound).SendString("file not found")
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).SendString("failed to read file")
+	}
+
+	if options.Name == "" {
+		options.Name = path.Base(filePath)
+	}
+	if options.Disposition == "" {
+		options.Disposition = Attachment
+	}
+	etag := entityTag(meta)
+	lastModified := meta.LastModified.UTC().Truncate(time.Second)
+
+	if etag != "" {
+		c.Set(fiber.HeaderETag, etag)
+	}
+	if !lastModified.IsZero() {
+		c.Set(fiber.HeaderLastModified, lastModified.Format(http.TimeFormat))
+	}
+	if options.CacheControl != "" {
+		c.Set(fiber.HeaderCacheControl, options.CacheControl)
+	}
+	if notModified(c, etag, lastModified) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	status, offset, length := fiber.StatusOK, int64(0), meta.Size
+	if header := c.Get(fiber.HeaderRange); header != "" && ifRange(c.Get(fiber.HeaderIfRange), etag, lastModified) {
+		start, size, ok, err := parseRange(header, meta.Size)
+		if err != nil {
+			c.Set(fiber.HeaderContentRange, "bytes */"+strconv.FormatInt(meta.Size, 10))
+			return c.Status(fiber.StatusRequestedRangeNotSatisfiable).SendString("requested range not satisfiable")
+		}
+		if ok {
+			status, offset, length = fiber.StatusPartialContent, start, size
+			c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d", start, start+size-1, meta.Size))
+		}
+	}
+
+	contentType := meta.MimeType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(options.Name))
+	}
+	if contentType == "" {
+		contentType = fiber.MIMEOctetStream
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, contentDisposition(options.Disposition, options.Name))
+	c.Set(fiber.HeaderAcceptRanges, "bytes")
+	// Keeps the compress middleware from encoding the body.
+	c.Set(fiber.HeaderContentEncoding, "identity")
+	c.Status(status)
+
+	if c.Method() == fiber.MethodHead {
+		c.Response().Header.SetContentLength(int(length))
+		return nil
+	}
+	content, err := storage.ReadRange(disk, filePath, offset, length)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("failed to read file")
+	}
+	c.Response().SetBodyStream(content, int(length))
+	return nil
+}
+
+// Download sends a file from disk as an attachment named name.
+func Download(c *fiber.Ctx, disk storage.Storage, filePath string, name string) error {
+	return Respond(c, disk, filePath, DownloadOptions{Name: name, Disposition: Attachment})
+}
+
+// Show sends a file from disk to be displayed in the browser.
+func Show(c *fiber.Ctx, disk storage.Storage, filePath string) error {
+	return Respond(c, disk, filePath, DownloadOptions{Disposition: Inline})
+}
+
+// entityTag returns a strong ETag from the checksum the disk stores for the
+// file, or a weak one from its size and modification time. Files are never
+// read to compute a checksum. It returns "" when the disk knows neither.
+func entityTag(meta storage.Metadata) string {
+	if meta.Checksum != "" {
+		return strconv.Quote(meta.Checksum)
+	}
+	if meta.LastModified.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf(`W/"%x-%x"`, meta.Size, meta.LastModified.UnixNano())
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since when the
+// request has no If-None-Match header.
+func notModified(c *fiber.Ctx, etag string, lastModified time.Time) bool {
+	if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+		return false
+	}
+	if header := c.Get(fiber.HeaderIfNoneMatch); header != "" {
+		for _, candidate := range strings.Split(header, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	since, err := http.ParseTime(c.Get(fiber.HeaderIfModifiedSince))
+	return err == nil && !lastModified.IsZero() && !lastModified.After(since)
+}
+
+// ifRange reports whether a Range header applies. If-Range holds either an
+// ETag, which must match strongly, or a date, which must match exactly.
+func ifRange(header string, etag string, lastModified time.Time) bool {
+	if header == "" {
+		return true
+	}
+	if strings.HasPrefix(header, `"`) || strings.HasPrefix(header, "W/") {
+		return header == etag && !strings.HasPrefix(etag, "W/")
+	}
+	date, err := http.ParseTime(header)
+	return err == nil && date.Equal(lastModified)
+}
+
+// parseRange parses a Range header with a single byte range and returns its
+// start and length. ok is false for headers that should be ignored, such as
+// other units, invalid syntax or multiple ranges, in which case the whole
+// file is sent.
+func parseRange(header string, size int64) (start int64, length int64, ok bool, err error) {
+	spec, found := strings.CutPrefix(header, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, 0, false, nil
+	}
+	first, last, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, 0, false, nil
+	}
+
+	if first == "" {
+		// A suffix range such as "-500" selects the last 500 bytes.
+		suffix, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || suffix < 0 {
+			return 0, 0, false, nil
+		}
+		if suffix == 0 || size == 0 {
+			return 0, 0, false, errRangeNotSatisfiable
+		}
+		if suffix > size {
+			suffix = size
+		}
+		return size - suffix, suffix, true, nil
+	}
+
+	start, err = strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, false, nil
+	}
+	end := size - 1
+	if last != "" {
+		if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+			return 0, 0, false, nil
+		}
+		if end > size-1 {
+			end = size - 1
+		}
+	}
+	if start >= size {
+		return 0, 0, false, errRangeNotSatisfiable
+	}
+	return start, end - start + 1, true, nil
+}
+
+// contentDisposition builds a Content-Disposition header. Names that are
+// not plain ASCII get an ASCII fallback and an RFC 5987 encoded filename*.
+func contentDisposition(disposition Disposition, name string) string {
+	fallback := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, name)
+	value := fmt.Sprintf(`%s; filename="%s"`, disposition, fallback)
+	if fallback != name {
+		value += "; filename*=UTF-8''" + encodeAttribute(name)
+	}
+	return value
+}
+
+// encodeAttribute percent-encodes everything but the attr-char set of RFC 5987.
+func encodeAttribute(value string) string {
+	const attrChars = "!#$&+-.^_`|~"
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		ch := value[i]
+		if ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' || strings.IndexByte(attrChars, ch) >= 0 {
+			b.WriteByte(ch)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", ch)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,193 @@
+package file
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/compress"
+	"github.com/mousav1/weiser/app/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDownloadApp(t *testing.T) *fiber.App {
+	disk := storage.NewMemoryDriver()
+	assert.NoError(t, disk.Put("docs/report.txt", strings.NewReader("0123456789")))
+	assert.NoError(t, disk.Put("docs/گزارش.txt", strings.NewReader("hello")))
+
+	app := fiber.New()
+	app.Get("/download/*", func(c *fiber.Ctx) error {
+		return Download(c, disk, c.Params("*"), "")
+	})
+	app.Get("/show/*", func(c *fiber.Ctx) error {
+		return Show(c, disk, c.Params("*"))
+	})
+	return app
+}
+
+// request sends a request with the given headers and returns the response and its body.
+func request(t *testing.T, app *fiber.App, method string, target string, headers map[string]string) (*http.Response, string) {
+	req := httptest.NewRequest(method, target, nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	return resp, string(body)
+}
+
+func TestRespond(t *testing.T) {
+	app := newDownloadApp(t)
+
+	resp, body := request(t, app, fiber.MethodGet, "/download/docs/report.txt", nil)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "0123456789", body)
+	assert.Equal(t, "text/plain; charset=utf-8", resp.Header.Get(fiber.HeaderContentType))
+	assert.Equal(t, `attachment; filename="report.txt"`, resp.Header.Get(fiber.HeaderContentDisposition))
+	assert.Equal(t, "bytes", resp.Header.Get(fiber.HeaderAcceptRanges))
+	// دیسک حافظه checksum ذخیره نمی‌کند، پس ETag از اندازه و زمان تغییر ساخته می‌شود
+	assert.True(t, strings.HasPrefix(resp.Header.Get(fiber.HeaderETag), `W/"a-`))
+	assert.NotEmpty(t, resp.Header.Get(fiber.HeaderLastModified))
+
+	resp, _ = request(t, app, fiber.MethodGet, "/show/docs/گزارش.txt", nil)
+	assert.Equal(t, `inline; filename="_____.txt"; filename*=UTF-8''%DA%AF%D8%B2%D8%A7%D8%B1%D8%B4.txt`, resp.Header.Get(fiber.HeaderContentDisposition))
+
+	resp, body = request(t, app, fiber.MethodHead, "/download/docs/report.txt", nil)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "10", resp.Header.Get(fiber.HeaderContentLength))
+	assert.Empty(t, body)
+
+	resp, _ = request(t, app, fiber.MethodGet, "/download/docs/missing.txt", nil)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestRespond_Range(t *testing.T) {
+	app := newDownloadApp(t)
+
+	resp, body := request(t, app, fiber.MethodGet, "/download/docs/report.txt", map[string]string{"Range": "bytes=2-5"})
+	assert.Equal(t, fiber.StatusPartialContent, resp.StatusCode)
+	assert.Equal(t, "2345", body)
+	assert.Equal(t, "bytes 2-5/10", resp.Header.Get(fiber.HeaderContentRange))
+
+	resp, body = request(t, app, fiber.MethodGet, "/download/docs/report.txt", map[string]string{"Range": "bytes=-3"})
+	assert.Equal(t, fiber.StatusPartialContent, resp.StatusCode)
+	assert.Equal(t, "789", body)
+
+	resp, body = request(t, app, fiber.MethodGet, "/download/docs/report.txt", map[string]string{"Range": "bytes=7-"})
+	assert.Equal(t, "789", body)
+	assert.Equal(t, "bytes 7-9/10", resp.Header.Get(fiber.HeaderContentRange))
+
+	resp, _ = request(t, app, fiber.MethodGet, "/download/docs/report.txt", map[string]string{"Range": "bytes=20-"})
+	assert.Equal(t, fiber.StatusRequestedRangeNotSatisfiable, resp.StatusCode)
+	assert.Equal(t, "bytes */10", resp.Header.Get(fiber.HeaderContentRange))
+
+	// چند بازه یا If-Range قدیمی باعث ارسال کل فایل می‌شود
+	resp, body = request(t, app, fiber.MethodGet, "/download/docs/report.txt", map[string]string{"Range": "bytes=0-1,4-5"})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "0123456789", body)
+
+	resp, body = request(t, app, fiber.MethodGet, "/download/docs/report.txt", map[string]string{"Range": "bytes=2-5", "If-Range": `"stale"`})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "0123456789", body)
+
+	// ETag ضعیف برای If-Range پذیرفته نمی‌شود ولی تاریخ آخرین تغییر پذیرفته می‌شود
+	resp, _ = request(t, app, fiber.MethodGet, "/download/docs/report.txt", nil)
+	etag, lastModified := resp.Header.Get(fiber.HeaderETag), resp.Header.Get(fiber.HeaderLastModified)
+
+	resp, body = request(t, app, fiber.MethodGet, "/download/docs/report.txt", map[string]string{"Range": "bytes=2-5", "If-Range": etag})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "0123456789", body)
+
+	resp, body = request(t, app, fiber.MethodGet, "/download/docs/report.txt", map[string]string{"Range": "bytes=2-5", "If-Range": lastModified})
+	assert.Equal(t, fiber.StatusPartialContent, resp.StatusCode)
+	assert.Equal(t, "2345", body)
+}
+
+func TestRespond_Compression(t *testing.T) {
+	disk := storage.NewMemoryDriver()
+	assert.NoError(t, disk.Put("docs/report.txt", strings.NewReader(strings.Repeat("0123456789", 100))))
+	app := fiber.New()
+	app.Use(compress.New())
+	app.Get("/download/*", func(c *fiber.Ctx) error {
+		return Download(c, disk, c.Params("*"), "")
+	})
+
+	// بازه‌ها و ETag به بایت‌های ذخیره شده اشاره می‌کنند، پس فایل فشرده نمی‌شود
+	resp, body := request(t, app, fiber.MethodGet, "/download/docs/report.txt", map[string]string{"Range": "bytes=2-5", "Accept-Encoding": "gzip"})
+	assert.Equal(t, fiber.StatusPartialContent, resp.StatusCode)
+	assert.Equal(t, "identity", resp.Header.Get(fiber.HeaderContentEncoding))
+	assert.Equal(t, "2345", body)
+
+	resp, body = request(t, app, fiber.MethodGet, "/download/docs/report.txt", map[string]string{"Accept-Encoding": "gzip"})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "identity", resp.Header.Get(fiber.HeaderContentEncoding))
+	assert.Len(t, body, 1000)
+}
+
+func TestRespond_Directory(t *testing.T) {
+	disk := storage.NewLocalDriver(t.TempDir())
+	assert.NoError(t, disk.Put("docs/report.txt", strings.NewReader("report")))
+	app := fiber.New()
+	app.Get("/download/*", func(c *fiber.Ctx) error {
+		return Download(c, disk, c.Params("*"), "")
+	})
+
+	for _, target := range []string{"/download/", "/download/docs"} {
+		resp, _ := request(t, app, fiber.MethodGet, target, nil)
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode, target)
+	}
+}
+
+func TestRespond_Conditional(t *testing.T) {
+	app := newDownloadApp(t)
+
+	resp, _ := request(t, app, fiber.MethodGet, "/download/docs/report.txt", nil)
+	etag := resp.Header.Get(fiber.HeaderETag)
+	lastModified := resp.Header.Get(fiber.HeaderLastModified)
+
+	resp, body := request(t, app, fiber.MethodGet, "/download/docs/report.txt", map[string]string{"If-None-Match": `"other", ` + etag})
+	assert.Equal(t, fiber.StatusNotModified, resp.StatusCode)
+	assert.Equal(t, etag, resp.Header.Get(fiber.HeaderETag))
+	assert.Empty(t, body)
+
+	resp, _ = request(t, app, fiber.MethodGet, "/download/docs/report.txt", map[string]string{"If-Modified-Since": lastModified})
+	assert.Equal(t, fiber.StatusNotModified, resp.StatusCode)
+
+	// If-None-Match بر If-Modified-Since اولویت دارد
+	resp, _ = request(t, app, fiber.MethodGet, "/download/docs/report.txt", map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": lastModified})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	resp, _ = request(t, app, fiber.MethodGet, "/download/docs/report.txt", map[string]string{"If-Modified-Since": time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		header string
+		start  int64
+		length int64
+		ok     bool
+		err    bool
+	}{
+		{"bytes=0-0", 0, 1, true, false},
+		{"bytes=5-100", 5, 5, true, false},
+		{"bytes=-100", 0, 10, true, false},
+		{"bytes=10-", 0, 0, false, true},
+		{"bytes=-0", 0, 0, false, true},
+		{"bytes=5-2", 0, 0, false, false},
+		{"bytes=abc", 0, 0, false, false},
+		{"items=0-1", 0, 0, false, false},
+	}
+	for _, test := range tests {
+		start, length, ok, err := parseRange(test.header, 10)
+		assert.Equal(t, test.start, start, test.header)
+		assert.Equal(t, test.length, length, test.header)
+		assert.Equal(t, test.ok, ok, test.header)
+		assert.Equal(t, test.err, err != nil, test.header)
+	}
+}
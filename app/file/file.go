@@ -4,9 +4,9 @@ import (
 	"errors"
 	"io/ioutil"
 	"os"
-	"path/filepath"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/mousav1/weiser/app/storage"
 )
 
 // WriteToFile writes data to a file
@@ -50,8 +50,11 @@ func GetFileSize(filename string) (int64, error) {
 	return file.Size(), nil
 }
 
-// UploadFile returns a handler function for uploading files
+// UploadFile returns a handler function for uploading files to a local
+// directory. Files are stored under generated names; use an Uploader to
+// store them on a configured storage disk.
 func UploadFile(uploadDir string) fiber.Handler {
+	uploader := NewUploader(storage.NewLocalDriver(uploadDir), UploadOptions{})
 	return func(c *fiber.Ctx) error {
 		// Check request method
 		if c.Method() != "POST" {
@@ -63,35 +66,21 @@ func UploadFile(uploadDir string) fiber.Handler {
 		if err != nil {
 			return c.Status(fiber.StatusBadRequest).SendString("failed to upload file")
 		}
-		err = c.SaveFile(file, uploadDir+"/"+file.Filename)
+		uploaded, err := uploader.Store(file)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).SendString("failed to save file")
+			return sendUploadError(c, err)
 		}
 
 		// Return the saved file name
-		return c.SendString(file.Filename)
+		return c.SendString(uploaded.Path)
 	}
 }
 
-// DownloadFile returns a handler function for downloading files
+// DownloadFile returns a handler function for downloading files from a
+// local directory. Use Respond to send files from a storage disk.
 func DownloadFile(uploadDir string) fiber.Handler {
+	disk := storage.NewLocalDriver(uploadDir)
 	return func(c *fiber.Ctx) error {
-		// Open the file
-		filename := c.Params("filename")
-		filePath := filepath.Join(uploadDir, filename)
-		file, err := os.Open(filePath)
-		if err != nil {
-			return c.Status(fiber.StatusNotFound).SendString("file not found")
-		}
-		defer file.Close()
-
-		// Read the file content into a buffer
-		fileContent, err := ioutil.ReadAll(file)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).SendString("failed to read file")
-		}
-
-		// Send the file content to the client
-		return c.Send(fileContent)
+		return Respond(c, disk, c.Params("filename"), DownloadOptions{Disposition: Attachment})
 	}
 }
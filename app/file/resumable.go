@@ -0,0 +1,364 @@
+package file
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// tusVersion is the version of the tus resumable upload protocol served by Resumable.
+const tusVersion = "1.0.0"
+
+var (
+	// ErrUploadNotFound is returned for unknown or expired resumable uploads.
+	ErrUploadNotFound = errors.New("upload not found")
+	// ErrOffsetMismatch is returned when a chunk does not start at the current upload offset.
+	ErrOffsetMismatch = errors.New("upload offset does not match")
+)
+
+// ResumableUpload is the state of an incomplete upload. Chunks are appended
+// to a staging file and the file is moved to the disk once Offset reaches Length.
+type ResumableUpload struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Length    int64     `json:"length"`
+	Offset    int64     `json:"offset"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Complete reports whether all bytes of the upload have been received.
+func (r ResumableUpload) Complete() bool {
+	return r.Offset == r.Length
+}
+
+// Create starts a resumable upload of length bytes.
+func (u *Uploader) Create(name string, length int64) (ResumableUpload, error) {
+	if length < 0 {
+		return ResumableUpload{}, fmt.Errorf("invalid upload length %d", length)
+	}
+	if u.options.MaxSize > 0 && length > u.options.MaxSize {
+		return ResumableUpload{}, ErrFileTooLarge
+	}
+	if err := os.MkdirAll(u.options.StagingPath, 0700); err != nil {
+		return ResumableUpload{}, err
+	}
+	id, err := randomID()
+	if err != nil {
+		return ResumableUpload{}, err
+	}
+
+	upload := ResumableUpload{ID: id, Name: name, Length: length, CreatedAt: time.Now()}
+	if err := os.WriteFile(u.stagingFile(id), nil, 0600); err != nil {
+		return ResumableUpload{}, err
+	}
+	if err := u.saveUpload(upload); err != nil {
+		os.Remove(u.stagingFile(id))
+		return ResumableUpload{}, err
+	}
+	return upload, nil
+}
+
+// Status returns the state of an incomplete upload.
+func (u *Uploader) Status(id string) (ResumableUpload, error) {
+	if !validID(id) {
+		return ResumableUpload{}, ErrUploadNotFound
+	}
+	data, err := os.ReadFile(u.infoFile(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return ResumableUpload{}, ErrUploadNotFound
+	}
+	if err != nil {
+		return ResumableUpload{}, err
+	}
+
+	var upload ResumableUpload
+	if err := json.Unmarshal(data, &upload); err != nil {
+		return ResumableUpload{}, err
+	}
+	if time.Since(upload.CreatedAt) > u.options.Expiration {
+		u.remove(id)
+		return ResumableUpload{}, ErrUploadNotFound
+	}
+	return upload, nil
+}
+
+// Append writes a chunk starting at offset. Bytes received before an
+// interrupted request are kept, so the client can resume from the returned
+// offset. When the last chunk arrives the file is stored on the disk and
+// returned; a file rejected by the size or type limits is discarded.
+func (u *Uploader) Append(id string, offset int64, chunk io.Reader) (ResumableUpload, *UploadedFile, error) {
+	lock := u.lock(id)
+	lock.Lock()
+	defer lock.Unlock()
+
+	upload, err := u.Status(id)
+	if err != nil {
+		return ResumableUpload{}, nil, err
+	}
+	if offset != upload.Offset {
+		return upload, nil, ErrOffsetMismatch
+	}
+
+	file, err := os.OpenFile(u.stagingFile(id), os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return upload, nil, err
+	}
+	remaining := upload.Length - upload.Offset
+	written, copyErr := io.Copy(file, io.LimitReader(chunk, remaining+1))
+	if err := file.Close(); err != nil && copyErr == nil {
+		copyErr = err
+	}
+	if written > remaining {
+		// The chunk ran past the declared length; drop the extra bytes.
+		if err := os.Truncate(u.stagingFile(id), upload.Length); err != nil {
+			return upload, nil, err
+		}
+		written, copyErr = remaining, ErrFileTooLarge
+	}
+	upload.Offset += written
+	if err := u.saveUpload(upload); err != nil {
+		return upload, nil, err
+	}
+	if copyErr != nil {
+		return upload, nil, copyErr
+	}
+	if !upload.Complete() {
+		return upload, nil, nil
+	}
+
+	uploaded, err := u.finish(upload)
+	if err != nil {
+		return upload, nil, err
+	}
+	return upload, &uploaded, nil
+}
+
+// finish moves a complete upload from the staging area to the disk.
+func (u *Uploader) finish(upload ResumableUpload) (UploadedFile, error) {
+	file, err := os.Open(u.stagingFile(upload.ID))
+	if err != nil {
+		return UploadedFile{}, err
+	}
+	uploaded, err := u.Put(upload.Name, file)
+	file.Close()
+	if err != nil && !errors.Is(err, ErrFileTooLarge) && !errors.Is(err, ErrTypeNotAllowed) {
+		// Keep the staged file so that a temporary disk failure can be retried.
+		return UploadedFile{}, err
+	}
+	u.remove(upload.ID)
+	return uploaded, err
+}
+
+// Cancel discards an incomplete upload.
+func (u *Uploader) Cancel(id string) error {
+	if _, err := u.Status(id); err != nil {
+		return err
+	}
+	return u.remove(id)
+}
+
+// CleanExpired removes incomplete uploads older than the expiration and
+// returns how many were removed.
+func (u *Uploader) CleanExpired() (int, error) {
+	infos, err := filepath.Glob(filepath.Join(u.options.StagingPath, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, info := range infos {
+		id := strings.TrimSuffix(filepath.Base(info), ".json")
+		if _, err := u.Status(id); errors.Is(err, ErrUploadNotFound) {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func (u *Uploader) stagingFile(id string) string {
+	return filepath.Join(u.options.StagingPath, id+".part")
+}
+
+func (u *Uploader) infoFile(id string) string {
+	return filepath.Join(u.options.StagingPath, id+".json")
+}
+
+func (u *Uploader) saveUpload(upload ResumableUpload) error {
+	data, err := json.Marshal(upload)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(u.infoFile(upload.ID), data, 0600)
+}
+
+func (u *Uploader) remove(id string) error {
+	u.mutex.Lock()
+	delete(u.locks, id)
+	u.mutex.Unlock()
+
+	err := os.Remove(u.stagingFile(id))
+	if infoErr := os.Remove(u.infoFile(id)); err == nil {
+		err = infoErr
+	}
+	return err
+}
+
+// lock returns the mutex that serialises chunks of one upload.
+func (u *Uploader) lock(id string) *sync.Mutex {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	lock, ok := u.locks[id]
+	if !ok {
+		lock = &sync.Mutex{}
+		u.locks[id] = lock
+	}
+	return lock
+}
+
+// validID reports whether id looks like an ID generated by randomID, which
+// keeps client supplied IDs from reaching the file system.
+func validID(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	for _, r := range id {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// Resumable registers the tus protocol (core, creation and termination
+// extensions) on router:
+//
+//	uploader.Resumable(app.Group("/uploads"))
+//
+// The disk path of the stored file is returned in the Upload-Path header of
+// the request that completes the upload.
+func (u *Uploader) Resumable(router fiber.Router) {
+	router.Options("/", u.tusOptions)
+	router.Post("/", u.tusCreate)
+	router.Head("/:id", u.tusHead)
+	router.Patch("/:id", u.tusPatch)
+	router.Delete("/:id", u.tusDelete)
+}
+
+func (u *Uploader) tusOptions(c *fiber.Ctx) error {
+	c.Set("Tus-Resumable", tusVersion)
+	c.Set("Tus-Version", tusVersion)
+	c.Set("Tus-Extension", "creation,termination")
+	if u.options.MaxSize > 0 {
+		c.Set("Tus-Max-Size", strconv.FormatInt(u.options.MaxSize, 10))
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// tusVersionMismatch rejects requests for another protocol version.
+func tusVersionMismatch(c *fiber.Ctx) bool {
+	c.Set("Tus-Resumable", tusVersion)
+	if c.Get("Tus-Resumable") == tusVersion {
+		return false
+	}
+	c.Set("Tus-Version", tusVersion)
+	c.Status(fiber.StatusPreconditionFailed)
+	return true
+}
+
+func (u *Uploader) tusCreate(c *fiber.Ctx) error {
+	if tusVersionMismatch(c) {
+		return nil
+	}
+	length, err := strconv.ParseInt(c.Get("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		return c.Status(fiber.StatusBadRequest).SendString("invalid Upload-Length header")
+	}
+
+	upload, err := u.Create(parseUploadMetadata(c.Get("Upload-Metadata"))["filename"], length)
+	if err != nil {
+		return sendUploadError(c, err)
+	}
+	c.Location(strings.TrimSuffix(c.BaseURL()+c.Path(), "/") + "/" + upload.ID)
+	return c.SendStatus(fiber.StatusCreated)
+}
+
+func (u *Uploader) tusHead(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	if tusVersionMismatch(c) {
+		return nil
+	}
+	upload, err := u.Status(c.Params("id"))
+	if err != nil {
+		return sendUploadError(c, err)
+	}
+	c.Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	c.Set("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func (u *Uploader) tusPatch(c *fiber.Ctx) error {
+	if tusVersionMismatch(c) {
+		return nil
+	}
+	if c.Get(fiber.HeaderContentType) != "application/offset+octet-stream" {
+		return c.Status(fiber.StatusUnsupportedMediaType).SendString("invalid Content-Type header")
+	}
+	offset, err := strconv.ParseInt(c.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("invalid Upload-Offset header")
+	}
+
+	body := c.Context().RequestBodyStream()
+	if body == nil {
+		body = bytes.NewReader(c.Body())
+	}
+	upload, uploaded, err := u.Append(c.Params("id"), offset, body)
+	if err != nil {
+		return sendUploadError(c, err)
+	}
+	c.Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	if uploaded != nil {
+		c.Set("Upload-Path", uploaded.Path)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (u *Uploader) tusDelete(c *fiber.Ctx) error {
+	if tusVersionMismatch(c) {
+		return nil
+	}
+	if err := u.Cancel(c.Params("id")); err != nil {
+		return sendUploadError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// parseUploadMetadata decodes the Upload-Metadata header, a comma separated
+// list of keys and base64 encoded values.
+func parseUploadMetadata(header string) map[string]string {
+	metadata := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		parts := strings.Fields(pair)
+		if len(parts) == 0 {
+			continue
+		}
+		value := ""
+		if len(parts) > 1 {
+			if decoded, err := base64.StdEncoding.DecodeString(parts[1]); err == nil {
+				value = string(decoded)
+			}
+		}
+		metadata[parts[0]] = value
+	}
+	return metadata
+}
@@ -0,0 +1,320 @@
+package file
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mousav1/weiser/app/storage"
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+var (
+	// ErrFileTooLarge is returned when an upload exceeds UploadOptions.MaxSize.
+	ErrFileTooLarge = errors.New("file exceeds the maximum upload size")
+	// ErrTypeNotAllowed is returned when the sniffed content type is not in UploadOptions.AllowedTypes.
+	ErrTypeNotAllowed = errors.New("file type is not allowed")
+)
+
+// sniffLength is the number of bytes http.DetectContentType looks at.
+const sniffLength = 512
+
+// genericTypes are sniffed types that say little about the file. They are
+// never replaced by the type of the client extension, so an executable named
+// "avatar.png" stays application/octet-stream.
+var genericTypes = map[string]bool{
+	"application/octet-stream": true,
+	"application/zip":          true,
+	"text/plain":               true,
+}
+
+// activeTypes are types a browser may run scripts in. Wildcards such as
+// "image/*" do not match them, they are only allowed when listed by name, and
+// generic content is never stored under their extensions.
+var activeTypes = map[string]bool{
+	"image/svg+xml":          true,
+	"text/html":              true,
+	"text/xml":               true,
+	"text/javascript":        true,
+	"application/xml":        true,
+	"application/xhtml+xml":  true,
+	"application/javascript": true,
+}
+
+// UploadOptions configures an Uploader.
+type UploadOptions struct {
+	// Directory on the disk that uploaded files are stored in.
+	Directory string
+	// MaxSize is the maximum upload size in bytes; zero means unlimited.
+	MaxSize int64
+	// AllowedTypes lists the accepted MIME types, e.g. "image/png" or "image/*",
+	// which are checked against the type sniffed from the content. Wildcards
+	// do not match types that can run scripts, such as image/svg+xml; list
+	// those by name to accept them. An empty list accepts every type.
+	AllowedTypes []string
+	// StagingPath is the local directory that holds incomplete resumable uploads.
+	StagingPath string
+	// Expiration is how long an incomplete resumable upload is kept.
+	Expiration time.Duration
+}
+
+// UploadedFile describes a file stored by an Uploader.
+type UploadedFile struct {
+	// Path of the file on the disk.
+	Path string `json:"path"`
+	// Name is the original client filename.
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	MimeType string `json:"mime_type"`
+}
+
+// Uploader stores uploaded files on a storage disk under generated names,
+// enforcing size and content type limits.
+type Uploader struct {
+	disk    storage.Storage
+	options UploadOptions
+
+	mutex sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// NewUploader creates an Uploader that writes to disk.
+func NewUploader(disk storage.Storage, options UploadOptions) *Uploader {
+	if options.StagingPath == "" {
+		options.StagingPath = "./storage/tmp/uploads"
+	}
+	if options.Expiration <= 0 {
+		options.Expiration = 24 * time.Hour
+	}
+	return &Uploader{disk: disk, options: options, locks: make(map[string]*sync.Mutex)}
+}
+
+// UploaderFromConfig creates an Uploader from the "upload" configuration.
+// An empty upload.disk selects the default storage disk.
+func UploaderFromConfig() (*Uploader, error) {
+	disk := storage.DefaultDriver
+	if name := viper.GetString("upload.disk"); name != "" {
+		var err error
+		if disk, err = storage.SelectDriver(name); err != nil {
+			return nil, fmt.Errorf("failed to create uploader: %w", err)
+		}
+	}
+	if disk == nil {
+		return nil, fmt.Errorf("failed to create uploader: no storage disk is configured")
+	}
+	return NewUploader(disk, UploadOptions{
+		Directory:    viper.GetString("upload.directory"),
+		MaxSize:      cast.ToInt64(viper.Get("upload.max_size")),
+		AllowedTypes: viper.GetStringSlice("upload.allowed_types"),
+		StagingPath:  viper.GetString("upload.staging_path"),
+		Expiration:   viper.GetDuration("upload.expiration"),
+	}), nil
+}
+
+// Store saves a multipart form file.
+func (u *Uploader) Store(header *multipart.FileHeader) (UploadedFile, error) {
+	if u.options.MaxSize > 0 && header.Size > u.options.MaxSize {
+		return UploadedFile{}, ErrFileTooLarge
+	}
+	file, err := header.Open()
+	if err != nil {
+		return UploadedFile{}, err
+	}
+	defer file.Close()
+	return u.Put(header.Filename, file)
+}
+
+// Put saves content under a unique name derived from the client filename.
+// The content type is sniffed from the first bytes of content and checked
+// against the allowed types before anything is written to the disk.
+func (u *Uploader) Put(name string, content io.Reader) (UploadedFile, error) {
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(content, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return UploadedFile{}, err
+	}
+	head = head[:n]
+
+	mimeType, extension := detectType(name, head)
+	if !u.allowed(mimeType) {
+		return UploadedFile{}, fmt.Errorf("%w: %s", ErrTypeNotAllowed, mimeType)
+	}
+
+	filename, err := uniqueName(extension)
+	if err != nil {
+		return UploadedFile{}, err
+	}
+	filePath := path.Join(u.options.Directory, filename)
+
+	reader := &limitedReader{reader: io.MultiReader(bytes.NewReader(head), content), limit: u.options.MaxSize}
+	if err := u.disk.PutStream(filePath, reader); err != nil {
+		if reader.exceeded {
+			return UploadedFile{}, ErrFileTooLarge
+		}
+		return UploadedFile{}, err
+	}
+	return UploadedFile{Path: filePath, Name: name, Size: reader.read, MimeType: mimeType}, nil
+}
+
+// allowed reports whether mimeType matches one of the allowed types.
+func (u *Uploader) allowed(mimeType string) bool {
+	if len(u.options.AllowedTypes) == 0 {
+		return true
+	}
+	for _, allowedType := range u.options.AllowedTypes {
+		if allowedType == mimeType {
+			return true
+		}
+		if strings.HasSuffix(allowedType, "/*") && !activeTypes[mimeType] && strings.HasPrefix(mimeType, strings.TrimSuffix(allowedType, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectType returns the media type sniffed from the content and the
+// extension to store it under. The client extension is kept only when it
+// agrees with the content, so a PNG cannot be stored as "avatar.html". Generic
+// content keeps an extension of the same kind, e.g. ".csv" for text, but never
+// that of an image or of a type a browser runs scripts in.
+func detectType(name string, head []byte) (string, string) {
+	sniffed := sniff(head)
+	extension := strings.ToLower(path.Ext(path.Base(strings.ReplaceAll(name, "\\", "/"))))
+	if !safeExtension(extension) {
+		extension = ""
+	}
+	extensionType := ""
+	if extension != "" {
+		extensionType = mediaType(mime.TypeByExtension(extension))
+	}
+
+	if extensionType == sniffed {
+		return sniffed, extension
+	}
+	if genericTypes[sniffed] && (extensionType == "" || sameKind(sniffed, extensionType) && !activeTypes[extensionType]) {
+		return sniffed, extension
+	}
+	if extensions, _ := mime.ExtensionsByType(sniffed); len(extensions) > 0 {
+		return sniffed, extensions[0]
+	}
+	return sniffed, ""
+}
+
+// sniff returns the media type of the first bytes of a file. SVG images,
+// which http.DetectContentType reports as text, are recognised so that they
+// are only accepted where they are allowed explicitly.
+func sniff(head []byte) string {
+	sniffed := mediaType(http.DetectContentType(head))
+	if (sniffed == "text/plain" || sniffed == "text/xml") && bytes.Contains(bytes.ToLower(head), []byte("<svg")) {
+		return "image/svg+xml"
+	}
+	return sniffed
+}
+
+// sameKind reports whether two media types have the same top-level type.
+func sameKind(a, b string) bool {
+	kind, _, _ := strings.Cut(a, "/")
+	other, _, _ := strings.Cut(b, "/")
+	return kind == other
+}
+
+func mediaType(contentType string) string {
+	if parsed, _, err := mime.ParseMediaType(contentType); err == nil {
+		return parsed
+	}
+	return contentType
+}
+
+// safeExtension reports whether extension is short and only contains letters and digits.
+func safeExtension(extension string) bool {
+	if len(extension) < 2 || len(extension) > 10 {
+		return false
+	}
+	for _, r := range extension[1:] {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// uniqueName returns a random file name with the given extension.
+func uniqueName(extension string) (string, error) {
+	id, err := randomID()
+	if err != nil {
+		return "", err
+	}
+	return id + extension, nil
+}
+
+func randomID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// limitedReader fails with ErrFileTooLarge once more than limit bytes are read.
+// A zero limit means unlimited.
+type limitedReader struct {
+	reader   io.Reader
+	limit    int64
+	read     int64
+	exceeded bool
+}
+
+func (lr *limitedReader) Read(p []byte) (int, error) {
+	n, err := lr.reader.Read(p)
+	lr.read += int64(n)
+	if lr.limit > 0 && lr.read > lr.limit {
+		lr.exceeded = true
+		return n, ErrFileTooLarge
+	}
+	return n, err
+}
+
+// Handler returns a handler that stores the file in the "file" form field
+// and responds with the stored file as JSON.
+func (u *Uploader) Handler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).SendString("failed to upload file")
+		}
+		uploaded, err := u.Store(header)
+		if err != nil {
+			return sendUploadError(c, err)
+		}
+		return c.Status(fiber.StatusCreated).JSON(uploaded)
+	}
+}
+
+// sendUploadError responds with the status code matching an upload error.
+// Unexpected errors are not shown to the client.
+func sendUploadError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, ErrFileTooLarge):
+		return c.Status(fiber.StatusRequestEntityTooLarge).SendString(err.Error())
+	case errors.Is(err, ErrTypeNotAllowed):
+		return c.Status(fiber.StatusUnsupportedMediaType).SendString(err.Error())
+	case errors.Is(err, ErrUploadNotFound):
+		return c.Status(fiber.StatusNotFound).SendString(err.Error())
+	case errors.Is(err, ErrOffsetMismatch):
+		return c.Status(fiber.StatusConflict).SendString(err.Error())
+	default:
+		return c.Status(fiber.StatusInternalServerError).SendString("failed to save file")
+	}
+}
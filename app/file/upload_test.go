@@ -0,0 +1,197 @@
+package file
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mousav1/weiser/app/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// pngHeader is enough of a PNG file for content sniffing.
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestUploader_Put(t *testing.T) {
+	disk := storage.NewMemoryDriver()
+	uploader := NewUploader(disk, UploadOptions{Directory: "avatars"})
+
+	uploaded, err := uploader.Put("../../me.png", bytes.NewReader(pngHeader))
+	assert.NoError(t, err)
+	assert.Equal(t, "avatars", path.Dir(uploaded.Path))
+	assert.Equal(t, ".png", path.Ext(uploaded.Path))
+	assert.Equal(t, "image/png", uploaded.MimeType)
+	assert.Equal(t, int64(len(pngHeader)), uploaded.Size)
+	exists, _ := disk.Exists(uploaded.Path)
+	assert.True(t, exists)
+
+	// نام فایل همیشه یکتا است
+	second, err := uploader.Put("me.png", bytes.NewReader(pngHeader))
+	assert.NoError(t, err)
+	assert.NotEqual(t, uploaded.Path, second.Path)
+
+	// پسوندی که با محتوا سازگار نیست استفاده نمی‌شود
+	uploaded, err = uploader.Put("avatar.html", bytes.NewReader(pngHeader))
+	assert.NoError(t, err)
+	assert.Equal(t, ".png", path.Ext(uploaded.Path))
+
+	// محتوای متنی پسوند متنی خود را نگه می‌دارد ولی نوع آن از روی پسوند تغییر نمی‌کند
+	uploaded, err = uploader.Put("report.csv", strings.NewReader("a,b\n1,2\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "text/plain", uploaded.MimeType)
+	assert.Equal(t, ".csv", path.Ext(uploaded.Path))
+
+	// متنی که پسوند svg دارد با آن پسوند ذخیره نمی‌شود
+	uploaded, err = uploader.Put("logo.svg", strings.NewReader("just text"))
+	assert.NoError(t, err)
+	assert.Equal(t, "text/plain", uploaded.MimeType)
+	assert.NotEqual(t, ".svg", path.Ext(uploaded.Path))
+}
+
+func TestUploader_Limits(t *testing.T) {
+	disk := storage.NewMemoryDriver()
+	uploader := NewUploader(disk, UploadOptions{MaxSize: 1024, AllowedTypes: []string{"image/*"}})
+
+	_, err := uploader.Put("notes.txt", strings.NewReader("hello"))
+	assert.True(t, errors.Is(err, ErrTypeNotAllowed))
+
+	// نوع فایل از محتوا تشخیص داده می‌شود، نه از پسوند
+	_, err = uploader.Put("avatar.png", bytes.NewReader([]byte("\x7fELF\x02\x01\x01\x00\x00\x00")))
+	assert.True(t, errors.Is(err, ErrTypeNotAllowed))
+
+	// SVG می‌تواند اسکریپت اجرا کند و فقط با نام کامل مجاز می‌شود
+	svg := `<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`
+	_, err = uploader.Put("logo.svg", strings.NewReader(svg))
+	assert.True(t, errors.Is(err, ErrTypeNotAllowed))
+
+	large := append(append([]byte{}, pngHeader...), make([]byte, 2048)...)
+	_, err = uploader.Put("large.png", bytes.NewReader(large))
+	assert.True(t, errors.Is(err, ErrFileTooLarge))
+
+	files, err := disk.Files("", true)
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+
+	uploader = NewUploader(disk, UploadOptions{AllowedTypes: []string{"image/svg+xml"}})
+	uploaded, err := uploader.Put("logo.svg", strings.NewReader(svg))
+	assert.NoError(t, err)
+	assert.Equal(t, "image/svg+xml", uploaded.MimeType)
+	assert.Equal(t, ".svg", path.Ext(uploaded.Path))
+}
+
+func TestUploader_Resumable(t *testing.T) {
+	disk := storage.NewMemoryDriver()
+	uploader := NewUploader(disk, UploadOptions{Directory: "videos", StagingPath: t.TempDir()})
+	content := append(append([]byte{}, pngHeader...), []byte("rest of the image")...)
+
+	upload, err := uploader.Create("clip.png", int64(len(content)))
+	assert.NoError(t, err)
+
+	upload, uploaded, err := uploader.Append(upload.ID, 0, bytes.NewReader(content[:10]))
+	assert.NoError(t, err)
+	assert.Nil(t, uploaded)
+	assert.Equal(t, int64(10), upload.Offset)
+
+	_, _, err = uploader.Append(upload.ID, 0, bytes.NewReader(content[:10]))
+	assert.True(t, errors.Is(err, ErrOffsetMismatch))
+
+	upload, uploaded, err = uploader.Append(upload.ID, 10, bytes.NewReader(content[10:]))
+	assert.NoError(t, err)
+	assert.True(t, upload.Complete())
+	if assert.NotNil(t, uploaded) {
+		assert.Equal(t, "image/png", uploaded.MimeType)
+		data, _ := disk.Get(uploaded.Path)
+		stored, _ := io.ReadAll(data)
+		assert.Equal(t, content, stored)
+	}
+
+	// فایل‌های موقت پس از پایان آپلود حذف می‌شوند
+	_, err = uploader.Status(upload.ID)
+	assert.True(t, errors.Is(err, ErrUploadNotFound))
+	_, err = uploader.Status("../../etc/passwd")
+	assert.True(t, errors.Is(err, ErrUploadNotFound))
+}
+
+func TestUploader_ResumableHandlers(t *testing.T) {
+	disk := storage.NewMemoryDriver()
+	uploader := NewUploader(disk, UploadOptions{StagingPath: t.TempDir()})
+	app := fiber.New()
+	uploader.Resumable(app.Group("/uploads"))
+
+	content := append(append([]byte{}, pngHeader...), []byte("data")...)
+
+	req := httptest.NewRequest(fiber.MethodPost, "/uploads", nil)
+	req.Header.Set("Upload-Length", strconv.Itoa(len(content)))
+	req.Header.Set("Upload-Metadata", "filename "+base64.StdEncoding.EncodeToString([]byte("photo.png")))
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusPreconditionFailed, resp.StatusCode)
+
+	req.Header.Set("Tus-Resumable", "1.0.0")
+	resp, err = app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	location := resp.Header.Get("Location")
+	assert.Contains(t, location, "/uploads/")
+	uploadPath := location[strings.Index(location, "/uploads/"):]
+
+	patch := func(offset int, chunk []byte) *http.Response {
+		req := httptest.NewRequest(fiber.MethodPatch, uploadPath, bytes.NewReader(chunk))
+		req.Header.Set("Tus-Resumable", "1.0.0")
+		req.Header.Set("Content-Type", "application/offset+octet-stream")
+		req.Header.Set("Upload-Offset", strconv.Itoa(offset))
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		return resp
+	}
+
+	resp = patch(0, content[:5])
+	assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "5", resp.Header.Get("Upload-Offset"))
+
+	req = httptest.NewRequest(fiber.MethodHead, uploadPath, nil)
+	req.Header.Set("Tus-Resumable", "1.0.0")
+	resp, err = app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "5", resp.Header.Get("Upload-Offset"))
+	assert.Equal(t, strconv.Itoa(len(content)), resp.Header.Get("Upload-Length"))
+
+	assert.Equal(t, fiber.StatusConflict, patch(0, content[:5]).StatusCode)
+
+	resp = patch(5, content[5:])
+	assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	stored := resp.Header.Get("Upload-Path")
+	assert.Equal(t, ".png", path.Ext(stored))
+	exists, _ := disk.Exists(stored)
+	assert.True(t, exists)
+
+	req = httptest.NewRequest(fiber.MethodHead, uploadPath, nil)
+	req.Header.Set("Tus-Resumable", "1.0.0")
+	resp, err = app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestUploader_CleanExpired(t *testing.T) {
+	staging := t.TempDir()
+	uploader := NewUploader(storage.NewMemoryDriver(), UploadOptions{StagingPath: staging})
+	uploader.options.Expiration = 0
+
+	upload, err := uploader.Create("a.txt", 10)
+	assert.NoError(t, err)
+
+	removed, err := uploader.CleanExpired()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	_, err = os.Stat(uploader.stagingFile(upload.ID))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -5,16 +5,22 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	middleware "github.com/mousav1/weiser/app/http/middlewares"
+	"github.com/mousav1/weiser/app/session"
 )
 
 // Define middleware aliases
 var MiddlewareAliases = map[string]func(*fiber.Ctx) error{
-	"logger": middleware.LoggerMiddleware,
+	"logger":          middleware.LoggerMiddleware,
+	"auth":            middleware.Authenticate,
+	"csrf":            middleware.VerifyCSRFToken,
+	"cookies.encrypt": middleware.EncryptCookies(session.CookieName + "*"),
+	"cache.response":  middleware.ResponseCache(middleware.ResponseCacheConfig{VaryByQuery: true, VaryByUser: middleware.SessionUser}),
 }
 
 // Define main middleware functions
 var Middleware = []func(*fiber.Ctx) error{
 	middleware.LoggerMiddleware,
+	middleware.AddQueuedCookies,
 	middleware.SessionMiddleware,
 }
 
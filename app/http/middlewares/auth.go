@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/mousav1/weiser/app/session"
+)
+
+// Authenticate rejects requests whose session has no authenticated user, see
+// SessionManager.Login. It must run after SessionMiddleware.
+func Authenticate(c *fiber.Ctx) error {
+	sessionData, err := session.FromContext(c)
+	if err != nil || sessionData.Get(session.AuthUserKey) == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthenticated",
+		})
+	}
+
+	return c.Next()
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mousav1/weiser/app/file"
+	"github.com/mousav1/weiser/app/session"
+	"github.com/mousav1/weiser/app/storage"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticate_Upload(t *testing.T) {
+	viper.Set("session.type", "memory")
+	viper.Set("session.expirationTime", time.Hour)
+	defer viper.Set("session.type", nil)
+	defer viper.Set("session.expirationTime", nil)
+	assert.NoError(t, session.InitSessionManager())
+
+	uploader := file.NewUploader(storage.NewMemoryDriver(), file.UploadOptions{})
+	app := fiber.New()
+	app.Use(SessionMiddleware)
+	app.Post("/login", func(c *fiber.Ctx) error {
+		sessionData, err := session.GetSessionManager().Login(c, 1)
+		if err != nil {
+			return err
+		}
+		return c.SendString(sessionData.Token())
+	})
+	app.Post("/upload", Authenticate, VerifyCSRFToken, uploader.Handler())
+
+	upload := func(cookies []*http.Cookie, token string) *http.Response {
+		var form bytes.Buffer
+		writer := multipart.NewWriter(&form)
+		part, _ := writer.CreateFormFile("file", "notes.txt")
+		part.Write([]byte("notes"))
+		writer.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/upload", &form)
+		req.Header.Set(fiber.HeaderContentType, writer.FormDataContentType())
+		req.Header.Set("X-CSRF-Token", token)
+		for _, cookie := range cookies {
+			req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+		}
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		return resp
+	}
+
+	// Without a signed in session the upload is rejected
+	assert.Equal(t, fiber.StatusUnauthorized, upload(nil, "").StatusCode)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/login", nil))
+	assert.NoError(t, err)
+	var token bytes.Buffer
+	token.ReadFrom(resp.Body)
+	assert.NotEmpty(t, token.String())
+
+	assert.Equal(t, fiber.StatusForbidden, upload(resp.Cookies(), "wrong").StatusCode)
+	assert.Equal(t, fiber.StatusCreated, upload(resp.Cookies(), token.String()).StatusCode)
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// BodyLimit rejects requests whose Content-Length exceeds limit bytes. A
+// server with StreamRequestBody streams larger bodies instead of rejecting
+// them, so this keeps the limit for handlers that read the whole body.
+// Chunked bodies have no length and are left to the handlers that stream
+// them. A limit of zero disables the check.
+func BodyLimit(limit int) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if limit > 0 && c.Request().Header.ContentLength() > limit {
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+				"error": "Request body too large",
+			})
+		}
+
+		return c.Next()
+	}
+}
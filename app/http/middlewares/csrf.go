@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"crypto/subtle"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mousav1/weiser/app/session"
+)
+
+// VerifyCSRFToken rejects state-changing requests whose X-CSRF-Token header
+// or _token form field does not match the CSRF token of the session. It must
+// run after SessionMiddleware.
+func VerifyCSRFToken(c *fiber.Ctx) error {
+	switch c.Method() {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+		return c.Next()
+	}
+
+	sessionData, err := session.FromContext(c)
+	if err != nil || sessionData.Token() == "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "CSRF token mismatch",
+		})
+	}
+
+	token := c.Get("X-CSRF-Token")
+	if token == "" {
+		token = c.FormValue(session.CSRFTokenKey)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token), []byte(sessionData.Token())) != 1 {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "CSRF token mismatch",
+		})
+	}
+
+	return c.Next()
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mousav1/weiser/app/cookies"
+	"github.com/mousav1/weiser/app/encryption"
+	"github.com/valyala/fasthttp"
+)
+
+// EncryptCookies decrypts the cookies of the request before the handler runs
+// and encrypts the cookies it sets, using the application key. Every value is
+// bound to its cookie name. Cookies that cannot be decrypted are dropped. Cookies named in except are left
+// untouched; a trailing * matches every name with that prefix.
+func EncryptCookies(except ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		encrypter, err := encryption.Default()
+		if err != nil {
+			return err
+		}
+
+		var names []string
+		c.Request().Header.VisitAllCookie(func(key, _ []byte) {
+			names = append(names, string(key))
+		})
+		for _, name := range names {
+			if isCookieExcluded(name, except) {
+				continue
+			}
+			value, err := encrypter.DecryptStringFor(c.Cookies(name), name)
+			if err != nil {
+				c.Request().Header.DelCookie(name)
+				continue
+			}
+			c.Request().Header.SetCookie(name, value)
+		}
+
+		err = c.Next()
+
+		// Queued cookies must be encrypted too.
+		cookies.AttachQueued(c)
+
+		var responseCookies []*fasthttp.Cookie
+		var partitioned []bool
+		c.Response().Header.VisitAllCookie(func(_, value []byte) {
+			cookie := fasthttp.AcquireCookie()
+			if err := cookie.ParseBytes(value); err != nil {
+				fasthttp.ReleaseCookie(cookie)
+				return
+			}
+			responseCookies = append(responseCookies, cookie)
+			partitioned = append(partitioned, bytes.Contains(bytes.ToLower(value), []byte("; partitioned")))
+		})
+		for i, cookie := range responseCookies {
+			// Cookies without a value are being removed from the client.
+			if !isCookieExcluded(string(cookie.Key()), except) && len(cookie.Value()) > 0 {
+				if encrypted, encryptErr := encrypter.EncryptFor(cookie.Value(), string(cookie.Key())); encryptErr == nil {
+					cookie.SetValue(encrypted)
+					cookies.WriteCookie(c, cookie, partitioned[i])
+				}
+			}
+			fasthttp.ReleaseCookie(cookie)
+		}
+
+		return err
+	}
+}
+
+func isCookieExcluded(name string, except []string) bool {
+	for _, pattern := range except {
+		if strings.HasSuffix(pattern, "*") {
+			if strings.HasPrefix(name, strings.TrimSuffix(pattern, "*")) {
+				return true
+			}
+		} else if name == pattern {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mousav1/weiser/app/encryption"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptCookies(t *testing.T) {
+	key, _ := encryption.GenerateKey()
+	parsed, _ := encryption.ParseKey(key)
+	encrypter, _ := encryption.New(parsed)
+	encryption.SetDefault(encrypter)
+	defer encryption.SetDefault(nil)
+
+	app := fiber.New()
+	app.Use(EncryptCookies("plain", "session*"))
+	app.Get("/set", func(c *fiber.Ctx) error {
+		c.Cookie(&fiber.Cookie{Name: "theme", Value: "dark"})
+		c.Cookie(&fiber.Cookie{Name: "plain", Value: "visible"})
+		return nil
+	})
+	app.Get("/get", func(c *fiber.Ctx) error {
+		return c.SendString(c.Cookies("theme") + "," + c.Cookies("plain") + "," + c.Cookies("session_1"))
+	})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/set", nil))
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/get", nil)
+	for _, cookie := range resp.Cookies() {
+		switch cookie.Name {
+		case "theme":
+			assert.NotEqual(t, "dark", cookie.Value)
+		case "plain":
+			assert.Equal(t, "visible", cookie.Value)
+		}
+		req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+	req.AddCookie(&http.Cookie{Name: "session_1", Value: "raw"})
+
+	resp, err = app.Test(req)
+	assert.NoError(t, err)
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(t, "dark,visible,raw", string(body))
+
+	// Cookies that cannot be decrypted are dropped
+	req = httptest.NewRequest(http.MethodGet, "/get", nil)
+	req.AddCookie(&http.Cookie{Name: "theme", Value: "forged"})
+	resp, err = app.Test(req)
+	assert.NoError(t, err)
+	body, _ = ioutil.ReadAll(resp.Body)
+	assert.Equal(t, ",,", string(body))
+}
@@ -0,0 +1,14 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/mousav1/weiser/app/cookies"
+)
+
+// AddQueuedCookies adds the cookies queued with cookies.Queue and
+// cookies.Forget during the request to the response.
+func AddQueuedCookies(c *fiber.Ctx) error {
+	err := c.Next()
+	cookies.AttachQueued(c)
+	return err
+}
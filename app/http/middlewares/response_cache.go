@@ -0,0 +1,293 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mousav1/weiser/app/cache"
+	"github.com/mousav1/weiser/app/session"
+	"github.com/spf13/viper"
+)
+
+const (
+	responseCacheKeyPrefix = "response:"
+	responseCacheTagPrefix = "response:tag:"
+
+	// responseCacheTagTTL is how long a tag index outlives the last
+	// response stored under it.
+	responseCacheTagTTL = 24 * time.Hour
+)
+
+// ResponseCacheConfig configures the ResponseCache middleware.
+type ResponseCacheConfig struct {
+	// Store is the cache store to use. The default store is used when empty.
+	Store string
+	// TTL is how long a response stays cached. Defaults to cache.response.ttl.
+	TTL time.Duration
+	// VaryByQuery caches a separate response for every query string.
+	VaryByQuery bool
+	// VaryByHeaders caches a separate response for every value of these headers.
+	VaryByHeaders []string
+	// VaryByUser returns the authenticated user of the request, see
+	// SessionUser. Responses that vary by user are marked private. Requests
+	// with an Authorization header or a session cookie but no user are not
+	// cached.
+	VaryByUser func(c *fiber.Ctx) string
+	// Tags returns the tags a response is stored under, so it can be purged
+	// with PurgeResponseCache.
+	Tags func(c *fiber.Ctx) []string
+}
+
+// cachedResponse is a full response as stored in the cache.
+type cachedResponse struct {
+	Status   int         `json:"status"`
+	Headers  [][2]string `json:"headers"`
+	Body     []byte      `json:"body"`
+	StoredAt time.Time   `json:"stored_at"`
+}
+
+// tagMutex serializes updates of the tag indexes made by this process.
+var tagMutex sync.Mutex
+
+// ResponseCache caches full GET responses in the configured cache store.
+func ResponseCache(config ResponseCacheConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Method() != fiber.MethodGet || strings.Contains(c.Get(fiber.HeaderCacheControl), "no-cache") {
+			return c.Next()
+		}
+
+		store, err := responseCacheStore(config.Store)
+		if err != nil {
+			return c.Next()
+		}
+
+		ttl := config.TTL
+		if ttl == 0 {
+			ttl = viper.GetDuration("cache.response.ttl")
+		}
+		if ttl <= 0 {
+			ttl = time.Minute
+		}
+
+		user := ""
+		if config.VaryByUser != nil {
+			user = config.VaryByUser(c)
+		}
+		// A response to credentials that identify no user may still be
+		// personal, so it is neither served from nor stored in the cache.
+		if user == "" && hasCredentials(c) {
+			return c.Next()
+		}
+		key := responseCacheKey(c, config, user)
+
+		var cached cachedResponse
+		if err := store.Get(key, &cached); err == nil {
+			return serveCachedResponse(c, cached, ttl, user != "", config.VaryByHeaders)
+		}
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		if !isCacheableResponse(c) {
+			return nil
+		}
+
+		cached = cachedResponse{
+			Status:   c.Response().StatusCode(),
+			Body:     append([]byte(nil), c.Response().Body()...),
+			StoredAt: time.Now(),
+		}
+		c.Response().Header.VisitAll(func(k, v []byte) {
+			switch string(k) {
+			case fiber.HeaderSetCookie, fiber.HeaderDate, fiber.HeaderContentLength, fiber.HeaderAge:
+				return
+			}
+			cached.Headers = append(cached.Headers, [2]string{string(k), string(v)})
+		})
+
+		if err := store.Set(key, cached, ttl); err != nil {
+			return nil
+		}
+		if config.Tags != nil {
+			tagResponse(store, key, config.Tags(c), ttl)
+		}
+
+		setResponseCacheHeaders(c, ttl, 0, user != "", config.VaryByHeaders)
+		c.Set("X-Cache", "MISS")
+		return nil
+	}
+}
+
+// SessionUser returns the authenticated user of the session of the request,
+// for use as ResponseCacheConfig.VaryByUser. Requests without a session
+// cookie have no user and do not start a session.
+func SessionUser(c *fiber.Ctx) string {
+	if c.Cookies(session.CookieName) == "" {
+		return ""
+	}
+
+	sessionData, err := session.FromContext(c)
+	if err != nil {
+		return ""
+	}
+	if user := sessionData.Get(session.AuthUserKey); user != nil {
+		return fmt.Sprint(user)
+	}
+	return ""
+}
+
+// hasCredentials reports whether the request carries an Authorization header
+// or a session cookie.
+func hasCredentials(c *fiber.Ctx) bool {
+	return c.Get(fiber.HeaderAuthorization) != "" || c.Cookies(session.CookieName) != ""
+}
+
+// PurgeResponseCache removes every cached response stored under the given
+// tags from the named store, or from the default store if name is empty.
+func PurgeResponseCache(name string, tags ...string) error {
+	store, err := responseCacheStore(name)
+	if err != nil {
+		return err
+	}
+
+	tagMutex.Lock()
+	defer tagMutex.Unlock()
+
+	for _, tag := range tags {
+		var keys []string
+		if err := store.Get(responseCacheTagPrefix+tag, &keys); err != nil {
+			if err == cache.ErrCacheMiss {
+				continue
+			}
+			return err
+		}
+
+		for _, key := range keys {
+			if err := store.Delete(key); err != nil && err != cache.ErrCacheMiss {
+				return err
+			}
+		}
+		if err := store.Delete(responseCacheTagPrefix + tag); err != nil && err != cache.ErrCacheMiss {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func responseCacheStore(name string) (*cache.Cache, error) {
+	if name != "" {
+		return cache.Store(name)
+	}
+
+	store := cache.GetCacheInstance()
+	if store == nil {
+		return nil, fmt.Errorf("cache is not initialized")
+	}
+	return store, nil
+}
+
+// responseCacheKey builds the cache key from the path and the vary-by rules.
+func responseCacheKey(c *fiber.Ctx, config ResponseCacheConfig, user string) string {
+	h := sha256.New()
+	h.Write([]byte(c.Path()))
+
+	if config.VaryByQuery {
+		args := c.Request().URI().QueryArgs()
+		keys := make([]string, 0, args.Len())
+		args.VisitAll(func(k, v []byte) {
+			keys = append(keys, string(k)+"="+string(v))
+		})
+		sort.Strings(keys)
+		h.Write([]byte("?" + strings.Join(keys, "&")))
+	}
+
+	for _, header := range config.VaryByHeaders {
+		h.Write([]byte("\n" + strings.ToLower(header) + ":" + c.Get(header)))
+	}
+
+	if user != "" {
+		h.Write([]byte("\nuser:" + user))
+	}
+
+	return responseCacheKeyPrefix + hex.EncodeToString(h.Sum(nil))
+}
+
+// isCacheableResponse reports whether the handler's response may be stored.
+func isCacheableResponse(c *fiber.Ctx) bool {
+	if c.Response().StatusCode() != fiber.StatusOK {
+		return false
+	}
+	if len(c.Response().Header.Peek(fiber.HeaderSetCookie)) > 0 {
+		return false
+	}
+
+	cacheControl := strings.ToLower(string(c.Response().Header.Peek(fiber.HeaderCacheControl)))
+	return !strings.Contains(cacheControl, "no-store") && !strings.Contains(cacheControl, "private")
+}
+
+func serveCachedResponse(c *fiber.Ctx, cached cachedResponse, ttl time.Duration, private bool, varyHeaders []string) error {
+	for _, header := range cached.Headers {
+		c.Response().Header.Add(header[0], header[1])
+	}
+
+	age := time.Since(cached.StoredAt)
+	if age < 0 {
+		age = 0
+	}
+	setResponseCacheHeaders(c, ttl, age, private, varyHeaders)
+	c.Set("X-Cache", "HIT")
+
+	return c.Status(cached.Status).Send(cached.Body)
+}
+
+func setResponseCacheHeaders(c *fiber.Ctx, ttl, age time.Duration, private bool, varyHeaders []string) {
+	scope := "public"
+	if private {
+		scope = "private"
+	}
+
+	maxAge := int((ttl - age).Seconds())
+	if maxAge < 0 {
+		maxAge = 0
+	}
+	c.Set(fiber.HeaderCacheControl, fmt.Sprintf("%s, max-age=%d", scope, maxAge))
+	c.Set(fiber.HeaderAge, fmt.Sprintf("%d", int(age.Seconds())))
+
+	for _, header := range varyHeaders {
+		c.Vary(header)
+	}
+}
+
+// tagResponse records key in the index of every tag.
+func tagResponse(store *cache.Cache, key string, tags []string, ttl time.Duration) {
+	tagMutex.Lock()
+	defer tagMutex.Unlock()
+
+	for _, tag := range tags {
+		var keys []string
+		if err := store.Get(responseCacheTagPrefix+tag, &keys); err != nil && err != cache.ErrCacheMiss {
+			continue
+		}
+
+		found := false
+		for _, existing := range keys {
+			if existing == key {
+				found = true
+				break
+			}
+		}
+		if !found {
+			keys = append(keys, key)
+		}
+
+		store.Set(responseCacheTagPrefix+tag, keys, responseCacheTagTTL+ttl)
+	}
+}
@@ -0,0 +1,183 @@
+package middleware
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mousav1/weiser/app/cache"
+	"github.com/mousav1/weiser/app/session"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupResponseCache(t *testing.T, config ResponseCacheConfig) (*fiber.App, *int) {
+	cache.RegisterStore("responses", cache.NewCache(cache.NewInMemoryCache(nil), time.Minute))
+	t.Cleanup(cache.Reset)
+
+	config.Store = "responses"
+	calls := 0
+	app := fiber.New()
+	app.Use(ResponseCache(config))
+	app.All("/items", func(c *fiber.Ctx) error {
+		calls++
+		c.Set("X-Handler", "items")
+		return c.SendString("items " + c.Query("page"))
+	})
+	return app, &calls
+}
+
+func doRequest(t *testing.T, app *fiber.App, method, target string) (*http.Response, string) {
+	resp, err := app.Test(httptest.NewRequest(method, target, nil))
+	assert.NoError(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	return resp, string(body)
+}
+
+func TestResponseCache_ServesCachedResponse(t *testing.T) {
+	app, calls := setupResponseCache(t, ResponseCacheConfig{TTL: time.Minute, VaryByQuery: true})
+
+	resp, body := doRequest(t, app, http.MethodGet, "/items?page=1")
+	assert.Equal(t, "MISS", resp.Header.Get("X-Cache"))
+	assert.Equal(t, "public, max-age=60", resp.Header.Get("Cache-Control"))
+	assert.Equal(t, "items 1", body)
+
+	resp, body = doRequest(t, app, http.MethodGet, "/items?page=1")
+	assert.Equal(t, "HIT", resp.Header.Get("X-Cache"))
+	assert.Equal(t, "items", resp.Header.Get("X-Handler"))
+	assert.Equal(t, "0", resp.Header.Get("Age"))
+	assert.Equal(t, "items 1", body)
+	assert.Equal(t, 1, *calls)
+
+	_, body = doRequest(t, app, http.MethodGet, "/items?page=2")
+	assert.Equal(t, "items 2", body)
+	assert.Equal(t, 2, *calls)
+}
+
+func TestResponseCache_BypassesNonGet(t *testing.T) {
+	app, calls := setupResponseCache(t, ResponseCacheConfig{TTL: time.Minute})
+
+	doRequest(t, app, http.MethodPost, "/items")
+	resp, _ := doRequest(t, app, http.MethodPost, "/items")
+	assert.Empty(t, resp.Header.Get("X-Cache"))
+	assert.Equal(t, 2, *calls)
+}
+
+func TestResponseCache_PurgeByTag(t *testing.T) {
+	app, calls := setupResponseCache(t, ResponseCacheConfig{
+		TTL:  time.Minute,
+		Tags: func(c *fiber.Ctx) []string { return []string{"items"} },
+	})
+
+	doRequest(t, app, http.MethodGet, "/items")
+	doRequest(t, app, http.MethodGet, "/items")
+	assert.Equal(t, 1, *calls)
+
+	assert.NoError(t, PurgeResponseCache("responses", "items"))
+
+	resp, _ := doRequest(t, app, http.MethodGet, "/items")
+	assert.Equal(t, "MISS", resp.Header.Get("X-Cache"))
+	assert.Equal(t, 2, *calls)
+}
+
+func TestResponseCache_VaryByUser(t *testing.T) {
+	app, calls := setupResponseCache(t, ResponseCacheConfig{
+		TTL:        time.Minute,
+		VaryByUser: func(c *fiber.Ctx) string { return c.Get("X-User") },
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	req.Header.Set("X-User", "1")
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "private, max-age=60", resp.Header.Get("Cache-Control"))
+
+	req = httptest.NewRequest(http.MethodGet, "/items", nil)
+	req.Header.Set("X-User", "2")
+	_, err = app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, *calls)
+}
+
+func TestResponseCache_BypassesCredentials(t *testing.T) {
+	app, calls := setupResponseCache(t, ResponseCacheConfig{TTL: time.Minute})
+
+	doRequest(t, app, http.MethodGet, "/items")
+	assert.Equal(t, 1, *calls)
+
+	// Requests with credentials are neither served from nor stored in the cache
+	for _, header := range [][2]string{
+		{fiber.HeaderAuthorization, "Bearer token"},
+		{fiber.HeaderCookie, session.CookieName + "=id"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/items", nil)
+		req.Header.Set(header[0], header[1])
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Empty(t, resp.Header.Get("X-Cache"))
+	}
+	assert.Equal(t, 3, *calls)
+}
+
+func TestResponseCache_SessionUser(t *testing.T) {
+	viper.Set("session.type", "memory")
+	viper.Set("session.expirationTime", time.Hour)
+	defer viper.Set("session.type", nil)
+	defer viper.Set("session.expirationTime", nil)
+	assert.NoError(t, session.InitSessionManager())
+
+	cache.RegisterStore("responses", cache.NewCache(cache.NewInMemoryCache(nil), time.Minute))
+	t.Cleanup(cache.Reset)
+
+	app := fiber.New()
+	app.Use(SessionMiddleware)
+	app.Post("/login/:id", func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return err
+		}
+		_, err = session.GetSessionManager().Login(c, id)
+		return err
+	})
+	app.Get("/items", ResponseCache(ResponseCacheConfig{Store: "responses", TTL: time.Minute, VaryByUser: SessionUser}), func(c *fiber.Ctx) error {
+		sessionData, _ := session.FromContext(c)
+		return c.SendString(fmt.Sprint("items ", sessionData.Get(session.AuthUserKey)))
+	})
+
+	login := func(id string) []*http.Cookie {
+		resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/login/"+id, nil))
+		assert.NoError(t, err)
+		return resp.Cookies()
+	}
+	get := func(cookies []*http.Cookie) (*http.Response, string) {
+		req := httptest.NewRequest(http.MethodGet, "/items", nil)
+		for _, cookie := range cookies {
+			req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+		}
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		body, _ := ioutil.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	first, second := login("1"), login("2")
+
+	resp, body := get(first)
+	assert.Equal(t, "MISS", resp.Header.Get("X-Cache"))
+	assert.Equal(t, "private, max-age=60", resp.Header.Get("Cache-Control"))
+	assert.Equal(t, "items 1", body)
+
+	resp, body = get(first)
+	assert.Equal(t, "HIT", resp.Header.Get("X-Cache"))
+	assert.Equal(t, "items 1", body)
+
+	// Another user does not get the first user's response
+	resp, body = get(second)
+	assert.Equal(t, "MISS", resp.Header.Get("X-Cache"))
+	assert.Equal(t, "items 2", body)
+}
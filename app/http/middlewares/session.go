@@ -1,37 +1,18 @@
 package middleware
 
 import (
+	"log"
+
 	"github.com/gofiber/fiber/v2"
-	"github.com/mousav1/weiser/app/cookies"
 	"github.com/mousav1/weiser/app/session"
 )
 
+// SessionMiddleware writes the session of the request back to storage once
+// the handler returns. The session itself is only loaded when the request
+// uses it through session.FromContext, so requests that never touch the
+// session do not touch the storage either.
 func SessionMiddleware(c *fiber.Ctx) error {
 	manager := session.GetSessionManager()
-	sessionID, err := cookies.GetCookie(c, "weiser_session")
-	if err != nil {
-		// Start a new session if no session ID is present
-		sess := manager.StartSession(c)
-		sessionID = sess.ID
-	}
-
-	// Check if the session is valid
-	if err := manager.CheckExpiration(sessionID); err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": err.Error(),
-		})
-	}
-
-	// Retrieve session data
-	data, err := manager.GetDataBySessionID(sessionID)
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": err.Error(),
-		})
-	}
-
-	// Store session data in the context
-	c.Locals("sessionData", data)
 
 	if !isAuthorized(c) {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
@@ -39,7 +20,15 @@ func SessionMiddleware(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.Next()
+	err := c.Next()
+
+	// Drop the flash data of the previous request, keep this request's
+	// flash data for the next one and store the session if it changed.
+	if commitErr := manager.Commit(c); commitErr != nil {
+		log.Println("Failed to save session:", commitErr)
+	}
+
+	return err
 }
 
 func isAuthorized(c *fiber.Ctx) bool {
@@ -20,6 +20,10 @@ func StartSessionCleaner() {
 }
 
 func cleanExpiredSessions(manager *session.SessionManager) error {
+	if pruned, err := manager.Prune(); pruned {
+		return err
+	}
+
 	sessionIDs, err := manager.GetSessionIDs()
 	if err != nil {
 		return err
@@ -0,0 +1,365 @@
+package request
+
+import (
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ErrUnsupportedMediaType is returned by Bind for request bodies it cannot decode.
+var ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+// FieldError describes a request value that could not be converted to the
+// type of its field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Source  string `json:"source"`
+	Message string `json:"error"`
+}
+
+// BindError is returned by Bind when request values have the wrong type.
+type BindError struct {
+	Errors []FieldError
+}
+
+func (e *BindError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldError := range e.Errors {
+		messages = append(messages, fmt.Sprintf("%s %s: %s", fieldError.Source, fieldError.Field, fieldError.Message))
+	}
+	return "invalid request input: " + strings.Join(messages, "; ")
+}
+
+// errUnsupportedType is reported for fields whose type cannot be bound.
+var errUnsupportedType = errors.New("unsupported field type")
+
+// sources are the tags of the values bound after the body.
+var sources = []string{"query", "param", "header"}
+
+var (
+	fileHeaderType  = reflect.TypeOf(&multipart.FileHeader{})
+	fileHeadersType = reflect.TypeOf([]*multipart.FileHeader{})
+	durationType    = reflect.TypeOf(time.Duration(0))
+	textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// bind fills data, a pointer to a struct, from the request. The body is
+// decoded by its media type: JSON and XML use the json and xml tags, forms
+// use the form tag (falling back to the json tag and the field name) and
+// multipart files use the file tag. Fields tagged with query, param or
+// header are then set from the query string, route parameters and headers.
+func (r *Request) bind(data interface{}) error {
+	target := reflect.ValueOf(data)
+	if target.Kind() != reflect.Pointer || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind target must be a non-nil pointer to a struct, got %T", data)
+	}
+	target = target.Elem()
+
+	// The other sources are bound even when the body is invalid, so that
+	// Validated can authorize the request before reporting the error.
+	var fieldErrors []FieldError
+	bodyErr := r.bindBody(data, target, &fieldErrors)
+	for _, source := range sources {
+		bindValues(target, source, r.lookup(source), &fieldErrors)
+	}
+
+	if bodyErr != nil {
+		return bodyErr
+	}
+	if len(fieldErrors) > 0 {
+		return &BindError{Errors: fieldErrors}
+	}
+	return nil
+}
+
+// bindBody decodes the request body into data according to its Content-Type.
+// Requests without a body are left alone.
+func (r *Request) bindBody(data interface{}, target reflect.Value, fieldErrors *[]FieldError) error {
+	body := r.ctx.Body()
+	contentType := r.ctx.Get(fiber.HeaderContentType)
+	if len(body) == 0 || contentType == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrUnsupportedMediaType, contentType)
+	}
+
+	switch {
+	case mediaType == fiber.MIMEApplicationJSON || strings.HasSuffix(mediaType, "+json"):
+		return bindJSON(body, data, target.Type(), fieldErrors)
+	case mediaType == fiber.MIMEApplicationXML || mediaType == fiber.MIMETextXML || strings.HasSuffix(mediaType, "+xml"):
+		if err := xml.Unmarshal(body, data); err != nil {
+			return fmt.Errorf("failed to parse XML body: %w", err)
+		}
+	case mediaType == fiber.MIMEApplicationForm:
+		values := make(map[string][]string)
+		r.ctx.Request().PostArgs().VisitAll(func(key, value []byte) {
+			values[string(key)] = append(values[string(key)], string(value))
+		})
+		bindValues(target, "form", func(key string) []string { return values[key] }, fieldErrors)
+	case mediaType == fiber.MIMEMultipartForm:
+		form, err := r.ctx.MultipartForm()
+		if err != nil {
+			return fmt.Errorf("failed to parse multipart form: %w", err)
+		}
+		bindValues(target, "form", func(key string) []string { return form.Value[key] }, fieldErrors)
+		bindFiles(target, form.File, fieldErrors)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedMediaType, mediaType)
+	}
+	return nil
+}
+
+// bindJSON decodes a JSON body into data, whose struct type is typ.
+// json.Unmarshal sets every field it can but only reports the first value of
+// the wrong type, so on such an error every member of the body is decoded
+// again on its own into a new value of typ to report all of them.
+func bindJSON(body []byte, data interface{}, typ reflect.Type, fieldErrors *[]FieldError) error {
+	err := json.Unmarshal(body, data)
+	if err == nil {
+		return nil
+	}
+	var typeError *json.UnmarshalTypeError
+	if !errors.As(err, &typeError) {
+		return fmt.Errorf("failed to parse JSON body: %w", err)
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(body, &members); err != nil {
+		// The body is not an object, e.g. an array.
+		*fieldErrors = append(*fieldErrors, jsonFieldError(typeError))
+		return nil
+	}
+	keys := make([]string, 0, len(members))
+	for key := range members {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		member, err := json.Marshal(map[string]json.RawMessage{key: members[key]})
+		if err != nil {
+			return fmt.Errorf("failed to parse JSON body: %w", err)
+		}
+		if err := json.Unmarshal(member, reflect.New(typ).Interface()); errors.As(err, &typeError) {
+			*fieldErrors = append(*fieldErrors, jsonFieldError(typeError))
+		}
+	}
+	return nil
+}
+
+func jsonFieldError(typeError *json.UnmarshalTypeError) FieldError {
+	return FieldError{
+		Field:   typeError.Field,
+		Source:  "json",
+		Message: fmt.Sprintf("expected %s but got %s", typeError.Type, typeError.Value),
+	}
+}
+
+// lookup returns a function that reads the values of a key from a source.
+func (r *Request) lookup(source string) func(key string) []string {
+	switch source {
+	case "query":
+		return func(key string) []string {
+			return byteStrings(r.ctx.Request().URI().QueryArgs().PeekMulti(key))
+		}
+	case "param":
+		return func(key string) []string {
+			if value := r.ctx.Params(key); value != "" {
+				return []string{value}
+			}
+			return nil
+		}
+	default:
+		return func(key string) []string {
+			return byteStrings(r.ctx.Request().Header.PeekAll(key))
+		}
+	}
+}
+
+func byteStrings(values [][]byte) []string {
+	result := make([]string, len(values))
+	for i, value := range values {
+		result[i] = string(value)
+	}
+	return result
+}
+
+// eachField calls fn for every field of v that is bound from source, with
+// the name the field has in that source. Embedded and untagged nested
+// structs are walked recursively.
+func eachField(v reflect.Value, source string, fn func(name string, field reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get(source), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" && isNestedStruct(field.Type) {
+			eachField(v.Field(i), source, fn)
+			continue
+		}
+		if name == "" && source == "form" && !hasSourceTag(field) {
+			// Forms historically bound to the JSON names of the fields.
+			name, _, _ = strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+		}
+		if name != "" {
+			fn(name, v.Field(i))
+		}
+	}
+}
+
+// hasSourceTag reports whether a field is bound from a source other than the form values.
+func hasSourceTag(field reflect.StructField) bool {
+	for _, source := range sources {
+		if _, ok := field.Tag.Lookup(source); ok {
+			return true
+		}
+	}
+	_, ok := field.Tag.Lookup("file")
+	return ok
+}
+
+// isNestedStruct reports whether fields of type t are structs whose own
+// fields are bound, rather than values such as time.Time.
+func isNestedStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !reflect.PointerTo(t).Implements(textUnmarshaler)
+}
+
+// bindValues sets the fields of v bound from source to the values returned by lookup.
+func bindValues(v reflect.Value, source string, lookup func(key string) []string, fieldErrors *[]FieldError) {
+	eachField(v, source, func(name string, field reflect.Value) {
+		values := lookup(name)
+		if len(values) == 0 {
+			return
+		}
+		if err := setValue(field, values); err != nil {
+			*fieldErrors = append(*fieldErrors, FieldError{Field: name, Source: source, Message: err.Error()})
+		}
+	})
+}
+
+// bindFiles sets the *multipart.FileHeader and []*multipart.FileHeader fields tagged with file.
+func bindFiles(v reflect.Value, files map[string][]*multipart.FileHeader, fieldErrors *[]FieldError) {
+	eachField(v, "file", func(name string, field reflect.Value) {
+		headers := files[name]
+		switch {
+		case len(headers) == 0:
+		case field.Type() == fileHeaderType:
+			field.Set(reflect.ValueOf(headers[0]))
+		case field.Type() == fileHeadersType:
+			field.Set(reflect.ValueOf(headers))
+		default:
+			*fieldErrors = append(*fieldErrors, FieldError{Field: name, Source: "file", Message: errUnsupportedType.Error() + " " + field.Type().String()})
+		}
+	})
+}
+
+// setValue converts values to the type of field. Slices receive every
+// value, other types the first one.
+func setValue(field reflect.Value, values []string) error {
+	if field.Kind() == reflect.Pointer {
+		element := reflect.New(field.Type().Elem())
+		if err := setValue(element.Elem(), values); err != nil {
+			return err
+		}
+		field.Set(element)
+		return nil
+	}
+	if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if err := unmarshaler.UnmarshalText([]byte(values[0])); err != nil {
+			return fmt.Errorf("%q is not a valid %s", values[0], field.Type())
+		}
+		return nil
+	}
+	if field.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setValue(slice.Index(i), []string{value}); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+	return setScalar(field, values[0])
+}
+
+// setScalar converts a single value to the kind of field. Empty values
+// leave non-string fields unchanged, as browsers send empty form inputs.
+func setScalar(field reflect.Value, value string) error {
+	if field.Kind() == reflect.String {
+		field.SetString(value)
+		return nil
+	}
+	if value == "" {
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.Bool:
+		switch strings.ToLower(value) {
+		case "on":
+			field.SetBool(true)
+		case "off":
+			field.SetBool(false)
+		default:
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("%q is not a valid boolean", value)
+			}
+			field.SetBool(parsed)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if field.Type() == durationType {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("%q is not a valid duration", value)
+			}
+			field.SetInt(int64(parsed))
+			return nil
+		}
+		parsed, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a valid integer", value)
+		}
+		field.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a valid unsigned integer", value)
+		}
+		field.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a valid number", value)
+		}
+		field.SetFloat(parsed)
+	default:
+		return fmt.Errorf("%w %s", errUnsupportedType, field.Type())
+	}
+	return nil
+}
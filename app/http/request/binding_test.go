@@ -0,0 +1,149 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type listInput struct {
+	Page    int           `query:"page"`
+	Tags    []string      `query:"tag"`
+	Since   *time.Time    `query:"since"`
+	Timeout time.Duration `query:"timeout"`
+	ID      uint          `param:"id"`
+	Token   string        `header:"X-Token"`
+}
+
+type profileInput struct {
+	Name   string                  `json:"name" xml:"name" form:"name" validate:"required"`
+	Age    int                     `json:"age" xml:"age"`
+	Active bool                    `form:"active"`
+	Avatar *multipart.FileHeader   `file:"avatar"`
+	Photos []*multipart.FileHeader `file:"photos"`
+}
+
+// bindRequest binds target from req on the route "/users/:id" and returns the error of Bind.
+func bindRequest(t *testing.T, req *http.Request, target interface{}) error {
+	var bindErr error
+	app := fiber.New()
+	app.All("/users/:id", func(c *fiber.Ctx) error {
+		r, err := New(c)
+		assert.NoError(t, err)
+		bindErr = r.Bind(target)
+		return nil
+	})
+	_, err := app.Test(req)
+	assert.NoError(t, err)
+	return bindErr
+}
+
+func TestBind_QueryParamsAndHeaders(t *testing.T) {
+	req := httptest.NewRequest(fiber.MethodGet, "/users/42?page=3&tag=a&tag=b&since=2024-01-02T03:04:05Z&timeout=1m", nil)
+	req.Header.Set("X-Token", "secret")
+
+	var input listInput
+	assert.NoError(t, bindRequest(t, req, &input))
+	assert.Equal(t, 3, input.Page)
+	assert.Equal(t, []string{"a", "b"}, input.Tags)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), *input.Since)
+	assert.Equal(t, time.Minute, input.Timeout)
+	assert.Equal(t, uint(42), input.ID)
+	assert.Equal(t, "secret", input.Token)
+
+	req = httptest.NewRequest(fiber.MethodGet, "/users/abc?page=two&since=yesterday", nil)
+	err := bindRequest(t, req, &listInput{})
+	var bindErr *BindError
+	assert.True(t, errors.As(err, &bindErr))
+	assert.ElementsMatch(t, []FieldError{
+		{Field: "page", Source: "query", Message: `"two" is not a valid integer`},
+		{Field: "since", Source: "query", Message: `"yesterday" is not a valid time.Time`},
+		{Field: "id", Source: "param", Message: `"abc" is not a valid unsigned integer`},
+	}, bindErr.Errors)
+}
+
+func TestBind_Body(t *testing.T) {
+	req := httptest.NewRequest(fiber.MethodPost, "/users/1", strings.NewReader(`{"name":"Sara","age":30}`))
+	req.Header.Set(fiber.HeaderContentType, "application/json; charset=utf-8")
+	var input profileInput
+	assert.NoError(t, bindRequest(t, req, &input))
+	assert.Equal(t, "Sara", input.Name)
+	assert.Equal(t, 30, input.Age)
+
+	req = httptest.NewRequest(fiber.MethodPost, "/users/1", strings.NewReader(`<profile><name>Sara</name><age>31</age></profile>`))
+	req.Header.Set(fiber.HeaderContentType, "application/xml")
+	input = profileInput{}
+	assert.NoError(t, bindRequest(t, req, &input))
+	assert.Equal(t, 31, input.Age)
+
+	// فیلدهای بدون تگ form با نام json خود پر می‌شوند
+	req = httptest.NewRequest(fiber.MethodPost, "/users/1", strings.NewReader("name=Sara&age=32&active=on"))
+	req.Header.Set(fiber.HeaderContentType, "application/x-www-form-urlencoded")
+	input = profileInput{}
+	assert.NoError(t, bindRequest(t, req, &input))
+	assert.Equal(t, profileInput{Name: "Sara", Age: 32, Active: true}, input)
+
+	req = httptest.NewRequest(fiber.MethodPost, "/users/1", strings.NewReader(`{"name":"Sara","age":"old"}`))
+	req.Header.Set(fiber.HeaderContentType, "application/json")
+	err := bindRequest(t, req, &profileInput{})
+	var bindErr *BindError
+	assert.True(t, errors.As(err, &bindErr))
+	assert.Equal(t, []FieldError{{Field: "age", Source: "json", Message: "expected int but got string"}}, bindErr.Errors)
+
+	// همه مقادیر با نوع اشتباه گزارش می‌شوند، نه فقط اولی
+	req = httptest.NewRequest(fiber.MethodPost, "/users/1", strings.NewReader(`{"name":5,"age":"old"}`))
+	req.Header.Set(fiber.HeaderContentType, "application/json")
+	err = bindRequest(t, req, &profileInput{})
+	assert.True(t, errors.As(err, &bindErr))
+	assert.Equal(t, []FieldError{
+		{Field: "age", Source: "json", Message: "expected int but got string"},
+		{Field: "name", Source: "json", Message: "expected string but got number"},
+	}, bindErr.Errors)
+
+	req = httptest.NewRequest(fiber.MethodPost, "/users/1", strings.NewReader("name: Sara"))
+	req.Header.Set(fiber.HeaderContentType, "text/yaml")
+	assert.ErrorIs(t, bindRequest(t, req, &profileInput{}), ErrUnsupportedMediaType)
+}
+
+func TestBind_Multipart(t *testing.T) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	assert.NoError(t, writer.WriteField("name", "Sara"))
+	for _, name := range []string{"avatar", "photos", "photos"} {
+		part, err := writer.CreateFormFile(name, name+".png")
+		assert.NoError(t, err)
+		io.WriteString(part, "png")
+	}
+	assert.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(fiber.MethodPost, "/users/1", &body)
+	req.Header.Set(fiber.HeaderContentType, writer.FormDataContentType())
+	var input profileInput
+	assert.NoError(t, bindRequest(t, req, &input))
+	assert.Equal(t, "Sara", input.Name)
+	assert.Equal(t, "avatar.png", input.Avatar.Filename)
+	assert.Len(t, input.Photos, 2)
+}
+
+func TestBind_Validation(t *testing.T) {
+	req := httptest.NewRequest(fiber.MethodPost, "/users/1", strings.NewReader(`{"age":30}`))
+	req.Header.Set(fiber.HeaderContentType, "application/json")
+	err := bindRequest(t, req, &profileInput{})
+
+	var fiberErr *fiber.Error
+	assert.True(t, errors.As(err, &fiberErr))
+	assert.Equal(t, fiber.StatusBadRequest, fiberErr.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(fiberErr.Message), &response))
+	assert.Contains(t, response["errors"], "name")
+}
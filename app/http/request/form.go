@@ -0,0 +1,132 @@
+package request
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mousav1/weiser/app/http/validation"
+	"github.com/mousav1/weiser/app/session"
+)
+
+// FormRequest is a struct that describes the input of an action together
+// with its authorization and validation. Embed BaseFormRequest to get the
+// defaults of the methods a request does not need.
+type FormRequest interface {
+	// Authorize reports whether the user may make the request.
+	Authorize(c *fiber.Ctx) bool
+	// Prepare normalises the bound input before it is validated.
+	Prepare()
+	// Rules returns rules such as "required|email" keyed by field name; they override the validate tags.
+	Rules() map[string]string
+	// Messages returns custom error messages keyed by "field.rule" or "rule".
+	Messages() map[string]string
+	// Attributes returns the display names of fields used in error messages.
+	Attributes() map[string]string
+}
+
+// BaseFormRequest authorizes every request and adds no rules, messages or attributes.
+type BaseFormRequest struct{}
+
+func (BaseFormRequest) Authorize(c *fiber.Ctx) bool { return true }
+
+func (BaseFormRequest) Prepare() {}
+
+func (BaseFormRequest) Rules() map[string]string { return nil }
+
+func (BaseFormRequest) Messages() map[string]string { return nil }
+
+func (BaseFormRequest) Attributes() map[string]string { return nil }
+
+// FormError is returned by Validated when a request is not authorized or its
+// input is invalid. ErrorHandler renders it as a JSON response.
+type FormError struct {
+	Code     int
+	Response *validation.ErrorResponse
+}
+
+func (e *FormError) Error() string {
+	return e.Response.Message
+}
+
+// Validated binds a form request of type T from the request, authorizes it,
+// prepares it and validates it:
+//
+//	input, err := request.Validated[requests.CreateUser](c)
+//	if err != nil {
+//		return err
+//	}
+//
+// Unauthorized requests fail with a 403 and invalid input with a 422
+// *FormError that lists the errors of every field.
+func Validated[T any, PT interface {
+	*T
+	FormRequest
+}](c *fiber.Ctx) (*T, error) {
+	r, err := New(c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Authorize runs before binding errors are reported, so that
+	// unauthorized callers learn nothing about the expected input.
+	form := PT(new(T))
+	bindErr := r.bind(form)
+	if !form.Authorize(c) {
+		return nil, &FormError{
+			Code:     fiber.StatusForbidden,
+			Response: &validation.ErrorResponse{Message: "This action is unauthorized."},
+		}
+	}
+	if bindErr != nil {
+		return nil, bindFailure(bindErr)
+	}
+	form.Prepare()
+
+	if err := r.validate.SetRules(form, form.Rules()); err != nil {
+		return nil, err
+	}
+	r.validate.SetMessages(form.Messages())
+	r.validate.SetAttributes(form.Attributes())
+	validationErrors, err := r.validate.Validate(form)
+	if len(validationErrors) > 0 {
+		response := r.validate.CreateErrorResponse(validationErrors)
+		// Let server-rendered forms re-fill their fields after a redirect.
+		if !r.IsJson() {
+			r.FlashInput()
+			r.Flash(session.FlashErrorKey, response.Errors)
+		}
+		return nil, &FormError{Code: fiber.StatusUnprocessableEntity, Response: response}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return (*T)(form), nil
+}
+
+// bindFailure converts an error of bind into the error returned by Validated.
+// Values of the wrong type are reported like failed rules.
+func bindFailure(err error) error {
+	var bindError *BindError
+	switch {
+	case errors.As(err, &bindError):
+		response := &validation.ErrorResponse{Message: "The given data was invalid.", Errors: make(map[string][]string)}
+		for _, fieldError := range bindError.Errors {
+			response.Errors[fieldError.Field] = append(response.Errors[fieldError.Field], fieldError.Message)
+		}
+		return &FormError{Code: fiber.StatusUnprocessableEntity, Response: response}
+	case errors.Is(err, ErrUnsupportedMediaType):
+		return fiber.NewError(fiber.StatusUnsupportedMediaType, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+}
+
+// ErrorHandler is the error handler of the application. It renders a
+// *FormError as JSON and leaves other errors to fiber.DefaultErrorHandler.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	var formError *FormError
+	if errors.As(err, &formError) {
+		return c.Status(formError.Code).JSON(formError.Response)
+	}
+	return fiber.DefaultErrorHandler(c, err)
+}
@@ -0,0 +1,89 @@
+package request
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type signupRequest struct {
+	BaseFormRequest
+	Email string `json:"email"`
+	Age   int    `json:"age"`
+	Team  string `param:"team"`
+}
+
+func (r *signupRequest) Authorize(c *fiber.Ctx) bool {
+	return r.Team != "closed"
+}
+
+func (r *signupRequest) Prepare() {
+	r.Email = strings.ToLower(strings.TrimSpace(r.Email))
+}
+
+func (r *signupRequest) Rules() map[string]string {
+	return map[string]string{"email": "required|email", "Age": "gte=18"}
+}
+
+func (r *signupRequest) Messages() map[string]string {
+	return map[string]string{"age.gte": ":attribute must be at least :param"}
+}
+
+func (r *signupRequest) Attributes() map[string]string {
+	return map[string]string{"Age": "Your age"}
+}
+
+// postSignup sends body to the signup route of team and returns the status and the JSON response.
+func postSignup(t *testing.T, team string, body string) (int, map[string]interface{}) {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Post("/teams/:team/signup", func(c *fiber.Ctx) error {
+		input, err := Validated[signupRequest](c)
+		if err != nil {
+			return err
+		}
+		return c.JSON(fiber.Map{"email": input.Email, "team": input.Team})
+	})
+
+	req := httptest.NewRequest(fiber.MethodPost, "/teams/"+team+"/signup", strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	var response map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&response)
+	return resp.StatusCode, response
+}
+
+func TestValidated(t *testing.T) {
+	status, response := postSignup(t, "go", `{"email":" Sara@Example.com ","age":20}`)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, map[string]interface{}{"email": "sara@example.com", "team": "go"}, response)
+
+	status, response = postSignup(t, "closed", `{"email":"sara@example.com","age":20}`)
+	assert.Equal(t, fiber.StatusForbidden, status)
+	assert.Equal(t, "This action is unauthorized.", response["message"])
+
+	// Unauthorized requests are rejected before their input is checked
+	status, response = postSignup(t, "closed", `{"email":"sara@example.com","age":"old"}`)
+	assert.Equal(t, fiber.StatusForbidden, status)
+	assert.Nil(t, response["errors"])
+	status, _ = postSignup(t, "closed", `{"email":`)
+	assert.Equal(t, fiber.StatusForbidden, status)
+
+	status, response = postSignup(t, "go", `{"email":"sara","age":16}`)
+	assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+	assert.Equal(t, map[string]interface{}{
+		"email": []interface{}{"The email must be a valid email address."},
+		"age":   []interface{}{"Your age must be at least 18"},
+	}, response["errors"])
+
+	status, response = postSignup(t, "go", `{"email":"sara@example.com","age":"old"}`)
+	assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+	assert.Equal(t, map[string]interface{}{"age": []interface{}{"expected int but got string"}}, response["errors"])
+
+	status, _ = postSignup(t, "go", `{"email":`)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
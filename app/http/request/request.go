@@ -11,8 +11,6 @@ import (
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/mitchellh/mapstructure"
-	"github.com/mousav1/weiser/app/cookies"
 	"github.com/mousav1/weiser/app/http/validation"
 	"github.com/mousav1/weiser/app/session"
 )
@@ -32,47 +30,38 @@ func New(ctx *fiber.Ctx) (*Request, error) {
 	return &Request{ctx: ctx, validate: validate}, nil
 }
 
+// Bind fills data, a pointer to a struct, from the request body, query
+// string, route parameters and headers, and then validates it. Values that
+// cannot be converted to their fields are reported as a *BindError.
 func (r *Request) Bind(data interface{}) error {
-	contentType := r.ctx.Get("Content-Type")
-
-	switch contentType {
-	case "application/json":
-		if err := r.ctx.BodyParser(data); err != nil {
-			return fmt.Errorf("failed to parse JSON body: %w", err)
-		}
-	case "application/x-www-form-urlencoded":
-		if err := r.parseFormData(data); err != nil {
-			return fmt.Errorf("failed to parse form data: %w", err)
-		}
-	default:
-		return fmt.Errorf("unsupported content type: %s", contentType)
+	if err := r.bind(data); err != nil {
+		return err
 	}
 
 	if err := r.validateData(data); err != nil {
+		// Let server-rendered forms re-fill their fields after a redirect.
+		if !r.IsJson() {
+			r.FlashInput()
+		}
 		return err
 	}
 
 	return nil
 }
 
-func (r *Request) parseFormData(data interface{}) error {
-	formData := make(map[string]interface{})
-	if err := r.ctx.BodyParser(&formData); err != nil {
-		return err
-	}
-	return mapstructure.Decode(formData, &data)
-}
-
 func (r *Request) validateData(data interface{}) error {
 	validationErrors, err := r.validate.Validate(data)
-	if err != nil {
-		return fmt.Errorf("validation error: %w", err)
-	}
-	if validationErrors != nil {
+	if len(validationErrors) > 0 {
 		response := r.validate.CreateErrorResponse(validationErrors)
+		if !r.IsJson() {
+			r.Flash(session.FlashErrorKey, response.Errors)
+		}
 		jsonResp, _ := json.Marshal(response)
 		return fiber.NewError(http.StatusBadRequest, string(jsonResp))
 	}
+	if err != nil {
+		return fmt.Errorf("validation error: %w", err)
+	}
 	return nil
 }
 
@@ -276,42 +265,134 @@ func (r *Request) AllFiles() map[string][]*multipart.FileHeader {
 	return form.File
 }
 
-func (r *Request) getSessionID() (string, error) {
-	manager := session.GetSessionManager()
-	sessionID, err := cookies.GetCookie(r.ctx, "weiser_session")
+// session returns the session of the request.
+func (r *Request) session() (*session.Session, error) {
+	sessionData, err := session.FromContext(r.ctx)
 	if err != nil {
-		session := manager.StartSession(r.ctx)
-		return session.ID, nil
-	}
-	if err := manager.CheckExpiration(sessionID); err != nil {
-		return "", fmt.Errorf("session ID is invalid or has expired: %w", err)
+		return nil, fmt.Errorf("failed to load session: %w", err)
 	}
-	return sessionID, nil
+	return sessionData, nil
 }
 
 func (r *Request) Getsession(key string) interface{} {
-	sessionID, err := r.getSessionID()
+	sessionData, err := r.session()
 	if err != nil {
-		log.Printf("Failed to get session ID: %v\n", err)
+		log.Println(err)
 		return nil
 	}
-	return session.GetSessionManager().Get(key, sessionID)
+	return sessionData.Get(key)
 }
 
 func (r *Request) Setsession(key string, value interface{}) {
-	sessionID, err := r.getSessionID()
+	sessionData, err := r.session()
 	if err != nil {
-		log.Printf("Failed to get session ID: %v\n", err)
+		log.Println(err)
 		return
 	}
-	session.GetSessionManager().Set(key, value, sessionID)
+	sessionData.Put(key, value)
 }
 
 func (r *Request) Deletesession(key string) {
-	sessionID, err := r.getSessionID()
+	sessionData, err := r.session()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	sessionData.Forget(key)
+}
+
+// dontFlash lists the input fields that are never flashed to the session.
+var dontFlash = []string{"password", "password_confirmation", "current_password"}
+
+// Flash stores a value in the session for the next request only.
+func (r *Request) Flash(key string, value interface{}) {
+	sessionData, err := r.session()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	sessionData.Flash(key, value)
+}
+
+// Reflash keeps all flash data for an additional request.
+func (r *Request) Reflash() {
+	sessionData, err := r.session()
 	if err != nil {
-		log.Printf("Failed to get session ID: %v\n", err)
+		log.Println(err)
 		return
 	}
-	session.GetSessionManager().Delete(key, sessionID)
+	sessionData.Reflash()
+}
+
+// Keep keeps the given flash keys for an additional request.
+func (r *Request) Keep(keys ...string) {
+	sessionData, err := r.session()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	sessionData.Keep(keys...)
+}
+
+// FlashInput flashes the request input, except passwords, to the session.
+func (r *Request) FlashInput() {
+	input := r.input()
+	for _, key := range dontFlash {
+		delete(input, key)
+	}
+
+	sessionData, err := r.session()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	sessionData.FlashInput(input)
+}
+
+// Old returns a value of the input flashed by the previous request.
+func (r *Request) Old(key string, def ...string) string {
+	sessionData, err := r.session()
+	if err == nil {
+		if value, ok := sessionData.OldInput()[key]; ok {
+			return fmt.Sprint(value)
+		}
+	}
+	if len(def) > 0 {
+		return def[0]
+	}
+	return ""
+}
+
+// input returns the query and body values of the request.
+func (r *Request) input() map[string]interface{} {
+	input := make(map[string]interface{})
+	r.ctx.Request().URI().QueryArgs().VisitAll(func(key, value []byte) {
+		input[string(key)] = string(value)
+	})
+
+	if r.IsJson() {
+		var body map[string]interface{}
+		if err := json.Unmarshal(r.ctx.Body(), &body); err == nil {
+			for key, value := range body {
+				input[key] = value
+			}
+		}
+		return input
+	}
+
+	if form, err := r.ctx.MultipartForm(); err == nil {
+		for key, values := range form.Value {
+			if len(values) == 1 {
+				input[key] = values[0]
+			} else {
+				input[key] = values
+			}
+		}
+		return input
+	}
+
+	r.ctx.Request().PostArgs().VisitAll(func(key, value []byte) {
+		input[string(key)] = string(value)
+	})
+	return input
 }
@@ -0,0 +1,52 @@
+package requests
+
+import (
+	"strings"
+
+	"github.com/mousav1/weiser/app/http/request"
+)
+
+// CreateUser is the input of the create user action.
+type CreateUser struct {
+	request.BaseFormRequest
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// Prepare trims the username and normalises the email address.
+func (r *CreateUser) Prepare() {
+	r.Username = strings.TrimSpace(r.Username)
+	r.Email = strings.ToLower(strings.TrimSpace(r.Email))
+}
+
+func (r *CreateUser) Rules() map[string]string {
+	return map[string]string{
+		"username": "required",
+		"email":    "required|email",
+		"password": "required",
+	}
+}
+
+// UpdateUser is the input of the update user action.
+type UpdateUser struct {
+	request.BaseFormRequest
+	ID       uint   `json:"-" param:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// Prepare trims the username and normalises the email address.
+func (r *UpdateUser) Prepare() {
+	r.Username = strings.TrimSpace(r.Username)
+	r.Email = strings.ToLower(strings.TrimSpace(r.Email))
+}
+
+func (r *UpdateUser) Rules() map[string]string {
+	return map[string]string{
+		"id":       "required",
+		"email":    "required|email",
+		"password": "nullable|min:6|max:20",
+	}
+}
@@ -0,0 +1,121 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	databaseMutex sync.RWMutex
+	database      *gorm.DB
+)
+
+// ErrNoDatabase is returned by Validate and ValidateMap when the unique or
+// exists rule is used before SetDatabase was called.
+var ErrNoDatabase = errors.New("validation: the unique and exists rules need a database, see validation.SetDatabase")
+
+// ruleFailure holds the first error of a database rule that could not run
+// during a validation. The rule passes and Validate returns the error, so a
+// broken database is not reported as invalid input.
+type ruleFailure struct {
+	err error
+}
+
+type ruleFailureKey struct{}
+
+// withRuleFailure returns a context that collects the errors of database rules.
+func withRuleFailure(ctx context.Context) (context.Context, *ruleFailure) {
+	failure := &ruleFailure{}
+	return context.WithValue(ctx, ruleFailureKey{}, failure), failure
+}
+
+// fail records err in the ruleFailure of ctx.
+func fail(ctx context.Context, err error) {
+	if failure, ok := ctx.Value(ruleFailureKey{}).(*ruleFailure); ok && failure.err == nil {
+		failure.err = err
+	}
+}
+
+// SetDatabase sets the database queried by the unique and exists rules.
+func SetDatabase(db *gorm.DB) {
+	databaseMutex.Lock()
+	defer databaseMutex.Unlock()
+	database = db
+}
+
+// unique checks that no row of a table has the value of the field:
+//
+//	unique:table[,column[,except[,idColumn]]]
+//
+// The column defaults to the name of the field. except is the ID of a row
+// to ignore, e.g. the user being updated, and idColumn defaults to "id".
+func unique(ctx context.Context, fl validator.FieldLevel) bool {
+	if fl.Field().IsZero() {
+		return true
+	}
+	params := strings.Split(fl.Param(), ",")
+	count, err := countRows(fl, params, func(query *gorm.DB) *gorm.DB {
+		if len(params) > 2 && params[2] != "" {
+			idColumn := "id"
+			if len(params) > 3 && params[3] != "" {
+				idColumn = params[3]
+			}
+			query = query.Where(clause.Neq{Column: clause.Column{Name: idColumn}, Value: params[2]})
+		}
+		return query
+	})
+	if err != nil {
+		fail(ctx, err)
+		return true
+	}
+	return count == 0
+}
+
+// exists checks that a row of a table has the value of the field:
+//
+//	exists:table[,column]
+func exists(ctx context.Context, fl validator.FieldLevel) bool {
+	if fl.Field().IsZero() {
+		return true
+	}
+	count, err := countRows(fl, strings.Split(fl.Param(), ","), nil)
+	if err != nil {
+		fail(ctx, err)
+		return true
+	}
+	return count > 0
+}
+
+// countRows counts the rows of the table in params[0] whose column, params[1]
+// or the field name, equals the value of the field. Both rules leave empty
+// values to the required rule.
+func countRows(fl validator.FieldLevel, params []string, scope func(*gorm.DB) *gorm.DB) (int64, error) {
+	databaseMutex.RLock()
+	db := database
+	databaseMutex.RUnlock()
+	if db == nil {
+		return 0, ErrNoDatabase
+	}
+
+	column := fl.FieldName()
+	if len(params) > 1 && params[1] != "" {
+		column = params[1]
+	}
+	query := db.Table(params[0]).Where(clause.Eq{Column: clause.Column{Name: column}, Value: fl.Field().Interface()})
+	if scope != nil {
+		query = scope(query)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("validation: failed to run the %s rule: %w", fl.GetTag(), err)
+	}
+	return count, nil
+}
@@ -0,0 +1,107 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// DefaultMessages are the messages of failed rules, keyed by rule name.
+// Rules whose meaning depends on the type of the field have a message per
+// type, e.g. "min.string", "min.numeric" and "min.array". Messages may use
+// the placeholders :attribute (the display name of the field), :param (the
+// parameter of the rule), :value (the invalid value) and :rule.
+var DefaultMessages = map[string]string{
+	"required":      "The :attribute field is required.",
+	"email":         "The :attribute must be a valid email address.",
+	"url":           "The :attribute must be a valid URL.",
+	"uuid":          "The :attribute must be a valid UUID.",
+	"alpha":         "The :attribute may only contain letters.",
+	"alpha_num":     "The :attribute may only contain letters and numbers.",
+	"numeric":       "The :attribute must be a number.",
+	"integer":       "The :attribute must be an integer.",
+	"boolean":       "The :attribute field must be true or false.",
+	"in":            "The selected :attribute is invalid.",
+	"same":          "The :attribute and :param must match.",
+	"different":     "The :attribute and :param must be different.",
+	"unique":        "The :attribute has already been taken.",
+	"exists":        "The selected :attribute is invalid.",
+	"min.string":    "The :attribute must be at least :param characters.",
+	"min.numeric":   "The :attribute must be at least :param.",
+	"min.array":     "The :attribute must have at least :param items.",
+	"max.string":    "The :attribute may not be greater than :param characters.",
+	"max.numeric":   "The :attribute may not be greater than :param.",
+	"max.array":     "The :attribute may not have more than :param items.",
+	"size.string":   "The :attribute must be :param characters.",
+	"size.numeric":  "The :attribute must be :param.",
+	"size.array":    "The :attribute must contain :param items.",
+	"gt.numeric":    "The :attribute must be greater than :param.",
+	"gte.numeric":   "The :attribute must be greater than or equal to :param.",
+	"lt.numeric":    "The :attribute must be less than :param.",
+	"lte.numeric":   "The :attribute must be less than or equal to :param.",
+	"invalid":       "The :attribute field is invalid.",
+	"invalid.array": "The :attribute field contains invalid items.",
+}
+
+// message returns the error message of a failed rule of field. Custom
+// messages keyed by "field.rule" take precedence over those keyed by "rule",
+// which take precedence over the default messages.
+func (v *Validation) message(field string, err validator.FieldError) string {
+	return v.render(field, err.StructField(), err.Tag(), err.Param(), err.Value(), typeOf(err.Kind()))
+}
+
+// render returns the message of the rule tag with the placeholders replaced.
+func (v *Validation) render(field string, structField string, tag string, param string, value interface{}, kind string) string {
+	rule := tag
+	if alias, ok := ruleAliases[rule]; ok {
+		rule = alias
+	}
+
+	attribute, ok := v.attributes[field]
+	if !ok {
+		if attribute, ok = v.attributes[structField]; !ok {
+			attribute = strings.ReplaceAll(field, "_", " ")
+		}
+	}
+
+	message := v.template(field, structField, rule, tag, kind)
+	return strings.NewReplacer(
+		":attribute", attribute,
+		":param", param,
+		":value", fmt.Sprint(value),
+		":rule", rule,
+	).Replace(message)
+}
+
+// template finds the message of a rule, which may be known by its rule name
+// or its validator tag.
+func (v *Validation) template(field string, structField string, rule string, tag string, kind string) string {
+	for _, key := range []string{field + "." + rule, structField + "." + rule, field + "." + tag, rule, tag} {
+		if message, ok := v.messages[key]; ok {
+			return message
+		}
+	}
+	if message, ok := v.ruleMessages[tag]; ok {
+		return message
+	}
+	for _, key := range []string{rule + "." + kind, rule, "invalid." + kind} {
+		if message, ok := DefaultMessages[key]; ok {
+			return message
+		}
+	}
+	return DefaultMessages["invalid"]
+}
+
+// typeOf returns the type suffix of the messages of a field kind.
+func typeOf(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "array"
+	default:
+		return "numeric"
+	}
+}
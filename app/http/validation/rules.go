@@ -0,0 +1,116 @@
+package validation
+
+import "strings"
+
+// ruleNames maps the names of rules in rule strings to validator tags.
+var ruleNames = map[string]string{
+	"nullable":  "omitempty",
+	"sometimes": "omitempty",
+	"integer":   "number",
+	"alpha_num": "alphanum",
+	"in":        "oneof",
+	"size":      "len",
+	"same":      "eqfield",
+	"different": "nefield",
+}
+
+// comparisonRules are the rules that compare a field with another field.
+var comparisonRules = map[string]bool{
+	"same":      true,
+	"different": true,
+}
+
+// ruleAliases maps validator tags back to the rule names used in messages.
+var ruleAliases = map[string]string{
+	"number":   "integer",
+	"alphanum": "alpha_num",
+	"oneof":    "in",
+	"len":      "size",
+	"eqfield":  "same",
+	"nefield":  "different",
+}
+
+// ParseRules converts a rule string such as "required|email|max:255" into
+// validator tags. Rules are separated by "|" and parameters follow a ":"
+// separated by ",", as in "unique:users,email" or "in:draft,published".
+// Rules without ":" are passed through, so validator tags such as
+// "required,email" or "gte=18" keep working.
+func ParseRules(rules string) string {
+	return parseRules(rules, "")
+}
+
+// parseRules converts a rule string and uses column as the column of
+// database rules that do not name one.
+func parseRules(rules string, column string) string {
+	var tags []string
+	omitEmpty := false
+	for _, rule := range strings.Split(rules, "|") {
+		rule = strings.TrimSpace(rule)
+		name, params, hasParams := strings.Cut(rule, ":")
+		if tag, ok := ruleNames[name]; ok {
+			name = tag
+		}
+
+		switch {
+		case rule == "" || name == "string":
+			// Strings are the only input that is not typed by its field.
+		case name == "omitempty":
+			omitEmpty = true
+		case !hasParams:
+			tags = append(tags, name)
+		case name == "oneof":
+			tags = append(tags, name+"="+strings.ReplaceAll(params, ",", " "))
+		case name == "between":
+			lower, upper, _ := strings.Cut(params, ",")
+			tags = append(tags, "min="+lower, "max="+upper)
+		default:
+			if (name == "unique" || name == "exists") && column != "" && !strings.Contains(params, ",") {
+				params += "," + column
+			}
+			// The validator separates tags with commas, so they are escaped in parameters.
+			tags = append(tags, name+"="+strings.ReplaceAll(params, ",", "0x2C"))
+		}
+	}
+	if omitEmpty {
+		// omitempty only works as the first tag.
+		tags = append([]string{"omitempty"}, tags...)
+	}
+	return strings.Join(tags, ",")
+}
+
+// comparison is a same or different rule, which compares the value of a
+// field with that of another field.
+type comparison struct {
+	rule  string
+	other string
+}
+
+// splitComparisons removes the same and different rules from a rule string.
+// The validator only compares fields of structs, so ValidateMap compares
+// them itself.
+func splitComparisons(rules string) (string, []comparison) {
+	var kept []string
+	var comparisons []comparison
+	for _, rule := range strings.Split(rules, "|") {
+		name, other, _ := strings.Cut(strings.TrimSpace(rule), ":")
+		if comparisonRules[name] {
+			comparisons = append(comparisons, comparison{rule: name, other: other})
+			continue
+		}
+		kept = append(kept, rule)
+	}
+	return strings.Join(kept, "|"), comparisons
+}
+
+// renameComparisons replaces the input names compared by same and different
+// rules with the Go names of the fields, which the validator compares by.
+func renameComparisons(rules string, names map[string]string) string {
+	segments := strings.Split(rules, "|")
+	for i, rule := range segments {
+		name, other, _ := strings.Cut(strings.TrimSpace(rule), ":")
+		if goName, ok := names[other]; ok && comparisonRules[name] {
+			segments[i] = name + ":" + goName
+		}
+	}
+	return strings.Join(segments, "|")
+}
@@ -0,0 +1,211 @@
+package validation
+
+import (
+	stderrors "errors"
+	"reflect"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+func TestParseRules(t *testing.T) {
+	tests := map[string]string{
+		"required|email|max:255":        "required,email,max=255",
+		"nullable|integer|between:1,10": "omitempty,number,min=1,max=10",
+		"in:draft,published":            "oneof=draft published",
+		"string|unique:users,email":     "unique=users0x2Cemail",
+		"required,email":                "required,email",
+		"gte=18":                        "gte=18",
+	}
+	for rules, expected := range tests {
+		if tags := ParseRules(rules); tags != expected {
+			t.Errorf("ParseRules(%q) = %q, expected %q", rules, tags, expected)
+		}
+	}
+}
+
+func TestValidation_ValidateMap(t *testing.T) {
+	v := New()
+	v.SetMessages(map[string]string{"name.required": "Tell us your :attribute"})
+	v.SetAttributes(map[string]string{"nick_name": "nickname"})
+
+	data := map[string]interface{}{
+		"email":     "invalid",
+		"nick_name": "ab",
+		"status":    "deleted",
+		"tags":      []string{"a"},
+	}
+	rules := map[string]string{
+		"name":      "required",
+		"email":     "required|email",
+		"nick_name": "min:3",
+		"status":    "in:draft,published",
+		"tags":      "min:2",
+	}
+
+	errors, err := v.ValidateMap(data, rules)
+	if err == nil {
+		t.Fatal("Expected validation error, but got nil")
+	}
+	expected := []ValidationError{
+		{Field: "email", Error: "The email must be a valid email address."},
+		{Field: "name", Error: "Tell us your name"},
+		{Field: "nick_name", Error: "The nickname must be at least 3 characters."},
+		{Field: "status", Error: "The selected status is invalid."},
+		{Field: "tags", Error: "The tags must have at least 2 items."},
+	}
+	if !reflect.DeepEqual(errors, expected) {
+		t.Errorf("Expected %+v, but got %+v", expected, errors)
+	}
+
+	errors, err = v.ValidateMap(map[string]interface{}{"email": "sara@example.com"}, map[string]string{"email": "required|email"})
+	if err != nil || len(errors) != 0 {
+		t.Errorf("Expected no validation errors, but got %+v", errors)
+	}
+}
+
+func TestValidation_Comparisons(t *testing.T) {
+	v := New()
+	data := map[string]interface{}{
+		"password":              "secret1",
+		"password_confirmation": "secret2",
+		"old_password":          "secret1",
+	}
+	rules := map[string]string{
+		"password_confirmation": "required|same:password",
+		"password":              "required|different:old_password",
+		"nickname":              "nullable|different:password",
+	}
+	errors, _ := v.ValidateMap(data, rules)
+	expected := []ValidationError{
+		{Field: "password", Error: "The password and old_password must be different."},
+		{Field: "password_confirmation", Error: "The password confirmation and password must match."},
+	}
+	if !reflect.DeepEqual(errors, expected) {
+		t.Errorf("Expected %+v, but got %+v", expected, errors)
+	}
+
+	type TestStruct struct {
+		Password     string `json:"password"`
+		Confirmation string `json:"password_confirmation"`
+	}
+	if err := v.SetRules(TestStruct{}, map[string]string{"password_confirmation": "same:password"}); err != nil {
+		t.Fatal(err)
+	}
+	errors, _ = v.Validate(TestStruct{Password: "secret1", Confirmation: "secret2"})
+	if len(errors) != 1 || errors[0].Field != "password_confirmation" {
+		t.Errorf("Expected the confirmation to fail, but got %+v", errors)
+	}
+}
+
+func TestValidation_UnknownRules(t *testing.T) {
+	v := New()
+	for _, rule := range []string{"required|confirmed", "regex:^[a-z]+$", "requird"} {
+		if _, err := v.ValidateMap(map[string]interface{}{"name": "sara"}, map[string]string{"name": rule}); err == nil {
+			t.Errorf("Expected an error for the rules %q", rule)
+		}
+	}
+
+	type TestStruct struct {
+		Name string `json:"name"`
+	}
+	if err := v.SetRules(TestStruct{}, map[string]string{"name": "required|emial"}); err == nil {
+		t.Error("Expected an error for an unknown rule")
+	}
+}
+
+func TestValidation_JSONFieldNames(t *testing.T) {
+	v := New()
+
+	type TestStruct struct {
+		FirstName string `json:"first_name" validate:"required"`
+		Age       int    `json:"age"`
+	}
+	v.SetRules(TestStruct{}, map[string]string{"age": "required|gte:18"})
+
+	errors, _ := v.Validate(TestStruct{Age: 16})
+	expected := []ValidationError{
+		{Field: "first_name", Error: "The first name field is required."},
+		{Field: "age", Error: "The age must be greater than or equal to 18."},
+	}
+	if !reflect.DeepEqual(errors, expected) {
+		t.Errorf("Expected %+v, but got %+v", expected, errors)
+	}
+}
+
+func TestValidation_DatabaseRules(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	type User struct {
+		ID    uint
+		Email string
+	}
+	if err := db.AutoMigrate(&User{}); err != nil {
+		t.Fatal(err)
+	}
+	db.Create(&User{ID: 1, Email: "taken@example.com"})
+	SetDatabase(db)
+	defer SetDatabase(nil)
+
+	v := New()
+	rules := map[string]string{"email": "unique:users", "owner": "exists:users,email"}
+	errors, _ := v.ValidateMap(map[string]interface{}{"email": "taken@example.com", "owner": "nobody@example.com"}, rules)
+	expected := []ValidationError{
+		{Field: "email", Error: "The email has already been taken."},
+		{Field: "owner", Error: "The selected owner is invalid."},
+	}
+	if !reflect.DeepEqual(errors, expected) {
+		t.Errorf("Expected %+v, but got %+v", expected, errors)
+	}
+
+	// The row being updated is ignored.
+	rules = map[string]string{"email": "unique:users,email,1", "owner": "exists:users,email"}
+	errors, _ = v.ValidateMap(map[string]interface{}{"email": "taken@example.com", "owner": "taken@example.com"}, rules)
+	if len(errors) != 0 {
+		t.Errorf("Expected no validation errors, but got %+v", errors)
+	}
+
+	// A rule that cannot run is an error, not invalid input.
+	errors, err = v.ValidateMap(map[string]interface{}{"email": "new@example.com"}, map[string]string{"email": "unique:missing_table"})
+	if err == nil || len(errors) != 0 {
+		t.Errorf("Expected an error without validation errors, but got %v and %+v", err, errors)
+	}
+
+	SetDatabase(nil)
+	type Signup struct {
+		Email string `json:"email" validate:"unique=users"`
+	}
+	if errors, err = v.Validate(Signup{Email: "new@example.com"}); !stderrors.Is(err, ErrNoDatabase) || len(errors) != 0 {
+		t.Errorf("Expected ErrNoDatabase, but got %v and %+v", err, errors)
+	}
+}
+
+func TestValidation_AddValidation(t *testing.T) {
+	v := New()
+	even := func(fl validator.FieldLevel) bool { return fl.Field().Int()%2 == 0 }
+	if err := v.AddValidation("even", even, "The :attribute must be even."); err != nil {
+		t.Fatal(err)
+	}
+
+	type TestStruct struct {
+		Count int `json:"count" validate:"even"`
+	}
+	errors, _ := v.Validate(TestStruct{Count: 3})
+	expected := []ValidationError{{Field: "count", Error: "The count must be even."}}
+	if !reflect.DeepEqual(errors, expected) {
+		t.Errorf("Expected %+v, but got %+v", expected, errors)
+	}
+
+	if err := Extend("odd", func(fl validator.FieldLevel) bool { return fl.Field().Int()%2 == 1 }, "The :attribute must be odd."); err != nil {
+		t.Fatal(err)
+	}
+	errors, _ = New().ValidateMap(map[string]interface{}{"count": 4}, map[string]string{"count": "odd"})
+	expected = []ValidationError{{Field: "count", Error: "The count must be odd."}}
+	if !reflect.DeepEqual(errors, expected) {
+		t.Errorf("Expected %+v, but got %+v", expected, errors)
+	}
+}
@@ -1,13 +1,22 @@
 package validation
 
 import (
+	"context"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/go-playground/validator/v10"
 )
 
 type Validation struct {
-	validator *validator.Validate
+	validator  *validator.Validate
+	messages   map[string]string
+	attributes map[string]string
+	// ruleMessages are the default messages of rules added with Extend or AddValidation.
+	ruleMessages map[string]string
 }
 
 type ValidationError struct {
@@ -15,29 +24,210 @@ type ValidationError struct {
 	Error string `json:"error"`
 }
 
+// extension is a rule added with Extend.
+type extension struct {
+	fn      validator.Func
+	message string
+}
+
+var (
+	extensionsMutex sync.RWMutex
+	extensions      = make(map[string]extension)
+)
+
+// Extend adds a rule to every Validation created afterwards, e.g. the
+// validators of requests. message is the default message of the rule and
+// may use the placeholders of DefaultMessages.
+func Extend(tag string, fn validator.Func, message string) error {
+	if err := validator.New().RegisterValidation(tag, fn); err != nil {
+		return err
+	}
+	extensionsMutex.Lock()
+	defer extensionsMutex.Unlock()
+	extensions[tag] = extension{fn: fn, message: message}
+	return nil
+}
+
 func New() *Validation {
 	validate := validator.New()
 
 	// Defining default settings for the validator
 	validate.SetTagName("validate")
+	validate.RegisterTagNameFunc(fieldName)
 
-	return &Validation{validator: validate}
+	validate.RegisterValidationCtx("unique", unique)
+	validate.RegisterValidationCtx("exists", exists)
+
+	v := &Validation{validator: validate, ruleMessages: make(map[string]string)}
+	extensionsMutex.RLock()
+	defer extensionsMutex.RUnlock()
+	for tag, rule := range extensions {
+		validate.RegisterValidation(tag, rule.fn)
+		v.ruleMessages[tag] = rule.message
+	}
+	return v
+}
+
+// fieldName returns the name of a field in errors: the name it has in the
+// request input, or the Go name when it has no tag.
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "xml", "query", "param", "header"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return ""
 }
 
+// Validate validates the fields of the struct i. A database rule that could
+// not run is returned as an error without validation errors.
 func (v *Validation) Validate(i interface{}) ([]ValidationError, error) {
-	var errors []ValidationError
-	if err := v.validator.Struct(i); err != nil {
-		for _, err := range err.(validator.ValidationErrors) {
-			fieldName := err.Field()
+	ctx, failure := withRuleFailure(context.Background())
+	err := v.validator.StructCtx(ctx, i)
+	if failure.err != nil {
+		return nil, failure.err
+	}
 
-			errorMessage := fmt.Sprintf("Field validation for %s failed on the %s", fieldName, err.Tag())
-			errors = append(errors, ValidationError{Field: fieldName, Error: errorMessage})
+	var errors []ValidationError
+	if err != nil {
+		fieldErrors, ok := err.(validator.ValidationErrors)
+		if !ok {
+			return nil, err
+		}
+		for _, err := range fieldErrors {
+			errors = append(errors, ValidationError{Field: err.Field(), Error: v.message(err.Field(), err)})
 		}
 		return errors, fmt.Errorf("validation error")
 	}
 	return errors, nil
 }
 
+// ValidateMap validates dynamic data against rules keyed by the same keys,
+// e.g. {"email": "required|email|unique:users,email"}. The same and
+// different rules compare with other keys of data. Rules the validator does
+// not know and database rules that could not run are returned as an error.
+func (v *Validation) ValidateMap(data map[string]interface{}, rules map[string]string) ([]ValidationError, error) {
+	fields := make([]string, 0, len(rules))
+	for field := range rules {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	ctx, failure := withRuleFailure(context.Background())
+	var errors []ValidationError
+	for _, field := range fields {
+		rule, comparisons := splitComparisons(rules[field])
+		tags := parseRules(rule, field)
+		if err := v.checkTags(rules[field], tags); err != nil {
+			return nil, err
+		}
+
+		value := data[field]
+		err := v.validator.VarCtx(ctx, value, tags)
+		if failure.err != nil {
+			return nil, failure.err
+		}
+		if fieldErrors, ok := err.(validator.ValidationErrors); ok {
+			for _, err := range fieldErrors {
+				errors = append(errors, ValidationError{Field: field, Error: v.message(field, err)})
+			}
+		} else if err != nil {
+			return nil, err
+		}
+
+		if strings.HasPrefix(tags, "omitempty") && isEmpty(value) {
+			continue
+		}
+		for _, c := range comparisons {
+			if reflect.DeepEqual(value, data[c.other]) != (c.rule == "same") {
+				errors = append(errors, ValidationError{Field: field, Error: v.render(field, field, c.rule, c.other, value, "")})
+			}
+		}
+	}
+	if len(errors) > 0 {
+		return errors, fmt.Errorf("validation error")
+	}
+	return nil, nil
+}
+
+// isEmpty reports whether value is nil or the zero value of its type.
+func isEmpty(value interface{}) bool {
+	return value == nil || reflect.ValueOf(value).IsZero()
+}
+
+// checkTags returns an error when tags, parsed from rules, use a rule the
+// validator does not know, which it would otherwise panic on while validating.
+func (v *Validation) checkTags(rules string, tags string) (err error) {
+	if tags == "" {
+		return nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid validation rules %q: %v", rules, r)
+		}
+	}()
+	// An empty value with omitempty only parses the tags.
+	if !strings.HasPrefix(tags, "omitempty") {
+		tags = "omitempty," + tags
+	}
+	return v.validator.Var("", tags)
+}
+
+// SetRules overrides the rules of the fields of data's type. Rules are keyed
+// by input or struct field name and use the syntax of ParseRules, e.g.
+// {"email": "required|email|unique:users"}. Rules the validator does not
+// know are returned as an error.
+func (v *Validation) SetRules(data interface{}, rules map[string]string) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	typ := reflect.TypeOf(data)
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	names := structFieldNames(typ)
+
+	tags := make(map[string]string, len(rules))
+	for field, rule := range rules {
+		name, ok := names[field]
+		if !ok {
+			name = field
+		}
+		tags[name] = ParseRules(renameComparisons(rule, names))
+		if err := v.checkTags(rule, tags[name]); err != nil {
+			return err
+		}
+	}
+	v.validator.RegisterStructValidationMapRules(tags, data)
+	return nil
+}
+
+// structFieldNames maps the input names of the fields of typ to their Go names.
+func structFieldNames(typ reflect.Type) map[string]string {
+	names := make(map[string]string)
+	if typ.Kind() != reflect.Struct {
+		return names
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		if name := fieldName(typ.Field(i)); name != "" {
+			names[name] = typ.Field(i).Name
+		}
+	}
+	return names
+}
+
+// SetMessages sets custom error messages keyed by "field.rule" or by
+// "rule". Messages may use the placeholders of DefaultMessages.
+func (v *Validation) SetMessages(messages map[string]string) {
+	v.messages = messages
+}
+
+// SetAttributes sets the display names of fields used in error messages.
+func (v *Validation) SetAttributes(attributes map[string]string) {
+	v.attributes = attributes
+}
+
 type ErrorResponse struct {
 	Message string              `json:"message"`
 	Errors  map[string][]string `json:"errors"`
@@ -59,11 +249,14 @@ func (v *Validation) CreateErrorResponse(validationErrors []ValidationError) *Er
 	}
 }
 
-func (v *Validation) AddValidation(tag string, fn validator.Func, fields ...string) error {
-	for _, field := range fields {
-		if err := v.validator.RegisterValidation(fmt.Sprintf("%s_%s", field, tag), fn); err != nil {
-			return err
-		}
+// AddValidation adds a rule to this Validation only; use Extend to add it
+// to every Validation. The optional message is the default message of the rule.
+func (v *Validation) AddValidation(tag string, fn validator.Func, message ...string) error {
+	if err := v.validator.RegisterValidation(tag, fn); err != nil {
+		return err
+	}
+	if len(message) > 0 {
+		v.ruleMessages[tag] = message[0]
 	}
 	return nil
 }
@@ -105,3 +105,37 @@ func compareErrorMaps(a, b map[string][]string) bool {
 	}
 	return true
 }
+
+func TestValidation_RulesAndMessages(t *testing.T) {
+	v := New()
+
+	type TestStruct struct {
+		Name  string `validate:"required"`
+		Email string
+	}
+
+	v.SetRules(TestStruct{}, map[string]string{"Email": "required,min=5"})
+	v.SetMessages(map[string]string{
+		"Name.required": "Please enter :attribute",
+		"min":           ":attribute must have at least :param characters",
+	})
+	v.SetAttributes(map[string]string{"Name": "your name"})
+
+	errors, err := v.Validate(TestStruct{Email: "a@b"})
+	if err == nil {
+		t.Fatal("Expected validation error, but got nil")
+	}
+
+	expected := []ValidationError{
+		{Field: "Name", Error: "Please enter your name"},
+		{Field: "Email", Error: "Email must have at least 5 characters"},
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d validation errors, but got %+v", len(expected), errors)
+	}
+	for i, validationError := range errors {
+		if validationError != expected[i] {
+			t.Errorf("Expected %+v, but got %+v", expected[i], validationError)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// Format is an image encoding supported by the image service.
+type Format string
+
+const (
+	JPEG Format = "jpeg"
+	PNG  Format = "png"
+	GIF  Format = "gif"
+)
+
+// defaultQuality is the JPEG quality used when none is configured.
+const defaultQuality = 85
+
+// maxPixels limits the size of decoded images, so a small file that
+// declares huge dimensions cannot exhaust memory.
+const maxPixels = 50_000_000
+
+// ErrImageTooLarge is returned by Decode for images with more than maxPixels pixels.
+var ErrImageTooLarge = errors.New("image dimensions are too large")
+
+// ParseFormat returns the Format for a name such as "jpg" or "png".
+// An empty name returns an empty Format, which keeps the original format.
+func ParseFormat(name string) (Format, error) {
+	switch name {
+	case "":
+		return "", nil
+	case "jpeg", "jpg":
+		return JPEG, nil
+	case "png":
+		return PNG, nil
+	case "gif":
+		return GIF, nil
+	default:
+		return "", fmt.Errorf("unsupported image format: %s", name)
+	}
+}
+
+// Extension returns the file extension of the format, including the dot.
+func (f Format) Extension() string {
+	if f == JPEG {
+		return ".jpg"
+	}
+	return "." + string(f)
+}
+
+// ContentType returns the MIME type of the format.
+func (f Format) ContentType() string {
+	return "image/" + string(f)
+}
+
+// Decode reads a JPEG, PNG or GIF image. JPEG images are rotated according
+// to their EXIF orientation, since the metadata is not kept by Encode.
+// Only the first frame of an animated GIF is decoded.
+func Decode(r io.Reader) (image.Image, Format, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, "", err
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, "", ErrImageTooLarge
+	}
+	img, name, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	format := Format(name)
+	if format == JPEG {
+		img = orient(img, exifOrientation(data))
+	}
+	return img, format, nil
+}
+
+// Encode writes img in the given format. Images are always encoded from
+// pixels, so EXIF and other metadata of the original are stripped.
+// Transparent areas are flattened onto white for JPEG.
+func Encode(w io.Writer, img image.Image, format Format, quality int) error {
+	switch format {
+	case JPEG:
+		if quality <= 0 || quality > 100 {
+			quality = defaultQuality
+		}
+		return jpeg.Encode(w, flatten(img), &jpeg.Options{Quality: quality})
+	case PNG:
+		return png.Encode(w, img)
+	case GIF:
+		return gif.Encode(w, img, &gif.Options{NumColors: 256})
+	default:
+		return fmt.Errorf("unsupported image format: %s", format)
+	}
+}
+
+// flatten draws img onto a white background.
+func flatten(img image.Image) image.Image {
+	if opaque, ok := img.(interface{ Opaque() bool }); ok && opaque.Opaque() {
+		return img
+	}
+	bounds := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Over)
+	return dst
+}
+
+// exifOrientation returns the orientation tag (1-8) of a JPEG file, or 1
+// when the file has none.
+func exifOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 {
+			// Start of scan or end of image: no more metadata segments.
+			return 1
+		}
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if size < 2 || i+2+size > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+size]
+		if marker == 0xE1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return tiffOrientation(segment[6:])
+		}
+		i += 2 + size
+	}
+	return 1
+}
+
+// tiffOrientation reads the orientation tag from the first IFD of the TIFF
+// structure inside an EXIF segment.
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for k := 0; k < entries; k++ {
+		entry := ifd + 2 + k*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if orientation := int(order.Uint16(tiff[entry+8:])); orientation >= 1 && orientation <= 8 {
+				return orientation
+			}
+			return 1
+		}
+	}
+	return 1
+}
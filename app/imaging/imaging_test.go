@@ -0,0 +1,100 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testImage returns an image whose left half is red and right half is blue.
+func testImage(width int, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x < width/2 {
+				img.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+	return img
+}
+
+// withOrientation inserts an EXIF segment with the given orientation after the SOI marker of a JPEG file.
+func withOrientation(data []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01")
+	entry := make([]byte, 12)
+	binary.BigEndian.PutUint16(entry[0:], 0x0112)
+	binary.BigEndian.PutUint16(entry[2:], 3)
+	binary.BigEndian.PutUint32(entry[4:], 1)
+	binary.BigEndian.PutUint16(entry[8:], orientation)
+	tiff = append(append(tiff, entry...), 0, 0, 0, 0)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	header := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(header[2:], uint16(len(segment)+2))
+
+	result := append([]byte{}, data[:2]...)
+	result = append(append(result, header...), segment...)
+	return append(result, data[2:]...)
+}
+
+func TestTransforms(t *testing.T) {
+	img := testImage(400, 200)
+
+	assert.Equal(t, image.Rect(0, 0, 100, 50), Resize(img, 100, 0).Bounds())
+	assert.Equal(t, image.Rect(0, 0, 100, 100), Resize(img, 100, 100).Bounds())
+	assert.Equal(t, image.Rect(0, 0, 100, 50), Fit(img, 100, 100).Bounds())
+	assert.Equal(t, img.Bounds(), Fit(img, 1000, 1000).Bounds())
+	assert.Equal(t, image.Rect(0, 0, 50, 50), Crop(img, image.Rect(10, 10, 60, 60)).Bounds())
+
+	// Fill برش وسط تصویر را برمی‌گرداند که نیمی قرمز و نیمی آبی است
+	filled := Fill(img, 100, 100)
+	assert.Equal(t, image.Rect(0, 0, 100, 100), filled.Bounds())
+	r, _, _, _ := filled.At(10, 50).RGBA()
+	_, _, b, _ := filled.At(90, 50).RGBA()
+	assert.Greater(t, r, uint32(0xf000))
+	assert.Greater(t, b, uint32(0xf000))
+}
+
+func TestDecodeAndEncode(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, testImage(40, 20), nil))
+
+	// جهت EXIF قبل از حذف متادیتا اعمال می‌شود
+	img, format, err := Decode(bytes.NewReader(withOrientation(buf.Bytes(), 6)))
+	assert.NoError(t, err)
+	assert.Equal(t, JPEG, format)
+	assert.Equal(t, image.Rect(0, 0, 20, 40), img.Bounds())
+
+	var out bytes.Buffer
+	assert.NoError(t, Encode(&out, img, PNG, 0))
+	decoded, err := png.Decode(&out)
+	assert.NoError(t, err)
+	assert.Equal(t, img.Bounds(), decoded.Bounds())
+
+	out.Reset()
+	assert.NoError(t, Encode(&out, img, JPEG, 90))
+	assert.Equal(t, 1, exifOrientation(out.Bytes()))
+
+	_, _, err = Decode(bytes.NewReader([]byte("not an image")))
+	assert.ErrorIs(t, err, image.ErrFormat)
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("jpg")
+	assert.NoError(t, err)
+	assert.Equal(t, JPEG, format)
+	assert.Equal(t, ".jpg", format.Extension())
+	assert.Equal(t, "image/jpeg", format.ContentType())
+
+	_, err = ParseFormat("webp")
+	assert.Error(t, err)
+}
@@ -0,0 +1,287 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mousav1/weiser/app/file"
+	"github.com/mousav1/weiser/app/storage"
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+var (
+	// ErrUnknownVariant is returned for variant names that are not configured.
+	ErrUnknownVariant = errors.New("unknown image variant")
+	// ErrNotOriginal is returned when a variant is requested of a path outside
+	// the image directory or of another variant.
+	ErrNotOriginal = errors.New("not an original image")
+)
+
+const (
+	// defaultMaxAge is how long browsers may use variants served by Handler
+	// before revalidating them.
+	defaultMaxAge = 24 * time.Hour
+	// lockStripes is the number of mutexes variants are generated under.
+	lockStripes = 64
+)
+
+// Variant is a named derived size of an image, e.g. a square thumbnail.
+type Variant struct {
+	Width  int
+	Height int
+	Mode   Mode
+	// Format of the variant; empty keeps the format of the original.
+	Format  Format
+	Quality int
+}
+
+// validate reports configuration errors of the variant.
+func (v Variant) validate() error {
+	if v.Width < 0 || v.Height < 0 || (v.Width == 0 && v.Height == 0) {
+		return fmt.Errorf("invalid size %dx%d", v.Width, v.Height)
+	}
+	switch v.Mode {
+	case ModeResize, ModeFit:
+	case ModeCrop:
+		if v.Width == 0 || v.Height == 0 {
+			return fmt.Errorf("crop mode requires both width and height")
+		}
+	default:
+		return fmt.Errorf("unknown mode %q", v.Mode)
+	}
+	return nil
+}
+
+// Apply transforms img into the size of the variant.
+func (v Variant) Apply(img image.Image) image.Image {
+	switch v.Mode {
+	case ModeFit:
+		return Fit(img, v.Width, v.Height)
+	case ModeCrop:
+		return Fill(img, v.Width, v.Height)
+	default:
+		return Resize(img, v.Width, v.Height)
+	}
+}
+
+// Service generates variants of images stored on a disk. A variant is
+// created the first time it is requested and stored next to the original,
+// e.g. "avatars/abc.png_thumb.jpg" for "avatars/abc.png".
+type Service struct {
+	disk     storage.Storage
+	variants map[string]Variant
+	maxAge   time.Duration
+	// directory holds the originals; empty allows every path of the disk.
+	directory string
+
+	locks [lockStripes]sync.Mutex
+}
+
+// NewService creates an image service for the images on disk.
+func NewService(disk storage.Storage, variants map[string]Variant) (*Service, error) {
+	for name, variant := range variants {
+		if err := variant.validate(); err != nil {
+			return nil, fmt.Errorf("invalid image variant %s: %w", name, err)
+		}
+	}
+	return &Service{disk: disk, variants: variants, maxAge: defaultMaxAge}, nil
+}
+
+// ServiceFromConfig creates an image service from the "images"
+// configuration. An empty images.disk selects the default storage disk.
+func ServiceFromConfig() (*Service, error) {
+	disk := storage.DefaultDriver
+	if name := viper.GetString("images.disk"); name != "" {
+		var err error
+		if disk, err = storage.SelectDriver(name); err != nil {
+			return nil, fmt.Errorf("failed to create image service: %w", err)
+		}
+	}
+	if disk == nil {
+		return nil, fmt.Errorf("failed to create image service: no storage disk is configured")
+	}
+
+	variants := make(map[string]Variant)
+	for name, config := range viper.GetStringMap("images.variants") {
+		settings := cast.ToStringMap(config)
+		format, err := ParseFormat(cast.ToString(settings["format"]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid image variant %s: %w", name, err)
+		}
+		mode := Mode(cast.ToString(settings["mode"]))
+		if mode == "" {
+			mode = ModeFit
+		}
+		variants[name] = Variant{
+			Width:   cast.ToInt(settings["width"]),
+			Height:  cast.ToInt(settings["height"]),
+			Mode:    mode,
+			Format:  format,
+			Quality: cast.ToInt(settings["quality"]),
+		}
+	}
+
+	service, err := NewService(disk, variants)
+	if err != nil {
+		return nil, err
+	}
+	if maxAge := viper.GetDuration("images.max_age"); maxAge > 0 {
+		service.maxAge = maxAge
+	}
+	service.directory = strings.Trim(path.Clean("/"+viper.GetString("images.directory")), "/")
+	return service, nil
+}
+
+// VariantPath returns the disk path of a variant of original. The name of
+// the variant keeps the extension of the original, so that "abc.png" and
+// "abc.jpg" do not share a variant when it changes the format.
+func (s *Service) VariantPath(original string, name string) (string, error) {
+	variant, ok := s.variants[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownVariant, name)
+	}
+	extension := path.Ext(original)
+	if variant.Format != "" {
+		extension = variant.Format.Extension()
+	}
+	return original + "_" + name + extension, nil
+}
+
+// Variant returns the disk path of a variant of original, generating it
+// when it does not exist yet or is older than the original, e.g. after the
+// original was replaced. original must be in the image directory and
+// must not be a variant itself, so that requests cannot fill the disk with
+// variants of variants.
+func (s *Service) Variant(original string, name string) (string, error) {
+	original = strings.TrimPrefix(path.Clean("/"+original), "/")
+	if err := s.checkOriginal(original); err != nil {
+		return "", err
+	}
+	variantPath, err := s.VariantPath(original, name)
+	if err != nil {
+		return "", err
+	}
+
+	lock := s.lock(variantPath)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if s.upToDate(original, variantPath) {
+		return variantPath, nil
+	}
+
+	source, err := s.disk.ReadStream(original)
+	if err != nil {
+		return "", err
+	}
+	img, format, err := Decode(source)
+	source.Close()
+	if err != nil {
+		return "", err
+	}
+
+	variant := s.variants[name]
+	if variant.Format != "" {
+		format = variant.Format
+	}
+	var buf bytes.Buffer
+	if err := Encode(&buf, variant.Apply(img), format, variant.Quality); err != nil {
+		return "", err
+	}
+	if err := s.disk.Put(variantPath, bytes.NewReader(buf.Bytes())); err != nil {
+		return "", err
+	}
+	return variantPath, nil
+}
+
+// DeleteVariants removes the generated variants of original, e.g. after
+// the original is replaced or deleted.
+func (s *Service) DeleteVariants(original string) error {
+	var errs []error
+	for name := range s.variants {
+		variantPath, _ := s.VariantPath(original, name)
+		if exists, err := s.disk.Exists(variantPath); err != nil || !exists {
+			continue
+		}
+		if err := s.disk.Delete(variantPath); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// upToDate reports whether the variant exists and is not older than its
+// original. Disks that do not report modification times only check that the
+// variant exists.
+func (s *Service) upToDate(original string, variantPath string) bool {
+	variantInfo, err := storage.Stat(s.disk, variantPath)
+	if err != nil {
+		return false
+	}
+	originalInfo, err := storage.Stat(s.disk, original)
+	if err != nil {
+		return false
+	}
+	return !variantInfo.LastModified.Before(originalInfo.LastModified)
+}
+
+// checkOriginal rejects originals outside the image directory and paths
+// named like a variant, e.g. "avatars/abc.png_thumb.jpg".
+func (s *Service) checkOriginal(original string) error {
+	if s.directory != "" && !strings.HasPrefix(original, s.directory+"/") {
+		return fmt.Errorf("%w: %s", ErrNotOriginal, original)
+	}
+	stem := strings.TrimSuffix(path.Base(original), path.Ext(original))
+	for name := range s.variants {
+		if strings.HasSuffix(stem, "_"+name) {
+			return fmt.Errorf("%w: %s", ErrNotOriginal, original)
+		}
+	}
+	return nil
+}
+
+// lock returns the mutex that keeps a variant from being generated twice at
+// once. Variants share a fixed number of mutexes, so no state is kept per path.
+func (s *Service) lock(variantPath string) *sync.Mutex {
+	hash := fnv.New32a()
+	hash.Write([]byte(variantPath))
+	return &s.locks[hash.Sum32()%lockStripes]
+}
+
+// Handler serves variants on a route with ":variant" and "*" parameters:
+//
+//	app.Get("/images/:variant/*", images.Handler())
+//
+// so that "/images/thumb/avatars/abc.png" serves the thumb variant of
+// "avatars/abc.png". Browsers cache variants for the configured max age and
+// then revalidate them with their ETag, which changes when the variant is
+// generated again.
+func (s *Service) Handler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		variantPath, err := s.Variant(c.Params("*"), c.Params("variant"))
+		switch {
+		case errors.Is(err, ErrUnknownVariant), errors.Is(err, ErrNotOriginal), errors.Is(err, os.ErrNotExist), errors.Is(err, storage.ErrPathOutsideRoot):
+			return c.Status(fiber.StatusNotFound).SendString("image not found")
+		case errors.Is(err, image.ErrFormat), errors.Is(err, ErrImageTooLarge):
+			return c.Status(fiber.StatusUnsupportedMediaType).SendString("file is not a supported image")
+		case err != nil:
+			return c.Status(fiber.StatusInternalServerError).SendString("failed to process image")
+		}
+
+		return file.Respond(c, s.disk, variantPath, file.DownloadOptions{
+			Disposition:  file.Inline,
+			CacheControl: "public, max-age=" + strconv.Itoa(int(s.maxAge.Seconds())),
+		})
+	}
+}
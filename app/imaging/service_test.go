@@ -0,0 +1,120 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mousav1/weiser/app/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestService(t *testing.T) (*Service, *storage.MemoryDriver) {
+	disk := storage.NewMemoryDriver()
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, testImage(400, 200)))
+	assert.NoError(t, disk.Put("avatars/abc.png", bytes.NewReader(buf.Bytes())))
+
+	service, err := NewService(disk, map[string]Variant{
+		"thumb":  {Width: 100, Height: 100, Mode: ModeCrop, Format: JPEG},
+		"medium": {Width: 200, Mode: ModeFit},
+	})
+	assert.NoError(t, err)
+	return service, disk
+}
+
+func TestService_Variant(t *testing.T) {
+	service, disk := newTestService(t)
+
+	variantPath, err := service.Variant("avatars/abc.png", "thumb")
+	assert.NoError(t, err)
+	assert.Equal(t, "avatars/abc.png_thumb.jpg", variantPath)
+
+	content, _ := disk.Get(variantPath)
+	img, format, err := image.Decode(content)
+	assert.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, image.Rect(0, 0, 100, 100), img.Bounds())
+
+	variantPath, err = service.Variant("avatars/abc.png", "medium")
+	assert.NoError(t, err)
+	assert.Equal(t, "avatars/abc.png_medium.png", variantPath)
+
+	// تصاویر هم‌نام با قالب دیگر نسخه جداگانه دارند
+	assert.NoError(t, disk.Copy("avatars/abc.png", "avatars/abc.gif"))
+	otherPath, err := service.Variant("avatars/abc.gif", "thumb")
+	assert.NoError(t, err)
+	assert.Equal(t, "avatars/abc.gif_thumb.jpg", otherPath)
+	assert.NoError(t, service.DeleteVariants("avatars/abc.gif"))
+	assert.NoError(t, disk.Delete("avatars/abc.gif"))
+
+	// نسخه‌ای که از تصویر اصلی قدیمی‌تر است دوباره ساخته می‌شود
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, testImage(200, 400)))
+	assert.NoError(t, disk.Put("avatars/abc.png", bytes.NewReader(buf.Bytes())))
+	variantPath, err = service.Variant("avatars/abc.png", "medium")
+	assert.NoError(t, err)
+	content, _ = disk.Get(variantPath)
+	img, _, err = image.Decode(content)
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 200, 400), img.Bounds())
+
+	_, err = service.Variant("avatars/abc.png", "huge")
+	assert.ErrorIs(t, err, ErrUnknownVariant)
+
+	// نسخه‌ها خودشان تصویر اصلی نیستند
+	_, err = service.Variant("avatars/abc.png_thumb.jpg", "thumb")
+	assert.ErrorIs(t, err, ErrNotOriginal)
+
+	service.directory = "photos"
+	_, err = service.Variant("avatars/abc.png", "medium")
+	assert.ErrorIs(t, err, ErrNotOriginal)
+	_, err = service.Variant("photos/../avatars/abc.png", "medium")
+	assert.ErrorIs(t, err, ErrNotOriginal)
+	service.directory = ""
+
+	assert.NoError(t, service.DeleteVariants("avatars/abc.png"))
+	files, _ := disk.Files("avatars", false)
+	assert.Equal(t, []string{"avatars/abc.png"}, files)
+
+	_, err = NewService(disk, map[string]Variant{"bad": {Width: 100, Mode: ModeCrop}})
+	assert.Error(t, err)
+}
+
+func TestService_Handler(t *testing.T) {
+	service, _ := newTestService(t)
+	app := fiber.New()
+	app.Get("/images/:variant/*", service.Handler())
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/images/thumb/avatars/abc.png", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/jpeg", resp.Header.Get(fiber.HeaderContentType))
+	assert.Equal(t, "public, max-age=86400", resp.Header.Get(fiber.HeaderCacheControl))
+	body, _ := io.ReadAll(resp.Body)
+	_, format, err := image.Decode(bytes.NewReader(body))
+	assert.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+
+	req := httptest.NewRequest(fiber.MethodGet, "/images/thumb/avatars/abc.png", nil)
+	req.Header.Set(fiber.HeaderIfNoneMatch, resp.Header.Get(fiber.HeaderETag))
+	resp, err = app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotModified, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/images/thumb/avatars/missing.png", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/images/huge/avatars/abc.png", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/images/thumb/avatars/abc.png_thumb.jpg", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,129 @@
+package imaging
+
+import (
+	"image"
+
+	"golang.org/x/image/draw"
+)
+
+// Mode is the way a variant is sized into its width and height.
+type Mode string
+
+const (
+	// ModeResize scales to exactly width x height. When one of them is zero
+	// it is derived from the aspect ratio.
+	ModeResize Mode = "resize"
+	// ModeFit scales down to fit inside width x height, keeping the aspect ratio.
+	ModeFit Mode = "fit"
+	// ModeCrop scales to cover width x height and crops the centre.
+	ModeCrop Mode = "crop"
+)
+
+// Resize scales img to width x height. A zero width or height keeps the
+// aspect ratio of img.
+func Resize(img image.Image, width int, height int) image.Image {
+	bounds := img.Bounds()
+	if width <= 0 && height <= 0 {
+		return img
+	}
+	if width <= 0 {
+		width = atLeastOne(bounds.Dx() * height / bounds.Dy())
+	}
+	if height <= 0 {
+		height = atLeastOne(bounds.Dy() * width / bounds.Dx())
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// Fit scales img down so that it fits inside width x height. Smaller images
+// are returned unchanged. A zero width or height is not constrained.
+func Fit(img image.Image, width int, height int) image.Image {
+	bounds := img.Bounds()
+	scale := 1.0
+	if width > 0 && bounds.Dx() > width {
+		scale = float64(width) / float64(bounds.Dx())
+	}
+	if height > 0 && float64(bounds.Dy())*scale > float64(height) {
+		scale = float64(height) / float64(bounds.Dy())
+	}
+	if scale == 1 {
+		return img
+	}
+	return Resize(img, atLeastOne(int(float64(bounds.Dx())*scale+0.5)), atLeastOne(int(float64(bounds.Dy())*scale+0.5)))
+}
+
+// Fill scales img to cover width x height and crops the centre, e.g. for
+// square thumbnails.
+func Fill(img image.Image, width int, height int) image.Image {
+	bounds := img.Bounds()
+	if width <= 0 || height <= 0 {
+		return Resize(img, width, height)
+	}
+
+	// Crop the largest centred area with the target aspect ratio, then scale it.
+	cropWidth, cropHeight := bounds.Dx(), bounds.Dx()*height/width
+	if cropHeight > bounds.Dy() {
+		cropWidth, cropHeight = bounds.Dy()*width/height, bounds.Dy()
+	}
+	x := bounds.Min.X + (bounds.Dx()-cropWidth)/2
+	y := bounds.Min.Y + (bounds.Dy()-cropHeight)/2
+	return Resize(Crop(img, image.Rect(x, y, x+cropWidth, y+cropHeight)), width, height)
+}
+
+// Crop returns the part of img inside rect.
+func Crop(img image.Image, rect image.Rectangle) image.Image {
+	rect = rect.Intersect(img.Bounds())
+	dst := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, rect.Min, draw.Src)
+	return dst
+}
+
+// orient rotates and flips img so that an image with the given EXIF
+// orientation is displayed upright.
+func orient(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	dstWidth, dstHeight := w, h
+	if orientation >= 5 {
+		dstWidth, dstHeight = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// atLeastOne keeps computed dimensions of very thin images from reaching zero.
+func atLeastOne(n int) int {
+	if n < 1 {
+		return 1
+	}
+	return n
+}
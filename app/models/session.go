@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Session represents a row of the sessions table used by the database
+// session driver.
+type Session struct {
+	ID           string    `gorm:"primaryKey;size:64" json:"id"`
+	UserID       *uint     `gorm:"index" json:"user_id"`
+	IPAddress    string    `gorm:"size:45" json:"ip_address"`
+	UserAgent    string    `json:"user_agent"`
+	Payload      string    `gorm:"type:text" json:"-"`
+	LastActivity time.Time `gorm:"index" json:"last_activity"`
+	ExpiresAt    time.Time `gorm:"index" json:"expires_at"`
+}
+
+// TableName returns the table name of the Session model.
+func (Session) TableName() string {
+	return "sessions"
+}
@@ -17,20 +17,6 @@ type UserService interface {
 	DeleteUser(uint) error
 }
 
-// UpdateUserInput represents the input required for updating a user.
-type UpdateUserInput struct {
-	Username string `json:"username"`
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"omitempty,min=6,max=20"`
-}
-
-// CreateUserInput represents the input required for creating a user.
-type CreateUserInput struct {
-	Username string `json:"username" validate:"required"`
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
-}
-
 type userService struct {
 	userRepository *repositories.UserRepository
 }
@@ -0,0 +1,208 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mousav1/weiser/app/cookies"
+	"github.com/mousav1/weiser/app/encryption"
+)
+
+const (
+	// cookieChunkSize keeps every cookie below the 4096 byte browser limit,
+	// leaving room for the name and attributes.
+	cookieChunkSize = 3800
+	// defaultMaxCookieChunks is the default number of cookies a session may
+	// be split across.
+	defaultMaxCookieChunks = 4
+)
+
+var (
+	// ErrSessionTooLarge is returned when the session data does not fit in
+	// the allowed number of cookies.
+	ErrSessionTooLarge = errors.New("session data is too large for cookie storage")
+	// errOutsideRequest is returned when a cookie session is accessed by ID,
+	// which is only possible through the request that carries it.
+	errOutsideRequest = errors.New("cookie sessions are only available through the request, see session.FromContext")
+)
+
+// RequestStorage is implemented by storages that keep the session data in
+// the request itself instead of on the server. Load decodes the session of
+// the request and Save writes it to the response; in between the session
+// only lives in the locals of the request.
+type RequestStorage interface {
+	Storage
+	Load(c *fiber.Ctx) (Session, error)
+	Save(c *fiber.Ctx, sessionData Session) error
+}
+
+// CookieStorage keeps the whole session in encrypted cookies, so no server
+// side state is needed. The session is encrypted with a key derived from
+// session.key and split across several cookies when it grows beyond the
+// size of a single cookie.
+type CookieStorage struct {
+	encrypter *encryption.Encrypter
+	maxChunks int
+}
+
+// NewCookieStorage initializes cookie storage. Cookies are sealed with key;
+// cookies sealed with one of the previous keys are still accepted, so keys
+// can be rotated without logging everyone out.
+func NewCookieStorage(key string, previousKeys []string, maxChunks int) (*CookieStorage, error) {
+	if key == "" {
+		return nil, errors.New("session.key is required for cookie storage")
+	}
+	if maxChunks <= 0 {
+		maxChunks = defaultMaxCookieChunks
+	}
+
+	previous := make([][]byte, 0, len(previousKeys))
+	for _, k := range previousKeys {
+		previous = append(previous, encryption.DeriveKey(k))
+	}
+
+	encrypter, err := encryption.New(encryption.DeriveKey(key), previous...)
+	if err != nil {
+		return nil, err
+	}
+	return &CookieStorage{encrypter: encrypter, maxChunks: maxChunks}, nil
+}
+
+func (cs *CookieStorage) Set(key string, value Session) error {
+	return errOutsideRequest
+}
+
+func (cs *CookieStorage) Get(key string) (Session, error) {
+	return Session{}, errOutsideRequest
+}
+
+// Delete does nothing, the session is dropped when the response replaces
+// the session cookies.
+func (cs *CookieStorage) Delete(key string) error {
+	return nil
+}
+
+// GetSessionIDs returns no IDs, sessions only live in the clients' cookies.
+func (cs *CookieStorage) GetSessionIDs() ([]string, error) {
+	return []string{}, nil
+}
+
+// Load decrypts the session cookies of the request.
+func (cs *CookieStorage) Load(c *fiber.Ctx) (Session, error) {
+	sealed, err := readCookieChunks(c)
+	if err != nil {
+		return Session{}, err
+	}
+
+	plaintext, err := cs.encrypter.Decrypt(sealed)
+	if err != nil {
+		return Session{}, errors.New("invalid session cookie")
+	}
+
+	var sessionData Session
+	if err := json.Unmarshal(plaintext, &sessionData); err != nil {
+		return Session{}, err
+	}
+	if sessionData.ID == "" {
+		return Session{}, errors.New("invalid session cookie")
+	}
+	if sessionData.Data == nil {
+		sessionData.Data = make(map[string]interface{})
+	}
+	return sessionData, nil
+}
+
+// Save encrypts the session into the response cookies and removes the
+// cookies of chunks the session no longer needs.
+func (cs *CookieStorage) Save(c *fiber.Ctx, sessionData Session) error {
+	incoming := countCookieChunks(c)
+
+	plaintext, err := json.Marshal(sessionData)
+	if err != nil {
+		return err
+	}
+
+	sealed, err := cs.encrypter.Encrypt(plaintext)
+	if err != nil {
+		return err
+	}
+
+	chunks := splitCookieValue(sealed)
+	if len(chunks) > cs.maxChunks {
+		return fmt.Errorf("%w: %d bytes", ErrSessionTooLarge, len(sealed))
+	}
+
+	for i, chunk := range chunks {
+		if i == 0 {
+			chunk = strconv.Itoa(len(chunks)) + "." + chunk
+		}
+		cookies.SetCookie(c, cookieChunkName(i), chunk, sessionData.Expires)
+	}
+	expireCookieChunks(c, len(chunks), incoming)
+
+	return nil
+}
+
+// cookieChunkName returns the name of the cookie holding the i-th chunk.
+func cookieChunkName(i int) string {
+	if i == 0 {
+		return CookieName
+	}
+	return CookieName + "_" + strconv.Itoa(i)
+}
+
+// readCookieChunks joins the session cookies of the request. The first
+// cookie is prefixed with the number of chunks.
+func readCookieChunks(c *fiber.Ctx) (string, error) {
+	first := c.Cookies(CookieName)
+	prefix, value, ok := strings.Cut(first, ".")
+	if !ok {
+		return "", errors.New("invalid session cookie")
+	}
+
+	count, err := strconv.Atoi(prefix)
+	if err != nil || count < 1 {
+		return "", errors.New("invalid session cookie")
+	}
+
+	var b strings.Builder
+	b.WriteString(value)
+	for i := 1; i < count; i++ {
+		chunk := c.Cookies(cookieChunkName(i))
+		if chunk == "" {
+			return "", errors.New("incomplete session cookie")
+		}
+		b.WriteString(chunk)
+	}
+	return b.String(), nil
+}
+
+// countCookieChunks returns the number of session cookies sent by the client.
+func countCookieChunks(c *fiber.Ctx) int {
+	count := 0
+	for c.Cookies(cookieChunkName(count)) != "" {
+		count++
+	}
+	return count
+}
+
+// expireCookieChunks removes the session cookies from index from up to to.
+func expireCookieChunks(c *fiber.Ctx, from, to int) {
+	for i := from; i < to; i++ {
+		cookies.SetCookie(c, cookieChunkName(i), "", time.Now().Add(-time.Hour))
+	}
+}
+
+func splitCookieValue(value string) []string {
+	chunks := make([]string, 0, len(value)/cookieChunkSize+1)
+	for len(value) > cookieChunkSize {
+		chunks = append(chunks, value[:cookieChunkSize])
+		value = value[cookieChunkSize:]
+	}
+	return append(chunks, value)
+}
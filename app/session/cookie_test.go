@@ -0,0 +1,239 @@
+package session
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// saveCookieSession saves the session with storage and returns the cookies
+// written to the response.
+func saveCookieSession(t *testing.T, storage *CookieStorage, sessionData Session) map[string]string {
+	app := fiber.New()
+	ctx := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(ctx)
+
+	if err := storage.Save(ctx, sessionData); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+	return responseCookies(ctx)
+}
+
+// responseCookies returns the cookies written to the response of ctx.
+func responseCookies(ctx *fiber.Ctx) map[string]string {
+	result := make(map[string]string)
+	ctx.Response().Header.VisitAllCookie(func(key, value []byte) {
+		cookie := fasthttp.AcquireCookie()
+		defer fasthttp.ReleaseCookie(cookie)
+		cookie.ParseBytes(value)
+		result[string(key)] = string(cookie.Value())
+	})
+	return result
+}
+
+// loadCookieSession loads the session from the given request cookies.
+func loadCookieSession(storage *CookieStorage, requestCookies map[string]string) (Session, error) {
+	app := fiber.New()
+	ctx := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(ctx)
+
+	for name, value := range requestCookies {
+		ctx.Request().Header.SetCookie(name, value)
+	}
+
+	return storage.Load(ctx)
+}
+
+func newCookieSession(data map[string]interface{}) Session {
+	return Session{ID: "cookie_id", Data: data, Expires: time.Now().Add(time.Hour)}
+}
+
+func TestCookieStorage_SaveLoad(t *testing.T) {
+	storage, err := NewCookieStorage("secret", nil, 0)
+	if err != nil {
+		t.Fatalf("Failed to create cookie storage: %v", err)
+	}
+
+	saved := saveCookieSession(t, storage, newCookieSession(map[string]interface{}{"key": "value"}))
+	if len(saved) != 1 {
+		t.Fatalf("Expected a single cookie, got %d", len(saved))
+	}
+	if strings.Contains(saved[CookieName], "value") {
+		t.Error("Expected the session cookie to be encrypted")
+	}
+
+	loaded, err := loadCookieSession(storage, saved)
+	if err != nil {
+		t.Fatalf("Failed to load session: %v", err)
+	}
+	if loaded.ID != "cookie_id" || loaded.Data["key"] != "value" {
+		t.Errorf("Unexpected session: %+v", loaded)
+	}
+}
+
+func TestCookieStorage_Chunking(t *testing.T) {
+	storage, _ := NewCookieStorage("secret", nil, 4)
+
+	large := strings.Repeat("a", 2*cookieChunkSize)
+	saved := saveCookieSession(t, storage, newCookieSession(map[string]interface{}{"large": large}))
+	if len(saved) < 3 {
+		t.Fatalf("Expected the session to be split across cookies, got %d", len(saved))
+	}
+	for name, value := range saved {
+		if len(value) > cookieChunkSize+4 {
+			t.Errorf("Cookie %s is too large: %d bytes", name, len(value))
+		}
+	}
+
+	loaded, err := loadCookieSession(storage, saved)
+	if err != nil {
+		t.Fatalf("Failed to load chunked session: %v", err)
+	}
+	if loaded.Data["large"] != large {
+		t.Error("Chunked session data does not match")
+	}
+
+	delete(saved, cookieChunkName(1))
+	if _, err := loadCookieSession(storage, saved); err == nil {
+		t.Error("Expected an error for a missing chunk")
+	}
+}
+
+func TestCookieStorage_TooLarge(t *testing.T) {
+	storage, _ := NewCookieStorage("secret", nil, 1)
+
+	app := fiber.New()
+	ctx := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(ctx)
+
+	sessionData := newCookieSession(map[string]interface{}{"large": strings.Repeat("a", 2*cookieChunkSize)})
+	if err := storage.Save(ctx, sessionData); !errors.Is(err, ErrSessionTooLarge) {
+		t.Errorf("Expected ErrSessionTooLarge, got %v", err)
+	}
+}
+
+func TestCookieStorage_KeyRotation(t *testing.T) {
+	oldStorage, _ := NewCookieStorage("old-secret", nil, 0)
+	saved := saveCookieSession(t, oldStorage, newCookieSession(map[string]interface{}{"key": "value"}))
+
+	rotated, _ := NewCookieStorage("new-secret", []string{"old-secret"}, 0)
+	if _, err := loadCookieSession(rotated, saved); err != nil {
+		t.Errorf("Expected a cookie sealed with a previous key to be accepted: %v", err)
+	}
+
+	other, _ := NewCookieStorage("new-secret", nil, 0)
+	if _, err := loadCookieSession(other, saved); err == nil {
+		t.Error("Expected a cookie sealed with an unknown key to be rejected")
+	}
+}
+
+func TestCookieStorage_Tampered(t *testing.T) {
+	storage, _ := NewCookieStorage("secret", nil, 0)
+	saved := saveCookieSession(t, storage, newCookieSession(map[string]interface{}{"key": "value"}))
+
+	value := []byte(saved[CookieName])
+	last := len(value) - 1
+	if value[last] == 'A' {
+		value[last] = 'B'
+	} else {
+		value[last] = 'A'
+	}
+	saved[CookieName] = string(value)
+
+	if _, err := loadCookieSession(storage, saved); err == nil {
+		t.Error("Expected a tampered cookie to be rejected")
+	}
+}
+
+func TestCookieStorage_ConcurrentRequests(t *testing.T) {
+	BeforeEach()
+
+	storage, _ := NewCookieStorage("secret", nil, 0)
+	sm = NewSessionManager(storage)
+	saved := saveCookieSession(t, storage, newCookieSession(map[string]interface{}{"key": "value"}))
+
+	// Requests sharing a cookie must not see or clear each other's session.
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			app := fiber.New()
+			ctx := app.AcquireCtx(&fasthttp.RequestCtx{})
+			defer app.ReleaseCtx(ctx)
+			for name, value := range saved {
+				ctx.Request().Header.SetCookie(name, value)
+			}
+
+			sessionData, err := FromContext(ctx)
+			if err != nil {
+				t.Errorf("Failed to load session: %v", err)
+				return
+			}
+			if sessionData.ID != "cookie_id" {
+				t.Errorf("Expected the session of the cookie, got %s", sessionData.ID)
+			}
+			if i%2 == 0 {
+				sessionData.Put("request", i)
+			}
+			if err := sm.Commit(ctx); err != nil {
+				t.Errorf("Failed to commit session: %v", err)
+				return
+			}
+
+			// Only a changed session is written back to the response
+			if i%2 != 0 {
+				if written := responseCookies(ctx); len(written) != 0 {
+					t.Errorf("Expected no cookies for an unchanged session, got %v", written)
+				}
+				return
+			}
+			loaded, err := loadCookieSession(storage, responseCookies(ctx))
+			if err != nil {
+				t.Errorf("Expected the session cookie to be kept: %v", err)
+				return
+			}
+			if loaded.ID != "cookie_id" || loaded.Data["key"] != "value" {
+				t.Errorf("Unexpected session: %+v", loaded)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestCookieStorage_ReadOnlyRequest(t *testing.T) {
+	BeforeEach()
+
+	storage, _ := NewCookieStorage("secret", nil, 0)
+	sm = NewSessionManager(storage)
+	saved := saveCookieSession(t, storage, newCookieSession(map[string]interface{}{"key": "value"}))
+
+	app := fiber.New()
+	ctx := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(ctx)
+	for name, value := range saved {
+		ctx.Request().Header.SetCookie(name, value)
+	}
+
+	sessionData, err := FromContext(ctx)
+	if err != nil {
+		t.Fatalf("Failed to load session: %v", err)
+	}
+	if sessionData.Get("key") != "value" {
+		t.Errorf("Unexpected session value: %v", sessionData.Get("key"))
+	}
+	if err := sm.Commit(ctx); err != nil {
+		t.Fatalf("Failed to commit session: %v", err)
+	}
+
+	// Reading the session does not reissue its cookies
+	if written := string(ctx.Response().Header.Peek(fiber.HeaderSetCookie)); written != "" {
+		t.Errorf("Expected no Set-Cookie header, got %q", written)
+	}
+}
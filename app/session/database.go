@@ -0,0 +1,149 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/mousav1/weiser/app/models"
+	"gorm.io/gorm"
+)
+
+// UserSessionStorage is implemented by storages that can list and revoke the
+// sessions of a user.
+type UserSessionStorage interface {
+	UserSessions(userID uint) ([]Session, error)
+	RevokeUserSessions(userID uint, except ...string) error
+}
+
+// DatabaseStorage implementation for database session storage
+type DatabaseStorage struct {
+	db *gorm.DB
+}
+
+// NewDatabaseStorage initializes database storage and migrates the sessions table
+func NewDatabaseStorage(db *gorm.DB) (*DatabaseStorage, error) {
+	if db == nil {
+		return nil, errors.New("database connection is not initialized")
+	}
+	if err := db.AutoMigrate(&models.Session{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate sessions table: %w", err)
+	}
+	return &DatabaseStorage{db: db}, nil
+}
+
+func (ds *DatabaseStorage) Set(key string, value Session) error {
+	payload, err := json.Marshal(value.Data)
+	if err != nil {
+		return err
+	}
+
+	record := models.Session{
+		ID:           key,
+		UserID:       userIDFromData(value.Data),
+		IPAddress:    value.IPAddress,
+		UserAgent:    value.UserAgent,
+		Payload:      string(payload),
+		LastActivity: time.Now(),
+		ExpiresAt:    value.Expires,
+	}
+	return ds.db.Save(&record).Error
+}
+
+// Get returns the session with the given ID. Expired rows are not returned.
+func (ds *DatabaseStorage) Get(key string) (Session, error) {
+	var record models.Session
+	err := ds.db.Where("id = ? AND expires_at > ?", key, time.Now()).Take(&record).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return Session{}, errors.New("session not found")
+		}
+		return Session{}, err
+	}
+	return recordToSession(record)
+}
+
+func (ds *DatabaseStorage) Delete(key string) error {
+	return ds.db.Delete(&models.Session{}, "id = ?", key).Error
+}
+
+// GetSessionIDs returns the IDs of the sessions that have not expired.
+func (ds *DatabaseStorage) GetSessionIDs() ([]string, error) {
+	var sessionIDs []string
+	err := ds.db.Model(&models.Session{}).Where("expires_at > ?", time.Now()).Pluck("id", &sessionIDs).Error
+	if err != nil {
+		return []string{}, err
+	}
+	return sessionIDs, nil
+}
+
+// Prune deletes the expired sessions.
+func (ds *DatabaseStorage) Prune() error {
+	return ds.db.Delete(&models.Session{}, "expires_at <= ?", time.Now()).Error
+}
+
+// UserSessions returns the active sessions of a user, most recent first.
+func (ds *DatabaseStorage) UserSessions(userID uint) ([]Session, error) {
+	var records []models.Session
+	err := ds.db.Where("user_id = ? AND expires_at > ?", userID, time.Now()).
+		Order("last_activity desc").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]Session, 0, len(records))
+	for _, record := range records {
+		sessionData, err := recordToSession(record)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, sessionData)
+	}
+	return sessions, nil
+}
+
+// RevokeUserSessions deletes the sessions of a user except the given ones,
+// e.g. to log a user out of all other devices.
+func (ds *DatabaseStorage) RevokeUserSessions(userID uint, except ...string) error {
+	query := ds.db.Where("user_id = ?", userID)
+	if len(except) > 0 {
+		query = query.Where("id NOT IN ?", except)
+	}
+	return query.Delete(&models.Session{}).Error
+}
+
+func recordToSession(record models.Session) (Session, error) {
+	data := make(map[string]interface{})
+	if record.Payload != "" {
+		if err := json.Unmarshal([]byte(record.Payload), &data); err != nil {
+			return Session{}, err
+		}
+	}
+
+	return Session{
+		ID:           record.ID,
+		Data:         data,
+		Expires:      record.ExpiresAt,
+		IPAddress:    record.IPAddress,
+		UserAgent:    record.UserAgent,
+		LastActivity: record.LastActivity,
+	}, nil
+}
+
+// userIDFromData returns the authenticated user stored in the session data.
+func userIDFromData(data map[string]interface{}) *uint {
+	value, ok := data[AuthUserKey]
+	if !ok || value == nil {
+		return nil
+	}
+
+	id, err := strconv.ParseUint(fmt.Sprint(value), 10, 64)
+	if err != nil {
+		return nil
+	}
+	userID := uint(id)
+	return &userID
+}
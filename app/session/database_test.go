@@ -0,0 +1,109 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestDatabaseStorage(t *testing.T) *DatabaseStorage {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+
+	storage, err := NewDatabaseStorage(db)
+	if err != nil {
+		t.Fatalf("Failed to create database storage: %v", err)
+	}
+	return storage
+}
+
+func TestDatabaseStorage_Set_Get_Delete(t *testing.T) {
+	storage := newTestDatabaseStorage(t)
+
+	session := Session{
+		ID:        "session_id",
+		Data:      map[string]interface{}{"key": "value", AuthUserKey: 7},
+		Expires:   time.Now().Add(time.Hour),
+		IPAddress: "127.0.0.1",
+		UserAgent: "test-agent",
+	}
+	if err := storage.Set(session.ID, session); err != nil {
+		t.Fatalf("Failed to store session: %v", err)
+	}
+
+	stored, err := storage.Get(session.ID)
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+	if stored.Data["key"] != "value" || stored.IPAddress != "127.0.0.1" || stored.UserAgent != "test-agent" {
+		t.Errorf("Unexpected session: %+v", stored)
+	}
+
+	if err := storage.Delete(session.ID); err != nil {
+		t.Fatalf("Failed to delete session: %v", err)
+	}
+	if _, err := storage.Get(session.ID); err == nil {
+		t.Error("Expected deleted session to be missing")
+	}
+}
+
+func TestDatabaseStorage_Expiration(t *testing.T) {
+	storage := newTestDatabaseStorage(t)
+
+	storage.Set("active", Session{Data: map[string]interface{}{}, Expires: time.Now().Add(time.Hour)})
+	storage.Set("expired", Session{Data: map[string]interface{}{}, Expires: time.Now().Add(-time.Hour)})
+
+	if _, err := storage.Get("expired"); err == nil {
+		t.Error("Expected expired session to be missing")
+	}
+
+	sessionIDs, err := storage.GetSessionIDs()
+	if err != nil {
+		t.Fatalf("Failed to get session IDs: %v", err)
+	}
+	if len(sessionIDs) != 1 || sessionIDs[0] != "active" {
+		t.Errorf("Expected only the active session, got %v", sessionIDs)
+	}
+
+	if err := storage.Prune(); err != nil {
+		t.Fatalf("Failed to prune sessions: %v", err)
+	}
+	var count int64
+	storage.db.Table("sessions").Count(&count)
+	if count != 1 {
+		t.Errorf("Expected 1 session after pruning, got %d", count)
+	}
+}
+
+func TestDatabaseStorage_UserSessions(t *testing.T) {
+	storage := newTestDatabaseStorage(t)
+	sm := NewSessionManager(storage)
+
+	for _, id := range []string{"phone", "laptop"} {
+		storage.Set(id, Session{Data: map[string]interface{}{AuthUserKey: 7}, Expires: time.Now().Add(time.Hour)})
+	}
+	storage.Set("other", Session{Data: map[string]interface{}{AuthUserKey: 8}, Expires: time.Now().Add(time.Hour)})
+
+	sessions, err := sm.UserSessions(7)
+	if err != nil {
+		t.Fatalf("Failed to list user sessions: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Errorf("Expected 2 sessions, got %d", len(sessions))
+	}
+
+	if err := sm.RevokeUserSessions(7, "laptop"); err != nil {
+		t.Fatalf("Failed to revoke user sessions: %v", err)
+	}
+	sessions, _ = sm.UserSessions(7)
+	if len(sessions) != 1 || sessions[0].ID != "laptop" {
+		t.Errorf("Expected only the laptop session to remain, got %v", sessions)
+	}
+	if _, err := storage.Get("other"); err != nil {
+		t.Error("Expected sessions of other users to be kept")
+	}
+}
@@ -0,0 +1,182 @@
+package session
+
+import "fmt"
+
+// Reserved session keys used to track flash data.
+const (
+	flashNewKey   = "_flash.new"
+	flashOldKey   = "_flash.old"
+	oldInputKey   = "_old_input"
+	FlashErrorKey = "errors"
+)
+
+// Flash stores a value that is available during the next request only.
+func (s *Session) Flash(key string, value interface{}) {
+	s.Data[key] = value
+	s.Data[flashNewKey] = appendUnique(stringSlice(s.Data[flashNewKey]), key)
+	s.Data[flashOldKey] = remove(stringSlice(s.Data[flashOldKey]), key)
+	s.dirty = true
+}
+
+// Reflash keeps all flash data for an additional request.
+func (s *Session) Reflash() {
+	keys := stringSlice(s.Data[flashNewKey])
+	for _, key := range stringSlice(s.Data[flashOldKey]) {
+		keys = appendUnique(keys, key)
+	}
+	s.Data[flashNewKey] = keys
+	s.Data[flashOldKey] = []string{}
+	s.dirty = true
+}
+
+// Keep keeps the given flash keys for an additional request.
+func (s *Session) Keep(keys ...string) {
+	newKeys := stringSlice(s.Data[flashNewKey])
+	oldKeys := stringSlice(s.Data[flashOldKey])
+	for _, key := range keys {
+		newKeys = appendUnique(newKeys, key)
+		oldKeys = remove(oldKeys, key)
+	}
+	s.Data[flashNewKey] = newKeys
+	s.Data[flashOldKey] = oldKeys
+	s.dirty = true
+}
+
+// AgeFlashData removes the flash data of the previous request and marks the
+// data flashed during this request for removal after the next one.
+func (s *Session) AgeFlashData() {
+	for _, key := range stringSlice(s.Data[flashOldKey]) {
+		delete(s.Data, key)
+	}
+	s.Data[flashOldKey] = stringSlice(s.Data[flashNewKey])
+	s.Data[flashNewKey] = []string{}
+	s.dirty = true
+}
+
+// HasFlashData reports whether the session holds flash data to age.
+func (s *Session) HasFlashData() bool {
+	return len(stringSlice(s.Data[flashNewKey])) > 0 || len(stringSlice(s.Data[flashOldKey])) > 0
+}
+
+// FlashData returns the flashed values, keyed by name.
+func (s *Session) FlashData() map[string]interface{} {
+	data := make(map[string]interface{})
+	for _, key := range append(stringSlice(s.Data[flashOldKey]), stringSlice(s.Data[flashNewKey])...) {
+		if key == oldInputKey {
+			continue
+		}
+		if value, ok := s.Data[key]; ok {
+			data[key] = value
+		}
+	}
+	return data
+}
+
+// FlashInput flashes the request input so that a form can be re-filled on
+// the next request.
+func (s *Session) FlashInput(input map[string]interface{}) {
+	s.Flash(oldInputKey, input)
+}
+
+// OldInput returns the input flashed by the previous request.
+func (s *Session) OldInput() map[string]interface{} {
+	input, ok := s.Data[oldInputKey].(map[string]interface{})
+	if !ok {
+		return map[string]interface{}{}
+	}
+	return input
+}
+
+// stringSlice converts a stored key list back to []string. Lists read from
+// the file and Redis stores are decoded as []interface{}.
+func stringSlice(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			result = append(result, fmt.Sprint(item))
+		}
+		return result
+	default:
+		return []string{}
+	}
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+func remove(values []string, value string) []string {
+	result := make([]string, 0, len(values))
+	for _, existing := range values {
+		if existing != value {
+			result = append(result, existing)
+		}
+	}
+	return result
+}
+
+// Flash stores a value in the session that is available during the next request only.
+func (sm *SessionManager) Flash(key string, value interface{}, sessionID string) error {
+	return sm.update(sessionID, func(s *Session) {
+		s.Flash(key, value)
+	})
+}
+
+// Reflash keeps all flash data of the session for an additional request.
+func (sm *SessionManager) Reflash(sessionID string) error {
+	return sm.update(sessionID, func(s *Session) {
+		s.Reflash()
+	})
+}
+
+// Keep keeps the given flash keys of the session for an additional request.
+func (sm *SessionManager) Keep(sessionID string, keys ...string) error {
+	return sm.update(sessionID, func(s *Session) {
+		s.Keep(keys...)
+	})
+}
+
+// FlashInput flashes the request input into the session.
+func (sm *SessionManager) FlashInput(input map[string]interface{}, sessionID string) error {
+	return sm.update(sessionID, func(s *Session) {
+		s.FlashInput(input)
+	})
+}
+
+// AgeFlashData ages the flash data of the session. It is called once at the
+// end of every request by the session middleware.
+func (sm *SessionManager) AgeFlashData(sessionID string) error {
+	sessionData, err := sm.GetDataBySessionID(sessionID)
+	if err != nil {
+		return err
+	}
+	if !sessionData.HasFlashData() {
+		return nil
+	}
+
+	sessionData.AgeFlashData()
+	return sm.storage.Set(sessionID, *sessionData)
+}
+
+// update applies fn to the session and stores the result.
+func (sm *SessionManager) update(sessionID string, fn func(s *Session)) error {
+	if err := sm.CheckExpiration(sessionID); err != nil {
+		return err
+	}
+
+	sessionData, err := sm.GetDataBySessionID(sessionID)
+	if err != nil {
+		return err
+	}
+
+	fn(sessionData)
+	return sm.storage.Set(sessionID, *sessionData)
+}
@@ -0,0 +1,137 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultLockTimeout = 10 * time.Second
+	lockRetryInterval  = 20 * time.Millisecond
+	redisLockPrefix    = "session_lock:"
+)
+
+// ErrLockTimeout is returned when a session lock could not be acquired in time.
+var ErrLockTimeout = errors.New("timed out waiting for session lock")
+
+// Locker serializes the requests of a session, so that parallel requests do
+// not overwrite each other's writes.
+type Locker interface {
+	Lock(sessionID string, timeout time.Duration) (unlock func(), err error)
+}
+
+// localLocker locks sessions within the current process.
+type localLocker struct {
+	mutex sync.Mutex
+	locks map[string]*sessionLock
+}
+
+type sessionLock struct {
+	ch   chan struct{}
+	refs int
+}
+
+func newLocalLocker() *localLocker {
+	return &localLocker{locks: make(map[string]*sessionLock)}
+}
+
+func (l *localLocker) Lock(sessionID string, timeout time.Duration) (func(), error) {
+	l.mutex.Lock()
+	lock, ok := l.locks[sessionID]
+	if !ok {
+		lock = &sessionLock{ch: make(chan struct{}, 1)}
+		l.locks[sessionID] = lock
+	}
+	lock.refs++
+	l.mutex.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case lock.ch <- struct{}{}:
+		return func() {
+			<-lock.ch
+			l.release(sessionID, lock)
+		}, nil
+	case <-timer.C:
+		l.release(sessionID, lock)
+		return nil, ErrLockTimeout
+	}
+}
+
+// release drops a reference to the lock and forgets it once unused.
+func (l *localLocker) release(sessionID string, lock *sessionLock) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	lock.refs--
+	if lock.refs == 0 {
+		delete(l.locks, sessionID)
+	}
+}
+
+// unlockScript deletes the lock only if it is still held by the same owner.
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Lock locks the session across all processes sharing the Redis server. The
+// lock expires after timeout in case the holder dies.
+func (rs *RedisStorage) Lock(sessionID string, timeout time.Duration) (func(), error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	owner := hex.EncodeToString(b)
+	key := redisLockPrefix + sessionID
+	ctx := context.Background()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		ok, err := rs.client.SetNX(ctx, key, owner, timeout).Result()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return func() {
+				unlockScript.Run(ctx, rs.client, []string{key}, owner)
+			}, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, ErrLockTimeout
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// EnableLocking makes Lock serialize the requests of a session. Requests
+// wait up to timeout for the lock.
+func (sm *SessionManager) EnableLocking(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = defaultLockTimeout
+	}
+	sm.lockTimeout = timeout
+	sm.locker, _ = sm.storage.(Locker)
+	if sm.locker == nil {
+		sm.locker = newLocalLocker()
+	}
+}
+
+// Lock locks the session until the returned function is called. It does
+// nothing unless locking is enabled.
+func (sm *SessionManager) Lock(sessionID string) (func(), error) {
+	if sm.locker == nil {
+		return func() {}, nil
+	}
+	return sm.locker.Lock(sessionID, sm.lockTimeout)
+}
@@ -0,0 +1,98 @@
+package session
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestLocalLocker(t *testing.T) {
+	locker := newLocalLocker()
+
+	unlock, err := locker.Lock("session_id", time.Second)
+	if err != nil {
+		t.Fatalf("Failed to lock session: %v", err)
+	}
+
+	if _, err := locker.Lock("session_id", 20*time.Millisecond); !errors.Is(err, ErrLockTimeout) {
+		t.Errorf("Expected ErrLockTimeout while the session is locked, got %v", err)
+	}
+
+	otherUnlock, err := locker.Lock("other_id", time.Second)
+	if err != nil {
+		t.Errorf("Expected other sessions not to be blocked: %v", err)
+	} else {
+		otherUnlock()
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		unlock, err := locker.Lock("session_id", time.Second)
+		if err == nil {
+			unlock()
+		}
+		close(acquired)
+	}()
+
+	unlock()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the lock to be acquired after unlock")
+	}
+
+	if len(locker.locks) != 0 {
+		t.Errorf("Expected unused locks to be released, got %d", len(locker.locks))
+	}
+}
+
+func TestRedisStorage_Lock(t *testing.T) {
+	server := miniredis.RunT(t)
+	storage := &RedisStorage{client: redis.NewClient(&redis.Options{Addr: server.Addr()})}
+
+	unlock, err := storage.Lock("session_id", time.Second)
+	if err != nil {
+		t.Fatalf("Failed to lock session: %v", err)
+	}
+
+	if _, err := storage.Lock("session_id", 50*time.Millisecond); !errors.Is(err, ErrLockTimeout) {
+		t.Errorf("Expected ErrLockTimeout while the session is locked, got %v", err)
+	}
+
+	storage.Set("session_id", Session{ID: "session_id"})
+	sessionIDs, _ := storage.GetSessionIDs()
+	if len(sessionIDs) != 1 || sessionIDs[0] != "session_id" {
+		t.Errorf("Expected lock keys to be excluded from session IDs, got %v", sessionIDs)
+	}
+
+	unlock()
+	unlock, err = storage.Lock("session_id", time.Second)
+	if err != nil {
+		t.Fatalf("Expected the lock to be free after unlock: %v", err)
+	}
+	unlock()
+}
+
+func TestSessionManager_Lock(t *testing.T) {
+	sm := NewSessionManager(NewInMemoryStorage())
+
+	unlock, err := sm.Lock("session_id")
+	if err != nil {
+		t.Fatalf("Expected a no-op lock when locking is disabled: %v", err)
+	}
+	unlock()
+
+	sm.EnableLocking(20 * time.Millisecond)
+	unlock, err = sm.Lock("session_id")
+	if err != nil {
+		t.Fatalf("Failed to lock session: %v", err)
+	}
+	defer unlock()
+
+	if _, err := sm.Lock("session_id"); !errors.Is(err, ErrLockTimeout) {
+		t.Errorf("Expected ErrLockTimeout, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package session
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/mousav1/weiser/app/cookies"
+)
+
+// Reserved session keys used for authentication and CSRF protection.
+const (
+	CookieName   = "weiser_session"
+	CSRFTokenKey = "_token"
+	AuthUserKey  = "_auth_user"
+)
+
+// Token returns the CSRF token of the session.
+func (s *Session) Token() string {
+	token, _ := s.Data[CSRFTokenKey].(string)
+	return token
+}
+
+// RegenerateToken replaces the CSRF token of the session.
+func (s *Session) RegenerateToken() error {
+	token, err := generateSessionID()
+	if err != nil {
+		return err
+	}
+	s.Data[CSRFTokenKey] = token
+	s.dirty = true
+	return nil
+}
+
+// CurrentSessionID returns the ID of the session of the request.
+func CurrentSessionID(c *fiber.Ctx) (string, error) {
+	if sessionData := Loaded(c); sessionData != nil {
+		return sessionData.ID, nil
+	}
+	return GetSessionManager().Load(c)
+}
+
+// Regenerate moves the data of the current session to a new session ID and
+// rotates its CSRF token. The old session is removed when destroyOld is true.
+// It should be called whenever the authentication state changes to prevent
+// session fixation.
+func (sm *SessionManager) Regenerate(c *fiber.Ctx, destroyOld bool) (*Session, error) {
+	sessionData, err := sm.current(c)
+	if err != nil {
+		return nil, err
+	}
+	oldID := sessionData.ID
+
+	newID, err := generateSessionID()
+	if err != nil {
+		return nil, err
+	}
+	if err := sessionData.RegenerateToken(); err != nil {
+		return nil, err
+	}
+	sessionData.ID = newID
+
+	// A cookie can only hold a single session, so the old one cannot be kept.
+	if _, ok := sm.storage.(RequestStorage); ok || destroyOld {
+		if err := sm.storage.Delete(oldID); err != nil {
+			return nil, err
+		}
+	}
+
+	cookies.SetCookie(c, CookieName, newID, sessionData.Expires)
+	return sessionData, nil
+}
+
+// Invalidate removes all data of the current session and starts a new
+// session with a new ID and CSRF token.
+func (sm *SessionManager) Invalidate(c *fiber.Ctx) (*Session, error) {
+	if oldID, err := CurrentSessionID(c); err == nil {
+		if err := sm.storage.Delete(oldID); err != nil {
+			return nil, err
+		}
+	}
+
+	sessionData, err := sm.start(c)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Locals(localsKey, sessionData)
+	return sessionData, nil
+}
+
+// Login regenerates the session and stores the authenticated user in it.
+func (sm *SessionManager) Login(c *fiber.Ctx, userID interface{}) (*Session, error) {
+	sessionData, err := sm.Regenerate(c, true)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionData.Put(AuthUserKey, userID)
+	return sessionData, nil
+}
+
+// Logout invalidates the session of the authenticated user.
+func (sm *SessionManager) Logout(c *fiber.Ctx) error {
+	_, err := sm.Invalidate(c)
+	return err
+}
+
+// current returns the session of the request, loading it if needed.
+func (sm *SessionManager) current(c *fiber.Ctx) (*Session, error) {
+	if sessionData := Loaded(c); sessionData != nil {
+		return sessionData, nil
+	}
+
+	sessionData, err := sm.load(c)
+	if err != nil {
+		return nil, err
+	}
+	c.Locals(localsKey, sessionData)
+	return sessionData, nil
+}
+
+// Token returns the CSRF token of the session.
+func (sm *SessionManager) Token(sessionID string) string {
+	sessionData, err := sm.GetDataBySessionID(sessionID)
+	if err != nil {
+		return ""
+	}
+	return sessionData.Token()
+}
@@ -0,0 +1,147 @@
+package session
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mousav1/weiser/app/cookies"
+	"github.com/spf13/viper"
+)
+
+// Keys of the request locals holding the session of the request.
+const (
+	localsKey       = "sessionData"
+	localsUnlockKey = "sessionUnlock"
+)
+
+// Get returns a session value.
+func (s *Session) Get(key string) interface{} {
+	return s.Data[key]
+}
+
+// Put sets a session value.
+func (s *Session) Put(key string, value interface{}) {
+	s.Data[key] = value
+	s.dirty = true
+}
+
+// Forget removes a session value.
+func (s *Session) Forget(key string) {
+	if _, ok := s.Data[key]; ok {
+		delete(s.Data, key)
+		s.dirty = true
+	}
+}
+
+// IsDirty reports whether the session changed since it was loaded.
+func (s *Session) IsDirty() bool {
+	return s.dirty
+}
+
+// FromContext returns the session of the request. The session is loaded from
+// storage the first time it is used during a request; later calls return the
+// same in-memory session, which SessionMiddleware writes back once the
+// handler returns.
+func FromContext(c *fiber.Ctx) (*Session, error) {
+	manager := GetSessionManager()
+	if manager == nil {
+		return nil, errors.New("session manager is not initialized")
+	}
+	return manager.current(c)
+}
+
+// Loaded returns the session of the request if it was used, or nil.
+func Loaded(c *fiber.Ctx) *Session {
+	sessionData, _ := c.Locals(localsKey).(*Session)
+	return sessionData
+}
+
+// load reads the session of the request, starting a new one if the request
+// has no valid session.
+func (sm *SessionManager) load(c *fiber.Ctx) (*Session, error) {
+	// Sessions kept in the request belong to that request alone, so they
+	// need neither a lock nor a lookup in shared storage.
+	if storage, ok := sm.storage.(RequestStorage); ok {
+		stored, err := storage.Load(c)
+		if err != nil || !stored.IsValid() {
+			return sm.start(c)
+		}
+		return &stored, nil
+	}
+
+	sessionID, err := sm.Load(c)
+	if err != nil {
+		return sm.start(c)
+	}
+
+	unlock, err := sm.Lock(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	c.Locals(localsUnlockKey, unlock)
+
+	stored, err := sm.storage.Get(sessionID)
+	if err != nil {
+		return sm.start(c)
+	}
+	if !stored.IsValid() {
+		if err := sm.storage.Delete(sessionID); err != nil {
+			return nil, err
+		}
+		return sm.start(c)
+	}
+
+	if stored.Data == nil {
+		stored.Data = make(map[string]interface{})
+	}
+	return &stored, nil
+}
+
+// start creates a new session that is stored when the request ends.
+func (sm *SessionManager) start(c *fiber.Ctx) (*Session, error) {
+	sessionData, err := newSession(c)
+	if err != nil {
+		return nil, err
+	}
+	sessionData.dirty = true
+	return sessionData, nil
+}
+
+// Commit ages the flash data of the session of the request and stores the
+// session if it changed. Requests that never used the session do not touch
+// the storage at all.
+func (sm *SessionManager) Commit(c *fiber.Ctx) error {
+	defer release(c)
+
+	sessionData := Loaded(c)
+	if sessionData == nil {
+		return nil
+	}
+
+	if sessionData.HasFlashData() {
+		sessionData.AgeFlashData()
+	}
+
+	if sessionData.dirty {
+		sessionData.Expires = time.Now().Add(viper.GetDuration("session.expirationTime"))
+		if _, ok := sm.storage.(RequestStorage); !ok {
+			if err := sm.storage.Set(sessionData.ID, *sessionData); err != nil {
+				return err
+			}
+			cookies.SetCookie(c, CookieName, sessionData.ID, sessionData.Expires)
+		}
+		sessionData.dirty = false
+		return sm.Save(c, sessionData)
+	}
+
+	return nil
+}
+
+// release unlocks the session of the request.
+func release(c *fiber.Ctx) {
+	if unlock, ok := c.Locals(localsUnlockKey).(func()); ok {
+		unlock()
+		c.Locals(localsUnlockKey, nil)
+	}
+}
@@ -0,0 +1,114 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// countingStorage counts the calls made to the wrapped storage.
+type countingStorage struct {
+	Storage
+	gets, sets int
+}
+
+func (cs *countingStorage) Get(key string) (Session, error) {
+	cs.gets++
+	return cs.Storage.Get(key)
+}
+
+func (cs *countingStorage) Set(key string, value Session) error {
+	cs.sets++
+	return cs.Storage.Set(key, value)
+}
+
+func newRequestContext(sessionID string) *fiber.Ctx {
+	ctx := fiber.New().AcquireCtx(&fasthttp.RequestCtx{})
+	if sessionID != "" {
+		ctx.Request().Header.SetCookie(CookieName, sessionID)
+	}
+	return ctx
+}
+
+func TestFromContext_LazyLoading(t *testing.T) {
+	BeforeEach()
+
+	storage := &countingStorage{Storage: NewInMemoryStorage()}
+	storage.Storage.Set("session_id", Session{
+		ID:      "session_id",
+		Data:    map[string]interface{}{"key": "value"},
+		Expires: time.Now().Add(time.Hour),
+	})
+	sm = NewSessionManager(storage)
+
+	// A request that never uses the session does not touch the storage
+	ctx := newRequestContext("session_id")
+	if err := sm.Commit(ctx); err != nil {
+		t.Fatalf("Failed to commit session: %v", err)
+	}
+	if storage.gets != 0 || storage.sets != 0 {
+		t.Errorf("Expected no storage access, got %d gets and %d sets", storage.gets, storage.sets)
+	}
+
+	// Reading the session loads it once and does not write it back
+	ctx = newRequestContext("session_id")
+	for i := 0; i < 3; i++ {
+		sessionData, err := FromContext(ctx)
+		if err != nil {
+			t.Fatalf("Failed to load session: %v", err)
+		}
+		if sessionData.Get("key") != "value" {
+			t.Errorf("Unexpected session value: %v", sessionData.Get("key"))
+		}
+	}
+	sm.Commit(ctx)
+	if storage.gets != 1 || storage.sets != 0 {
+		t.Errorf("Expected 1 get and no sets, got %d gets and %d sets", storage.gets, storage.sets)
+	}
+
+	// Changes are written back once at the end of the request
+	ctx = newRequestContext("session_id")
+	sessionData, _ := FromContext(ctx)
+	sessionData.Put("key", "changed")
+	sessionData.Put("other", "value")
+	sessionData.Forget("missing")
+	sm.Commit(ctx)
+	if storage.sets != 1 {
+		t.Errorf("Expected a single write, got %d", storage.sets)
+	}
+
+	stored, _ := storage.Storage.Get("session_id")
+	if stored.Data["key"] != "changed" || stored.Data["other"] != "value" {
+		t.Errorf("Unexpected stored session: %v", stored.Data)
+	}
+}
+
+func TestFromContext_StartsNewSession(t *testing.T) {
+	BeforeEach()
+
+	storage := NewInMemoryStorage()
+	storage.Set("expired_id", Session{ID: "expired_id", Data: map[string]interface{}{}, Expires: time.Now().Add(-time.Hour)})
+	sm = NewSessionManager(storage)
+
+	for _, sessionID := range []string{"", "unknown_id", "expired_id"} {
+		ctx := newRequestContext(sessionID)
+		sessionData, err := FromContext(ctx)
+		if err != nil {
+			t.Fatalf("Failed to start session: %v", err)
+		}
+		if sessionData.ID == sessionID || sessionData.Token() == "" {
+			t.Errorf("Expected a new session with a CSRF token for %q", sessionID)
+		}
+
+		sm.Commit(ctx)
+		if _, err := storage.Get(sessionData.ID); err != nil {
+			t.Errorf("Expected the new session to be stored: %v", err)
+		}
+	}
+
+	if _, err := storage.Get("expired_id"); err == nil {
+		t.Error("Expected the expired session to be removed")
+	}
+}
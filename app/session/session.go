@@ -6,8 +6,12 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -18,13 +22,22 @@ import (
 )
 
 type SessionManager struct {
-	storage Storage
+	storage     Storage
+	locker      Locker
+	lockTimeout time.Duration
 }
 
 type Session struct {
-	ID      string
-	Data    map[string]interface{}
-	Expires time.Time
+	ID        string
+	Data      map[string]interface{}
+	Expires   time.Time
+	IPAddress string
+	UserAgent string
+	// LastActivity is only tracked by the database storage
+	LastActivity time.Time
+
+	// dirty reports whether the session changed since it was loaded
+	dirty bool
 }
 
 var sm *SessionManager
@@ -39,10 +52,17 @@ func InitSessionManager() error {
 	case "redis":
 		storage, err = NewRedisStorage()
 	case "file":
-		filePath := viper.GetString("session.file.path")
-		storage, err = NewFileStorage(filePath)
+		storage, err = NewFileStorage(viper.GetString("session.file.path"))
 	case "memory":
-		storage = &InMemoryStorage{sessions: make(map[string]Session)}
+		storage = NewInMemoryStorage()
+	case "database":
+		storage, err = NewDatabaseStorage(database.DB)
+	case "cookie":
+		storage, err = NewCookieStorage(
+			viper.GetString("session.key"),
+			viper.GetStringSlice("session.previous_keys"),
+			viper.GetInt("session.cookie.max_chunks"),
+		)
 	default:
 		return errors.New("invalid session storage type")
 	}
@@ -52,6 +72,9 @@ func InitSessionManager() error {
 	}
 
 	sm = NewSessionManager(storage)
+	if viper.GetBool("session.lock.enabled") {
+		sm.EnableLocking(viper.GetDuration("session.lock.timeout"))
+	}
 	return nil
 }
 
@@ -77,28 +100,60 @@ func NewSessionManager(storage Storage) *SessionManager {
 
 // StartSession creates a new session and sets a cookie for it
 func (sm *SessionManager) StartSession(c *fiber.Ctx) *Session {
-	sessionID, err := generateSessionID()
+	sessionData, err := newSession(c)
 	if err != nil {
-		log.Println("Failed to generate session ID:", err)
+		log.Println("Failed to start session:", err)
+		return nil
+	}
+
+	if err := sm.storage.Set(sessionData.ID, *sessionData); err != nil {
+		log.Println("Failed to store session:", err)
 		return nil
 	}
 
-	expirationTime := viper.GetDuration("session.expirationTime")
-	expiration := time.Now().Add(expirationTime)
+	return sessionData
+}
 
-	sessionData := Session{
-		ID:      sessionID,
-		Data:    make(map[string]interface{}),
-		Expires: expiration,
+// newSession creates a new session with a CSRF token and sets a cookie for
+// it, without storing it
+func newSession(c *fiber.Ctx) (*Session, error) {
+	sessionID, err := generateSessionID()
+	if err != nil {
+		return nil, err
 	}
 
-	if err := sm.storage.Set(sessionID, sessionData); err != nil {
-		log.Println("Failed to store session:", err)
-		return nil
+	expiration := time.Now().Add(viper.GetDuration("session.expirationTime"))
+	sessionData := &Session{
+		ID:        sessionID,
+		Data:      make(map[string]interface{}),
+		Expires:   expiration,
+		IPAddress: c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}
+	if err := sessionData.RegenerateToken(); err != nil {
+		return nil, err
+	}
+
+	cookies.SetCookie(c, CookieName, sessionID, expiration)
+	return sessionData, nil
+}
+
+// Load returns the ID of the session of the request
+func (sm *SessionManager) Load(c *fiber.Ctx) (string, error) {
+	if storage, ok := sm.storage.(RequestStorage); ok {
+		sessionData, err := storage.Load(c)
+		return sessionData.ID, err
 	}
+	return cookies.GetCookie(c, CookieName)
+}
 
-	cookies.SetCookie(c, "weiser_session", sessionID, expiration)
-	return &sessionData
+// Save writes the session back to the response for storages that keep the
+// session in the request
+func (sm *SessionManager) Save(c *fiber.Ctx, sessionData *Session) error {
+	if storage, ok := sm.storage.(RequestStorage); ok {
+		return storage.Save(c, *sessionData)
+	}
+	return nil
 }
 
 // Set updates a session value
@@ -196,6 +251,34 @@ func (sm *SessionManager) UpdateExpiration(sessionID string, expiration time.Dur
 	return sm.storage.Set(sessionID, *sessionData)
 }
 
+// UserSessions returns the active sessions of a user
+func (sm *SessionManager) UserSessions(userID uint) ([]Session, error) {
+	storage, ok := sm.storage.(UserSessionStorage)
+	if !ok {
+		return nil, errors.New("session storage does not track users")
+	}
+	return storage.UserSessions(userID)
+}
+
+// RevokeUserSessions removes the sessions of a user except the given ones
+func (sm *SessionManager) RevokeUserSessions(userID uint, except ...string) error {
+	storage, ok := sm.storage.(UserSessionStorage)
+	if !ok {
+		return errors.New("session storage does not track users")
+	}
+	return storage.RevokeUserSessions(userID, except...)
+}
+
+// Prune removes expired sessions from storages that can do so without
+// loading every session. It reports whether the storage supports pruning.
+func (sm *SessionManager) Prune() (bool, error) {
+	storage, ok := sm.storage.(interface{ Prune() error })
+	if !ok {
+		return false, nil
+	}
+	return true, storage.Prune()
+}
+
 // IsValid checks if a session is valid
 func (s *Session) IsValid() bool {
 	return time.Now().Before(s.Expires)
@@ -228,15 +311,25 @@ type Storage interface {
 
 // InMemoryStorage implementation for in-memory session storage
 type InMemoryStorage struct {
+	mutex    sync.RWMutex
 	sessions map[string]Session
 }
 
+// NewInMemoryStorage initializes in-memory storage
+func NewInMemoryStorage() *InMemoryStorage {
+	return &InMemoryStorage{sessions: make(map[string]Session)}
+}
+
 func (ims *InMemoryStorage) Set(key string, value Session) error {
+	ims.mutex.Lock()
+	defer ims.mutex.Unlock()
 	ims.sessions[key] = value
 	return nil
 }
 
 func (ims *InMemoryStorage) Get(key string) (Session, error) {
+	ims.mutex.RLock()
+	defer ims.mutex.RUnlock()
 	session, ok := ims.sessions[key]
 	if !ok {
 		return Session{}, errors.New("session not found")
@@ -245,11 +338,15 @@ func (ims *InMemoryStorage) Get(key string) (Session, error) {
 }
 
 func (ims *InMemoryStorage) Delete(key string) error {
+	ims.mutex.Lock()
+	defer ims.mutex.Unlock()
 	delete(ims.sessions, key)
 	return nil
 }
 
 func (ims *InMemoryStorage) GetSessionIDs() ([]string, error) {
+	ims.mutex.RLock()
+	defer ims.mutex.RUnlock()
 	sessionIDs := make([]string, 0, len(ims.sessions))
 	for sessionID := range ims.sessions {
 		sessionIDs = append(sessionIDs, sessionID)
@@ -288,132 +385,141 @@ func (rs *RedisStorage) Delete(key string) error {
 }
 
 func (rs *RedisStorage) GetSessionIDs() ([]string, error) {
-	sessionIDs, err := rs.client.Keys(context.Background(), "*").Result()
+	keys, err := rs.client.Keys(context.Background(), "*").Result()
 	if err != nil {
 		return []string{}, err
 	}
+
+	sessionIDs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if !strings.HasPrefix(key, redisLockPrefix) {
+			sessionIDs = append(sessionIDs, key)
+		}
+	}
 	return sessionIDs, nil
 }
 
-// FileStorage implementation for file-based session storage
+// FileStorage implementation for file-based session storage. Every session
+// is kept in its own file, which is replaced atomically on every write.
 type FileStorage struct {
-	filePath string
+	dir string
 }
 
-func NewFileStorage(filePath string) (*FileStorage, error) {
-	_, err := os.Stat(filePath)
-	if err != nil && os.IsNotExist(err) {
-		if _, err := os.Create(filePath); err != nil {
-			return nil, err
-		}
+// NewFileStorage initializes file storage in the given directory
+func NewFileStorage(dir string) (*FileStorage, error) {
+	if fi, err := os.Stat(dir); err == nil && !fi.IsDir() {
+		return nil, fmt.Errorf("session file path %s must be a directory", dir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
 	}
 
-	return &FileStorage{filePath: filePath}, nil
+	return &FileStorage{dir: dir}, nil
 }
 
 func (fs *FileStorage) Set(key string, value Session) error {
-	sessions, err := fs.readSessions()
+	path, err := fs.path(key)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(value)
 	if err != nil {
 		return err
 	}
 
-	sessions[key] = value
-	return fs.writeSessions(sessions)
+	// Write to a temporary file first so that readers never see a partially
+	// written session.
+	tmp, err := os.CreateTemp(fs.dir, ".tmp-"+key+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
 }
 
 func (fs *FileStorage) Get(key string) (Session, error) {
-	sessions, err := fs.readSessions()
+	path, err := fs.path(key)
 	if err != nil {
 		return Session{}, err
 	}
 
-	sessionData, ok := sessions[key]
-	if !ok {
-		return Session{}, errors.New("session not found")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Session{}, errors.New("session not found")
+		}
+		return Session{}, err
 	}
 
+	var sessionData Session
+	if err := json.Unmarshal(data, &sessionData); err != nil {
+		return Session{}, err
+	}
 	return sessionData, nil
 }
 
 func (fs *FileStorage) Delete(key string) error {
-	sessions, err := fs.readSessions()
+	path, err := fs.path(key)
 	if err != nil {
 		return err
 	}
 
-	delete(sessions, key)
-	return fs.writeSessions(sessions)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
 }
 
 func (fs *FileStorage) GetSessionIDs() ([]string, error) {
-	sessions, err := fs.readSessions()
+	entries, err := os.ReadDir(fs.dir)
 	if err != nil {
 		return nil, err
 	}
 
-	sessionIDs := make([]string, 0, len(sessions))
-	for sessionID := range sessions {
-		sessionIDs = append(sessionIDs, sessionID)
+	sessionIDs := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != sessionFileExt {
+			continue
+		}
+		sessionIDs = append(sessionIDs, strings.TrimSuffix(name, sessionFileExt))
 	}
 	return sessionIDs, nil
 }
 
-func (fs *FileStorage) readSessions() (map[string]Session, error) {
-	defaultSessions := map[string]Session{"empty": {}}
-	defaultBytes, _ := json.Marshal(defaultSessions)
-
-	fi, err := os.Stat(fs.filePath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			file, err := os.Create(fs.filePath)
-			if err != nil {
-				return nil, err
-			}
-			defer file.Close()
-
-			if _, err := file.Write(defaultBytes); err != nil {
-				return nil, err
-			}
-
-			return defaultSessions, nil
-		} else {
-			return nil, err
-		}
-	}
-	if fi.Size() == 0 {
-		return defaultSessions, nil
-	}
-
-	file, err := os.OpenFile(fs.filePath, os.O_RDONLY, 0644)
-	if err != nil {
-		return nil, err
+// path returns the file of the session, rejecting IDs that could escape the
+// session directory.
+func (fs *FileStorage) path(key string) (string, error) {
+	if !validSessionID(key) {
+		return "", errors.New("invalid session ID")
 	}
-	defer file.Close()
-
-	decoder := json.NewDecoder(file)
-	sessions := make(map[string]Session)
-	if err = decoder.Decode(&sessions); err != nil {
-		return nil, err
-	}
-
-	return sessions, nil
+	return filepath.Join(fs.dir, key+sessionFileExt), nil
 }
 
-func (fs *FileStorage) writeSessions(sessions map[string]Session) error {
-	file, err := os.OpenFile(fs.filePath, os.O_WRONLY|os.O_TRUNC, 0644)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
+const sessionFileExt = ".json"
 
-	encoder := json.NewEncoder(file)
-	if err = encoder.Encode(sessions); err != nil {
-		return err
+func validSessionID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
 	}
-
-	if err = file.Sync(); err != nil {
-		return err
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
 	}
-
-	return nil
+	return true
 }
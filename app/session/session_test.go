@@ -2,8 +2,11 @@ package session
 
 import (
 	"errors"
+	"fmt"
 	"log"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/spf13/viper"
@@ -13,7 +16,7 @@ import (
 func BeforeEach() {
 	// Perform initial setup for tests
 	viper.SetConfigFile("../../config/config.yaml")
-	viper.Set("session.file.path", "../../storage/logs/logs.txt")
+	viper.Set("session.file.path", "../../storage/sessions")
 	if err := viper.ReadInConfig(); err != nil {
 		log.Fatalf("failed to read configuration file: %s", err)
 	}
@@ -21,18 +24,18 @@ func BeforeEach() {
 
 // MockStorage implements the Storage interface for testing purposes
 type MockStorage struct {
-	data map[string]map[string]interface{}
+	data map[string]Session
 }
 
-func (ms *MockStorage) Set(key string, value map[string]interface{}) error {
+func (ms *MockStorage) Set(key string, value Session) error {
 	ms.data[key] = value
 	return nil
 }
 
-func (ms *MockStorage) Get(key string) (map[string]interface{}, error) {
+func (ms *MockStorage) Get(key string) (Session, error) {
 	value, ok := ms.data[key]
 	if !ok {
-		return nil, errors.New("key not found")
+		return Session{}, errors.New("key not found")
 	}
 	return value, nil
 }
@@ -54,7 +57,7 @@ func TestSessionManager_StartSession(t *testing.T) {
 	BeforeEach()
 
 	// Initialize the session manager with the mock storage
-	storage := &MockStorage{data: make(map[string]map[string]interface{})}
+	storage := &MockStorage{data: make(map[string]Session)}
 	sm := NewSessionManager(storage)
 
 	// Create a mock Fiber context
@@ -78,7 +81,7 @@ func TestSessionManager_StartSession(t *testing.T) {
 	if err != nil {
 		t.Errorf("Failed to get session data: %v", err)
 	}
-	if sessionData == nil {
+	if sessionData.Data == nil {
 		t.Error("Session data not stored")
 	}
 }
@@ -87,7 +90,7 @@ func TestSessionManager_Set_Get_Delete(t *testing.T) {
 	BeforeEach()
 
 	// Initialize the session manager with the mock storage
-	storage := &MockStorage{data: make(map[string]map[string]interface{})}
+	storage := &MockStorage{data: make(map[string]Session)}
 	sm := NewSessionManager(storage)
 
 	// Create a mock session
@@ -97,9 +100,10 @@ func TestSessionManager_Set_Get_Delete(t *testing.T) {
 			"key1": "value1",
 			"key2": "value2",
 		},
+		Expires: time.Now().Add(time.Hour),
 	}
 
-	storage.data[session.ID] = session.Data
+	storage.data[session.ID] = *session
 
 	// Set a value in the session
 	_, err := sm.Set("key3", "value3", session.ID)
@@ -157,3 +161,201 @@ func TestInitSessionManager(t *testing.T) {
 		t.Error("Session manager not initialized")
 	}
 }
+
+func TestSession_FlashData(t *testing.T) {
+	storage := &MockStorage{data: make(map[string]Session)}
+	sm := NewSessionManager(storage)
+
+	session := Session{ID: "flash_id", Data: map[string]interface{}{}, Expires: time.Now().Add(time.Hour)}
+	storage.data[session.ID] = session
+
+	if err := sm.Flash("status", "saved", session.ID); err != nil {
+		t.Fatalf("Failed to flash value: %v", err)
+	}
+
+	// The flashed value survives the end of the current request
+	sm.AgeFlashData(session.ID)
+	if value := sm.Get("status", session.ID); value != "saved" {
+		t.Errorf("Expected flashed value on the next request, got %v", value)
+	}
+
+	// and is removed at the end of the next one
+	sm.AgeFlashData(session.ID)
+	if value := sm.Get("status", session.ID); value != nil {
+		t.Errorf("Expected flashed value to be removed, got %v", value)
+	}
+}
+
+func TestSession_KeepAndReflash(t *testing.T) {
+	session := &Session{Data: map[string]interface{}{}}
+	session.Flash("status", "saved")
+	session.Flash("notice", "hello")
+	session.AgeFlashData()
+
+	session.Keep("status")
+	session.AgeFlashData()
+	if _, ok := session.Data["status"]; !ok {
+		t.Error("Expected kept flash value to survive another request")
+	}
+	if _, ok := session.Data["notice"]; ok {
+		t.Error("Expected flash value that was not kept to be removed")
+	}
+
+	session.Reflash()
+	session.AgeFlashData()
+	if _, ok := session.Data["status"]; !ok {
+		t.Error("Expected reflashed value to survive another request")
+	}
+}
+
+func TestSession_OldInput(t *testing.T) {
+	session := &Session{Data: map[string]interface{}{}}
+	session.FlashInput(map[string]interface{}{"email": "user@example.com"})
+	session.AgeFlashData()
+
+	if session.OldInput()["email"] != "user@example.com" {
+		t.Error("Expected old input to be available on the next request")
+	}
+	if _, ok := session.FlashData()[oldInputKey]; ok {
+		t.Error("Old input should not be listed as flash data")
+	}
+
+	session.AgeFlashData()
+	if len(session.OldInput()) != 0 {
+		t.Error("Expected old input to be removed")
+	}
+}
+
+func TestSessionManager_Regenerate(t *testing.T) {
+	BeforeEach()
+
+	storage := &MockStorage{data: make(map[string]Session)}
+	sm := NewSessionManager(storage)
+	ctx := fiber.New().AcquireCtx(&fasthttp.RequestCtx{})
+
+	session := sm.StartSession(ctx)
+	session.Data["key"] = "value"
+	storage.data[session.ID] = *session
+	ctx.Locals("sessionData", session)
+	oldID, oldToken := session.ID, session.Token()
+
+	regenerated, err := sm.Regenerate(ctx, true)
+	if err != nil {
+		t.Fatalf("Failed to regenerate session: %v", err)
+	}
+	if err := sm.Commit(ctx); err != nil {
+		t.Fatalf("Failed to commit session: %v", err)
+	}
+
+	if regenerated.ID == oldID {
+		t.Error("Expected a new session ID")
+	}
+	if regenerated.Token() == "" || regenerated.Token() == oldToken {
+		t.Error("Expected the CSRF token to be rotated")
+	}
+	if sm.Get("key", regenerated.ID) != "value" {
+		t.Error("Expected session data to be kept")
+	}
+	if _, ok := storage.data[oldID]; ok {
+		t.Error("Expected the old session to be destroyed")
+	}
+}
+
+func TestSessionManager_LoginLogout(t *testing.T) {
+	BeforeEach()
+
+	storage := &MockStorage{data: make(map[string]Session)}
+	sm := NewSessionManager(storage)
+	ctx := fiber.New().AcquireCtx(&fasthttp.RequestCtx{})
+
+	session := sm.StartSession(ctx)
+	ctx.Locals("sessionData", session)
+	guestID := session.ID
+
+	loggedIn, err := sm.Login(ctx, 42)
+	if err != nil {
+		t.Fatalf("Failed to log in: %v", err)
+	}
+	sm.Commit(ctx)
+	if loggedIn.ID == guestID {
+		t.Error("Expected login to regenerate the session ID")
+	}
+	if sm.Get(AuthUserKey, loggedIn.ID) != 42 {
+		t.Error("Expected the user to be stored in the session")
+	}
+
+	if err := sm.Logout(ctx); err != nil {
+		t.Fatalf("Failed to log out: %v", err)
+	}
+	if _, ok := storage.data[loggedIn.ID]; ok {
+		t.Error("Expected logout to destroy the session")
+	}
+	current := ctx.Locals("sessionData").(*Session)
+	if current.ID == loggedIn.ID || current.Data[AuthUserKey] != nil {
+		t.Error("Expected logout to start a new empty session")
+	}
+}
+
+func TestFileStorage(t *testing.T) {
+	storage, err := NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create file storage: %v", err)
+	}
+
+	session := Session{ID: "session_id", Data: map[string]interface{}{"key": "value"}, Expires: time.Now().Add(time.Hour)}
+	if err := storage.Set(session.ID, session); err != nil {
+		t.Fatalf("Failed to store session: %v", err)
+	}
+
+	stored, err := storage.Get(session.ID)
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+	if stored.Data["key"] != "value" {
+		t.Errorf("Unexpected session data: %v", stored.Data)
+	}
+
+	sessionIDs, _ := storage.GetSessionIDs()
+	if len(sessionIDs) != 1 || sessionIDs[0] != session.ID {
+		t.Errorf("Unexpected session IDs: %v", sessionIDs)
+	}
+
+	if err := storage.Delete(session.ID); err != nil {
+		t.Fatalf("Failed to delete session: %v", err)
+	}
+	if _, err := storage.Get(session.ID); err == nil {
+		t.Error("Expected deleted session to be missing")
+	}
+
+	if err := storage.Set("../escape", session); err == nil {
+		t.Error("Expected an invalid session ID to be rejected")
+	}
+}
+
+func TestStorage_Concurrent(t *testing.T) {
+	fileStorage, _ := NewFileStorage(t.TempDir())
+	storages := map[string]Storage{
+		"memory": NewInMemoryStorage(),
+		"file":   fileStorage,
+	}
+
+	for name, storage := range storages {
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				id := fmt.Sprintf("session_%d", i%5)
+				storage.Set(id, Session{ID: id, Data: map[string]interface{}{"n": i}})
+				storage.Get(id)
+				storage.GetSessionIDs()
+			}(i)
+		}
+		wg.Wait()
+
+		sessionIDs, err := storage.GetSessionIDs()
+		if err != nil || len(sessionIDs) != 5 {
+			t.Errorf("%s: expected 5 sessions, got %v (%v)", name, sessionIDs, err)
+		}
+	}
+}
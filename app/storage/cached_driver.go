@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/mousav1/weiser/app/cache"
+)
+
+// defaultMetadataCacheTTL مدت نگهداری اطلاعات فایل‌ها در cache وقتی ttl مشخص نشده باشد
+const defaultMetadataCacheTTL = 10 * time.Minute
+
+// CachedDriver اطلاعات فایل‌ها (وجود، اندازه، metadata، سطح دسترسی) و فهرست دایرکتوری‌ها را در cache نگه می‌دارد
+// تا درخواست‌های تکراری به دیسک‌های کند مانند S3 و SFTP ارسال نشوند. محتوای فایل‌ها cache نمی‌شود.
+//
+// هر عملیات نوشتن شماره نسل cache را تغییر می‌دهد و بدین ترتیب تمام مقادیر قبلی باطل می‌شوند؛ اگر cache
+// بین چند سرور مشترک باشد (مثلا redis) نوشتن روی یک سرور cache بقیه را نیز باطل می‌کند.
+type CachedDriver struct {
+	disk  Storage
+	cache *cache.Cache
+	name  string
+	ttl   time.Duration
+}
+
+// NewCachedDriver دیسک را با cache می‌پوشاند. name کلیدهای این دیسک را از بقیه دیسک‌هایی که از همان cache
+// استفاده می‌کنند جدا می‌کند.
+func NewCachedDriver(disk Storage, store *cache.Cache, name string, ttl time.Duration) (*CachedDriver, error) {
+	if store == nil {
+		return nil, fmt.Errorf("cached driver requires a cache store")
+	}
+	if ttl <= 0 {
+		ttl = defaultMetadataCacheTTL
+	}
+	return &CachedDriver{disk: disk, cache: store, name: name, ttl: ttl}, nil
+}
+
+func (cd *CachedDriver) generationKey() string {
+	return "storage:" + cd.name + ":generation"
+}
+
+// generation شماره نسل فعلی cache را برمی‌گرداند و در صورت نبودن آن نسل جدیدی می‌سازد
+func (cd *CachedDriver) generation() string {
+	var generation string
+	if err := cd.cache.Get(cd.generationKey(), &generation); err == nil && generation != "" {
+		return generation
+	}
+	return cd.invalidate()
+}
+
+// invalidate با ساختن نسل جدید تمام مقادیر cache شده این دیسک را باطل می‌کند
+func (cd *CachedDriver) invalidate() string {
+	generation := strconv.FormatInt(time.Now().UnixNano(), 36)
+	cd.cache.Set(cd.generationKey(), generation, cd.ttl)
+	return generation
+}
+
+// cached مقدار را از cache می‌خواند یا در صورت نبودن آن را از دیسک می‌گیرد و ذخیره می‌کند.
+// خطاهای cache نادیده گرفته می‌شوند تا از دسترس خارج شدن cache دیسک را از کار نیندازد.
+func cached[T any](cd *CachedDriver, kind string, path string, load func() (T, error)) (T, error) {
+	key := "storage:" + cd.name + ":" + cd.generation() + ":" + kind + ":" + path
+
+	var value T
+	if err := cd.cache.Get(key, &value); err == nil {
+		return value, nil
+	}
+
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+	cd.cache.Set(key, value, cd.ttl)
+	return value, nil
+}
+
+// write عملیات نوشتن را اجرا می‌کند و cache را باطل می‌کند، حتی اگر عملیات با خطا تمام شود
+func (cd *CachedDriver) write(err error) error {
+	cd.invalidate()
+	return err
+}
+
+func (cd *CachedDriver) Put(path string, content io.ReadSeeker) error {
+	return cd.write(cd.disk.Put(path, content))
+}
+
+func (cd *CachedDriver) PutStream(path string, content io.Reader) error {
+	return cd.write(cd.disk.PutStream(path, content))
+}
+
+func (cd *CachedDriver) Get(path string) (io.Reader, error) {
+	return cd.disk.Get(path)
+}
+
+func (cd *CachedDriver) ReadStream(path string) (io.ReadCloser, error) {
+	return cd.disk.ReadStream(path)
+}
+
+func (cd *CachedDriver) ReadRange(path string, offset int64, length int64) (io.ReadCloser, error) {
+	return ReadRange(cd.disk, path, offset, length)
+}
+
+func (cd *CachedDriver) Delete(path string) error {
+	return cd.write(cd.disk.Delete(path))
+}
+
+func (cd *CachedDriver) Exists(path string) (bool, error) {
+	return cached(cd, "exists", path, func() (bool, error) { return cd.disk.Exists(path) })
+}
+
+func (cd *CachedDriver) List(directory string) ([]string, error) {
+	return cached(cd, "list", directory, func() ([]string, error) { return cd.disk.List(directory) })
+}
+
+func (cd *CachedDriver) Files(directory string, recursive bool) ([]string, error) {
+	kind := "files"
+	if recursive {
+		kind = "files-recursive"
+	}
+	return cached(cd, kind, directory, func() ([]string, error) { return cd.disk.Files(directory, recursive) })
+}
+
+func (cd *CachedDriver) Directories(directory string) ([]string, error) {
+	return cached(cd, "directories", directory, func() ([]string, error) { return cd.disk.Directories(directory) })
+}
+
+// ListContents صفحه‌ها cache نمی‌شوند چون cursor برخی درایورها (مانند S3) عمر محدودی دارد
+func (cd *CachedDriver) ListContents(directory string, recursive bool, cursor string, limit int) (Page, error) {
+	return cd.disk.ListContents(directory, recursive, cursor, limit)
+}
+
+func (cd *CachedDriver) Missing(path string) (bool, error) {
+	exists, err := cd.Exists(path)
+	if err != nil {
+		return false, err
+	}
+	return !exists, nil
+}
+
+func (cd *CachedDriver) Download(path string) (io.Reader, error) {
+	return cd.disk.Download(path)
+}
+
+func (cd *CachedDriver) URL(path string) (string, error) {
+	return cd.disk.URL(path)
+}
+
+func (cd *CachedDriver) TemporaryURL(path string, expiresIn int64) (string, error) {
+	return cd.disk.TemporaryURL(path, expiresIn)
+}
+
+func (cd *CachedDriver) Size(path string) (int64, error) {
+	return cached(cd, "size", path, func() (int64, error) { return cd.disk.Size(path) })
+}
+
+func (cd *CachedDriver) Metadata(path string) (Metadata, error) {
+	return cached(cd, "metadata", path, func() (Metadata, error) { return cd.disk.Metadata(path) })
+}
+
+func (cd *CachedDriver) Stat(path string) (Metadata, error) {
+	return cached(cd, "stat", path, func() (Metadata, error) { return Stat(cd.disk, path) })
+}
+
+func (cd *CachedDriver) SetVisibility(path string, visibility Visibility) error {
+	return cd.write(cd.disk.SetVisibility(path, visibility))
+}
+
+func (cd *CachedDriver) Visibility(path string) (Visibility, error) {
+	return cached(cd, "visibility", path, func() (Visibility, error) { return cd.disk.Visibility(path) })
+}
+
+func (cd *CachedDriver) Copy(sourcePath string, destinationPath string) error {
+	return cd.write(cd.disk.Copy(sourcePath, destinationPath))
+}
+
+func (cd *CachedDriver) Move(sourcePath string, destinationPath string) error {
+	return cd.write(cd.disk.Move(sourcePath, destinationPath))
+}
+
+func (cd *CachedDriver) MakeDirectory(path string) error {
+	return cd.write(cd.disk.MakeDirectory(path))
+}
+
+func (cd *CachedDriver) DeleteDirectory(path string) error {
+	return cd.write(cd.disk.DeleteDirectory(path))
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mousav1/weiser/app/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingDriver تعداد درخواست‌های خواندن اطلاعات از دیسک را می‌شمارد
+type countingDriver struct {
+	*MemoryDriver
+	calls int
+}
+
+func (c *countingDriver) Exists(path string) (bool, error) {
+	c.calls++
+	return c.MemoryDriver.Exists(path)
+}
+
+func (c *countingDriver) List(directory string) ([]string, error) {
+	c.calls++
+	return c.MemoryDriver.List(directory)
+}
+
+func (c *countingDriver) Metadata(path string) (Metadata, error) {
+	c.calls++
+	return c.MemoryDriver.Metadata(path)
+}
+
+func TestCachedDriver(t *testing.T) {
+	disk := &countingDriver{MemoryDriver: NewMemoryDriver()}
+	store := cache.NewCache(cache.NewInMemoryCache(nil), time.Minute)
+	cached, err := NewCachedDriver(disk, store, "s3", time.Minute)
+	assert.NoError(t, err)
+
+	assert.NoError(t, cached.Put("a.txt", strings.NewReader("hello")))
+
+	for i := 0; i < 3; i++ {
+		exists, err := cached.Exists("a.txt")
+		assert.NoError(t, err)
+		assert.True(t, exists)
+
+		meta, err := cached.Metadata("a.txt")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), meta.Size)
+
+		files, err := cached.List("")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a.txt"}, files)
+	}
+	assert.Equal(t, 3, disk.calls)
+
+	// نوشتن cache را باطل می‌کند
+	assert.NoError(t, cached.Put("b.txt", strings.NewReader("b")))
+	files, err := cached.List("")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.txt", "b.txt"}, files)
+	assert.NoError(t, cached.Delete("a.txt"))
+	exists, err := cached.Exists("a.txt")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+	assert.Equal(t, 5, disk.calls)
+
+	// خطاها cache نمی‌شوند
+	_, err = cached.Metadata("missing.txt")
+	assert.Error(t, err)
+	_, err = cached.Metadata("missing.txt")
+	assert.Error(t, err)
+	assert.Equal(t, 7, disk.calls)
+
+	// دیسک‌های دیگری که از همان cache استفاده می‌کنند تحت تاثیر قرار نمی‌گیرند
+	other, err := NewCachedDriver(NewMemoryDriver(), store, "local", 0)
+	assert.NoError(t, err)
+	exists, err = other.Exists("b.txt")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = NewCachedDriver(disk, nil, "s3", 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"io"
+)
+
+// TB بخشی از testing.TB که assertionها به آن نیاز دارند؛ بدین ترتیب بسته testing وارد برنامه اصلی نمی‌شود
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// FakeDisk یک دیسک حافظه‌ای که جایگزین دیسک واقعی شده است
+type FakeDisk struct {
+	*MemoryDriver
+
+	name     string
+	original Storage
+	hadDisk  bool
+
+	defaultName   string
+	defaultDriver Storage
+}
+
+// Fake دیسک داده شده را در Registry با یک دیسک حافظه‌ای جایگزین می‌کند. اگر این دیسک پیش‌فرض باشد
+// DefaultDriver نیز جایگزین می‌شود، بنابراین facades.Storage() هم از دیسک جعلی استفاده می‌کند.
+// پس از پایان تست با Restore دیسک اصلی برگردانده می‌شود.
+func Fake(disk string) *FakeDisk {
+	original, hadDisk := Registry[disk]
+	fake := &FakeDisk{
+		MemoryDriver: NewMemoryDriver(),
+		name:         disk,
+		original:     original,
+		hadDisk:      hadDisk,
+
+		defaultName:   DefaultDriverName,
+		defaultDriver: DefaultDriver,
+	}
+
+	RegisterDriver(disk, fake)
+	if DefaultDriverName == disk || DefaultDriver == nil {
+		DefaultDriverName = disk
+		DefaultDriver = fake
+	}
+	return fake
+}
+
+// Restore دیسک اصلی را به Registry برمی‌گرداند
+func (fd *FakeDisk) Restore() {
+	if fd.hadDisk {
+		Registry[fd.name] = fd.original
+	} else {
+		delete(Registry, fd.name)
+	}
+	if DefaultDriver == Storage(fd) {
+		DefaultDriverName = fd.defaultName
+		DefaultDriver = fd.defaultDriver
+	}
+}
+
+// AssertExists بررسی می‌کند که تمام فایل‌های داده شده وجود داشته باشند
+func (fd *FakeDisk) AssertExists(t TB, paths ...string) bool {
+	t.Helper()
+	ok := true
+	for _, path := range paths {
+		if exists, _ := fd.Exists(path); !exists {
+			t.Errorf("storage: expected %q to exist on disk %q", path, fd.name)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// AssertMissing بررسی می‌کند که هیچ‌کدام از فایل‌های داده شده وجود نداشته باشند
+func (fd *FakeDisk) AssertMissing(t TB, paths ...string) bool {
+	t.Helper()
+	ok := true
+	for _, path := range paths {
+		if exists, _ := fd.Exists(path); exists {
+			t.Errorf("storage: expected %q to be missing on disk %q", path, fd.name)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// AssertContent بررسی می‌کند که محتوای فایل برابر با مقدار مورد انتظار باشد
+func (fd *FakeDisk) AssertContent(t TB, path string, expected string) bool {
+	t.Helper()
+	reader, err := fd.Get(path)
+	if err != nil {
+		t.Errorf("storage: expected %q to exist on disk %q", path, fd.name)
+		return false
+	}
+	content, _ := io.ReadAll(reader)
+	if string(content) != expected {
+		t.Errorf("storage: content of %q on disk %q is %q, expected %q", path, fd.name, content, expected)
+		return false
+	}
+	return true
+}
+
+// AssertDirectoryEmpty بررسی می‌کند که دایرکتوری هیچ فایلی نداشته باشد
+func (fd *FakeDisk) AssertDirectoryEmpty(t TB, directory string) bool {
+	t.Helper()
+	files, _ := fd.List(directory)
+	if len(files) > 0 {
+		t.Errorf("storage: expected directory %q on disk %q to be empty, found %v", directory, fd.name, files)
+		return false
+	}
+	return true
+}
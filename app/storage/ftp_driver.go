@@ -0,0 +1,506 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jlaffaye/ftp"
+)
+
+// FTPConfig پیکربندی اتصال به سرور FTP
+type FTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// TLS اتصال را با FTPS صریح (AUTH TLS) رمزنگاری می‌کند
+	TLS bool
+	// DisableEPSV برای سرورهایی که فقط از PASV پشتیبانی می‌کنند
+	DisableEPSV bool
+	// Root دایرکتوری ریشه دیسک روی سرور
+	Root string
+	// URL آدرس عمومی فایل‌ها در صورت وجود
+	URL            string
+	Timeout        time.Duration
+	MaxConnections int
+}
+
+// FTPDriver فایل‌ها را روی یک سرور FTP نگهداری می‌کند.
+// FTP امکان تغییر مجوز فایل‌ها را ندارد، بنابراین SetVisibility و Visibility پشتیبانی نمی‌شوند.
+type FTPDriver struct {
+	config FTPConfig
+	pool   *pool[*ftp.ServerConn]
+}
+
+func NewFTPDriver(config FTPConfig) (*FTPDriver, error) {
+	if config.Host == "" {
+		return nil, fmt.Errorf("ftp host is required")
+	}
+	if config.Port == 0 {
+		config.Port = 21
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultRemoteTimeout
+	}
+
+	options := []ftp.DialOption{
+		ftp.DialWithTimeout(config.Timeout),
+		ftp.DialWithDisabledEPSV(config.DisableEPSV),
+	}
+	if config.TLS {
+		options = append(options, ftp.DialWithExplicitTLS(&tls.Config{ServerName: config.Host}))
+	}
+
+	addr := net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+	p := newPool[*ftp.ServerConn](config.MaxConnections, config.Timeout)
+	p.dial = func() (*ftp.ServerConn, error) {
+		conn, err := ftp.Dial(addr, options...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to ftp server: %w", err)
+		}
+		if err := conn.Login(config.Username, config.Password); err != nil {
+			conn.Quit()
+			return nil, fmt.Errorf("failed to login to ftp server: %w", err)
+		}
+		return conn, nil
+	}
+	p.check = func(conn *ftp.ServerConn) error {
+		return conn.NoOp()
+	}
+	p.close = func(conn *ftp.ServerConn) error {
+		return conn.Quit()
+	}
+	p.broken = ftpConnectionError
+
+	return &FTPDriver{config: config, pool: p}, nil
+}
+
+// ftpConnectionError پاسخ‌های خطای سرور (مثلا 550) اتصال را خراب نمی‌کنند
+func ftpConnectionError(err error) bool {
+	var protoErr *textproto.Error
+	return !errors.As(err, &protoErr) && !errors.Is(err, os.ErrNotExist) && !errors.Is(err, ErrPathOutsideRoot)
+}
+
+func (fd *FTPDriver) path(p string) (string, error) {
+	return remoteJoin(fd.config.Root, p)
+}
+
+func (fd *FTPDriver) root() string {
+	return path.Clean("/" + fd.config.Root)
+}
+
+// Close اتصال‌های باز را می‌بندد
+func (fd *FTPDriver) Close() error {
+	return fd.pool.Close()
+}
+
+// makeDirectories دایرکتوری و والدهای آن را می‌سازد؛ خطای دایرکتوری‌های موجود نادیده گرفته می‌شود
+func (fd *FTPDriver) makeDirectories(conn *ftp.ServerConn, dirPath string) error {
+	current := ""
+	for _, part := range strings.Split(strings.Trim(dirPath, "/"), "/") {
+		if part == "" {
+			continue
+		}
+		current += "/" + part
+		conn.MakeDir(current)
+	}
+	if current == "" {
+		return nil
+	}
+	// بررسی می‌شود که دایرکتوری واقعا ایجاد شده باشد
+	return conn.ChangeDir(current)
+}
+
+// entry اطلاعات یک فایل یا دایرکتوری را از فهرست دایرکتوری والد آن پیدا می‌کند
+func (fd *FTPDriver) entry(conn *ftp.ServerConn, fullPath string) (*ftp.Entry, error) {
+	if fullPath == "/" {
+		return &ftp.Entry{Name: "/", Type: ftp.EntryTypeFolder}, nil
+	}
+	entries, err := conn.List(path.Dir(fullPath))
+	if err != nil {
+		return nil, err
+	}
+	name := path.Base(fullPath)
+	for _, entry := range entries {
+		if entry.Name == name {
+			return entry, nil
+		}
+	}
+	return nil, &os.PathError{Op: "stat", Path: fullPath, Err: os.ErrNotExist}
+}
+
+func (fd *FTPDriver) Put(path string, content io.ReadSeeker) error {
+	return fd.PutStream(path, content)
+}
+
+func (fd *FTPDriver) PutStream(path string, content io.Reader) error {
+	filePath, err := fd.path(path)
+	if err != nil {
+		return err
+	}
+	return fd.pool.with(func(conn *ftp.ServerConn) error {
+		return fd.write(conn, filePath, content)
+	})
+}
+
+// write محتوا را در یک فایل موقت آپلود می‌کند و سپس آن را جایگزین فایل مقصد می‌کند
+func (fd *FTPDriver) write(conn *ftp.ServerConn, filePath string, content io.Reader) error {
+	dir := path.Dir(filePath)
+	if err := fd.makeDirectories(conn, dir); err != nil {
+		return err
+	}
+
+	tmpPath := path.Join(dir, fmt.Sprintf(".tmp-%s-%d", path.Base(filePath), time.Now().UnixNano()))
+	if err := conn.Stor(tmpPath, content); err != nil {
+		conn.Delete(tmpPath)
+		return err
+	}
+	if err := ftpRename(conn, tmpPath, filePath); err != nil {
+		conn.Delete(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// ftpRename برخی سرورها فایل مقصد موجود را بازنویسی نمی‌کنند، بنابراین در صورت خطا ابتدا آن را حذف می‌کند
+func ftpRename(conn *ftp.ServerConn, oldPath string, newPath string) error {
+	if err := conn.Rename(oldPath, newPath); err == nil {
+		return nil
+	}
+	conn.Delete(newPath)
+	return conn.Rename(oldPath, newPath)
+}
+
+// Get کل محتوای فایل را می‌خواند و پاسخ را می‌بندد؛ برای فایل‌های بزرگ از ReadStream استفاده کنید
+func (fd *FTPDriver) Get(path string) (io.Reader, error) {
+	stream, err := fd.ReadStream(path)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	data, err := io.ReadAll(stream)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
+// ReadStream اتصال را تا زمان بسته شدن stream در اختیار نگه می‌دارد
+func (fd *FTPDriver) ReadStream(path string) (io.ReadCloser, error) {
+	filePath, err := fd.path(path)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := fd.pool.get()
+	if err != nil {
+		return nil, err
+	}
+	response, err := conn.Retr(filePath)
+	if err != nil {
+		fd.pool.put(conn, err)
+		return nil, err
+	}
+	return &pooledReader[*ftp.ServerConn]{reader: response, pool: fd.pool, conn: conn}, nil
+}
+
+// ReadRange با دستور REST خواندن را از offset شروع می‌کند
+func (fd *FTPDriver) ReadRange(path string, offset int64, length int64) (io.ReadCloser, error) {
+	filePath, err := fd.path(path)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := fd.pool.get()
+	if err != nil {
+		return nil, err
+	}
+	response, err := conn.RetrFrom(filePath, uint64(offset))
+	if err != nil {
+		fd.pool.put(conn, err)
+		return nil, err
+	}
+	return limitStream(&pooledReader[*ftp.ServerConn]{reader: response, pool: fd.pool, conn: conn}, length), nil
+}
+
+func (fd *FTPDriver) Delete(path string) error {
+	filePath, err := fd.path(path)
+	if err != nil {
+		return err
+	}
+	return fd.pool.with(func(conn *ftp.ServerConn) error {
+		return conn.Delete(filePath)
+	})
+}
+
+func (fd *FTPDriver) stat(path string) (*ftp.Entry, error) {
+	fullPath, err := fd.path(path)
+	if err != nil {
+		return nil, err
+	}
+	var entry *ftp.Entry
+	err = fd.pool.with(func(conn *ftp.ServerConn) error {
+		entry, err = fd.entry(conn, fullPath)
+		return err
+	})
+	return entry, err
+}
+
+func (fd *FTPDriver) Exists(path string) (bool, error) {
+	_, err := fd.stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (fd *FTPDriver) List(directory string) ([]string, error) {
+	dirPath, err := fd.path(directory)
+	if err != nil {
+		return nil, err
+	}
+	var fileNames []string
+	err = fd.pool.with(func(conn *ftp.ServerConn) error {
+		entries, err := conn.List(dirPath)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if entry.Name != "." && entry.Name != ".." {
+				fileNames = append(fileNames, entry.Name)
+			}
+		}
+		return nil
+	})
+	return fileNames, err
+}
+
+func (fd *FTPDriver) Files(directory string, recursive bool) ([]string, error) {
+	return listFiles(fd, directory, recursive)
+}
+
+func (fd *FTPDriver) Directories(directory string) ([]string, error) {
+	return listDirectories(fd, directory)
+}
+
+func (fd *FTPDriver) ListContents(directory string, recursive bool, cursor string, limit int) (Page, error) {
+	dirPath, err := fd.path(directory)
+	if err != nil {
+		return Page{}, err
+	}
+	root := fd.root()
+	entry := func(p string, item *ftp.Entry) Entry {
+		return Entry{
+			Path:         relativePath(root, p),
+			IsDir:        item.Type == ftp.EntryTypeFolder,
+			Size:         int64(item.Size),
+			LastModified: item.Time,
+		}
+	}
+
+	var entries []Entry
+	err = fd.pool.with(func(conn *ftp.ServerConn) error {
+		if !recursive {
+			items, err := conn.List(dirPath)
+			if err != nil {
+				return err
+			}
+			for _, item := range items {
+				if item.Name != "." && item.Name != ".." {
+					entries = append(entries, entry(path.Join(dirPath, item.Name), item))
+				}
+			}
+			return nil
+		}
+
+		walker := conn.Walk(dirPath)
+		for walker.Next() {
+			if walker.Stat().Type == ftp.EntryTypeFile {
+				entries = append(entries, entry(walker.Path(), walker.Stat()))
+			}
+		}
+		return walker.Err()
+	})
+	if err != nil {
+		return Page{}, err
+	}
+	return paginate(entries, cursor, limit), nil
+}
+
+func (fd *FTPDriver) Missing(path string) (bool, error) {
+	exists, err := fd.Exists(path)
+	if err != nil {
+		return false, err
+	}
+	return !exists, nil
+}
+
+func (fd *FTPDriver) Download(path string) (io.Reader, error) {
+	return fd.Get(path)
+}
+
+// URL فقط زمانی که آدرس عمومی برای دیسک تنظیم شده باشد قابل استفاده است
+func (fd *FTPDriver) URL(path string) (string, error) {
+	if fd.config.URL == "" {
+		return "", ErrUnsupported
+	}
+	return strings.TrimSuffix(fd.config.URL, "/") + "/" + strings.TrimPrefix(path, "/"), nil
+}
+
+func (fd *FTPDriver) TemporaryURL(path string, expiresIn int64) (string, error) {
+	return "", ErrUnsupported
+}
+
+func (fd *FTPDriver) Size(path string) (int64, error) {
+	filePath, err := fd.path(path)
+	if err != nil {
+		return 0, err
+	}
+	var size int64
+	err = fd.pool.with(func(conn *ftp.ServerConn) error {
+		size, err = conn.FileSize(filePath)
+		return err
+	})
+	return size, err
+}
+
+func (fd *FTPDriver) Metadata(path string) (Metadata, error) {
+	entry, err := fd.stat(path)
+	if err != nil {
+		return Metadata{}, err
+	}
+	if entry.Type == ftp.EntryTypeFolder {
+		return Metadata{}, fmt.Errorf("%s: %w", path, ErrIsDirectory)
+	}
+
+	stream, err := fd.ReadStream(path)
+	if err != nil {
+		return Metadata{}, err
+	}
+	defer stream.Close()
+
+	mimeType, checksum, err := sniffAndHash(path, stream)
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	return Metadata{
+		Path:         path,
+		Size:         int64(entry.Size),
+		LastModified: entry.Time,
+		MimeType:     mimeType,
+		Checksum:     checksum,
+	}, nil
+}
+
+func (fd *FTPDriver) Stat(path string) (Metadata, error) {
+	entry, err := fd.stat(path)
+	if err != nil {
+		return Metadata{}, err
+	}
+	if entry.Type == ftp.EntryTypeFolder {
+		return Metadata{}, fmt.Errorf("%s: %w", path, ErrIsDirectory)
+	}
+	return Metadata{
+		Path:         path,
+		Size:         int64(entry.Size),
+		LastModified: entry.Time,
+		MimeType:     mimeTypeByExtension(path),
+	}, nil
+}
+
+func (fd *FTPDriver) SetVisibility(path string, visibility Visibility) error {
+	return ErrUnsupported
+}
+
+func (fd *FTPDriver) Visibility(path string) (Visibility, error) {
+	return "", ErrUnsupported
+}
+
+// Copy در FTP کپی سمت سرور وجود ندارد و هر اتصال فقط یک انتقال همزمان دارد، بنابراین فایل ابتدا
+// در یک فایل موقت محلی دانلود و سپس دوباره آپلود می‌شود
+func (fd *FTPDriver) Copy(sourcePath string, destinationPath string) error {
+	srcPath, err := fd.path(sourcePath)
+	if err != nil {
+		return err
+	}
+	dstPath, err := fd.path(destinationPath)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp("", "weiser-ftp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	return fd.pool.with(func(conn *ftp.ServerConn) error {
+		response, err := conn.Retr(srcPath)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(tmp, response)
+		if closeErr := response.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		return fd.write(conn, dstPath, tmp)
+	})
+}
+
+func (fd *FTPDriver) Move(sourcePath string, destinationPath string) error {
+	srcPath, err := fd.path(sourcePath)
+	if err != nil {
+		return err
+	}
+	dstPath, err := fd.path(destinationPath)
+	if err != nil {
+		return err
+	}
+	return fd.pool.with(func(conn *ftp.ServerConn) error {
+		if err := fd.makeDirectories(conn, path.Dir(dstPath)); err != nil {
+			return err
+		}
+		return ftpRename(conn, srcPath, dstPath)
+	})
+}
+
+func (fd *FTPDriver) MakeDirectory(path string) error {
+	dirPath, err := fd.path(path)
+	if err != nil {
+		return err
+	}
+	return fd.pool.with(func(conn *ftp.ServerConn) error {
+		return fd.makeDirectories(conn, dirPath)
+	})
+}
+
+func (fd *FTPDriver) DeleteDirectory(path string) error {
+	dirPath, err := fd.path(path)
+	if err != nil {
+		return err
+	}
+	if dirPath == fd.root() {
+		return fmt.Errorf("refusing to delete the storage root")
+	}
+	return fd.pool.with(func(conn *ftp.ServerConn) error {
+		return conn.RemoveDirRecur(dirPath)
+	})
+}
@@ -0,0 +1,200 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultPageSize تعداد پیش‌فرض مدخل‌های هر صفحه، برابر با حداکثر S3
+const defaultPageSize = 1000
+
+// Entry یک فایل یا دایرکتوری در فهرست دیسک. Path همیشه نسبت به ریشه دیسک و با / جدا شده است.
+type Entry struct {
+	Path         string    `json:"path"`
+	IsDir        bool      `json:"is_dir"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// Page یک صفحه از فهرست دیسک. اگر NextCursor خالی نباشد صفحه‌های بیشتری وجود دارد.
+type Page struct {
+	Entries    []Entry
+	NextCursor string
+}
+
+// cleanDirectory مسیر دایرکتوری را یکسان‌سازی می‌کند؛ ریشه دیسک رشته خالی است
+func cleanDirectory(directory string) string {
+	return strings.TrimPrefix(path.Clean("/"+directory), "/")
+}
+
+// relativePath مسیر کامل سرور را به مسیر نسبت به ریشه دیسک تبدیل می‌کند
+func relativePath(root string, p string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(p, strings.TrimSuffix(root, "/")), "/")
+}
+
+// paginate مدخل‌ها را به ترتیب مسیر مرتب می‌کند و صفحه بعد از cursor را برمی‌گرداند.
+// درایورهایی که صفحه‌بندی سمت سرور ندارند از آن استفاده می‌کنند و cursor آخرین مسیر صفحه قبل است.
+func paginate(entries []Entry, cursor string, limit int) Page {
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+
+	start := sort.Search(len(entries), func(i int) bool { return entries[i].Path > cursor })
+	entries = entries[start:]
+	if len(entries) <= limit {
+		return Page{Entries: entries}
+	}
+	entries = entries[:limit]
+	return Page{Entries: entries, NextCursor: entries[len(entries)-1].Path}
+}
+
+// Iterator مدخل‌های یک دایرکتوری را صفحه به صفحه از دیسک می‌خواند:
+//
+//	it := storage.NewIterator(disk, "uploads", true, 500)
+//	for it.Next() {
+//		entry := it.Entry()
+//	}
+//	if err := it.Err(); err != nil { ... }
+type Iterator struct {
+	disk      Storage
+	directory string
+	recursive bool
+	pageSize  int
+
+	cursor  string
+	entries []Entry
+	current Entry
+	started bool
+	err     error
+}
+
+// NewIterator یک Iterator می‌سازد. فهرست بازگشتی فقط شامل فایل‌ها است؛ فهرست غیربازگشتی شامل فایل‌ها و
+// زیردایرکتوری‌های مستقیم است.
+func NewIterator(disk Storage, directory string, recursive bool, pageSize int) *Iterator {
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	return &Iterator{disk: disk, directory: directory, recursive: recursive, pageSize: pageSize}
+}
+
+// Next به مدخل بعدی می‌رود و در صورت تمام شدن مدخل‌ها یا بروز خطا false برمی‌گرداند
+func (it *Iterator) Next() bool {
+	for len(it.entries) == 0 {
+		if it.err != nil || (it.started && it.cursor == "") {
+			return false
+		}
+		page, err := it.disk.ListContents(it.directory, it.recursive, it.cursor, it.pageSize)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.started = true
+		it.entries = page.Entries
+		it.cursor = page.NextCursor
+	}
+	it.current, it.entries = it.entries[0], it.entries[1:]
+	return true
+}
+
+// Entry مدخل فعلی را برمی‌گرداند
+func (it *Iterator) Entry() Entry {
+	return it.current
+}
+
+// Err خطای خواندن فهرست را در صورت وجود برمی‌گرداند
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// listFiles مسیر تمام فایل‌های دایرکتوری را با پیمایش تمام صفحه‌ها برمی‌گرداند
+func listFiles(disk Storage, directory string, recursive bool) ([]string, error) {
+	files := []string{}
+	it := NewIterator(disk, directory, recursive, defaultPageSize)
+	for it.Next() {
+		if !it.Entry().IsDir {
+			files = append(files, it.Entry().Path)
+		}
+	}
+	return files, it.Err()
+}
+
+// listDirectories مسیر زیردایرکتوری‌های مستقیم دایرکتوری را برمی‌گرداند
+func listDirectories(disk Storage, directory string) ([]string, error) {
+	directories := []string{}
+	it := NewIterator(disk, directory, false, defaultPageSize)
+	for it.Next() {
+		if it.Entry().IsDir {
+			directories = append(directories, it.Entry().Path)
+		}
+	}
+	return directories, it.Err()
+}
+
+// Glob مسیر فایل‌هایی از دیسک که با الگو مطابقت دارند را برمی‌گرداند. الگو مانند path.Match است و
+// ** با هر تعداد دایرکتوری (حتی صفر) مطابقت دارد، مثلا "reports/**/*.csv".
+func Glob(disk Storage, pattern string) ([]string, error) {
+	segments := strings.Split(strings.Trim(pattern, "/"), "/")
+	for _, segment := range segments {
+		if segment == "**" {
+			continue
+		}
+		if _, err := path.Match(segment, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+		}
+	}
+
+	// فقط زیر بخش ثابت الگو جستجو می‌شود
+	static := 0
+	for static < len(segments)-1 && !hasMeta(segments[static]) {
+		static++
+	}
+	base := strings.Join(segments[:static], "/")
+
+	files, err := listFiles(disk, base, true)
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []string{}
+	for _, file := range files {
+		if globMatch(segments, strings.Split(file, "/")) {
+			matches = append(matches, file)
+		}
+	}
+	return matches, nil
+}
+
+func hasMeta(segment string) bool {
+	return segment == "**" || strings.ContainsAny(segment, `*?[\`)
+}
+
+// globMatch بخش‌های مسیر را با بخش‌های الگو مقایسه می‌کند
+func globMatch(pattern []string, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if globMatch(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginate(t *testing.T) {
+	entries := []Entry{{Path: "c"}, {Path: "a"}, {Path: "b"}}
+
+	page := paginate(entries, "", 2)
+	assert.Equal(t, []Entry{{Path: "a"}, {Path: "b"}}, page.Entries)
+	assert.Equal(t, "b", page.NextCursor)
+
+	page = paginate(entries, page.NextCursor, 2)
+	assert.Equal(t, []Entry{{Path: "c"}}, page.Entries)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestIterator(t *testing.T) {
+	disk := NewMemoryDriver()
+	for _, name := range []string{"a.txt", "b.txt", "c/d.txt", "c/e/f.txt", "g.txt"} {
+		assert.NoError(t, disk.Put(name, strings.NewReader(name)))
+	}
+
+	var paths []string
+	it := NewIterator(disk, "", true, 2)
+	for it.Next() {
+		paths = append(paths, it.Entry().Path)
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []string{"a.txt", "b.txt", "c/d.txt", "c/e/f.txt", "g.txt"}, paths)
+
+	paths = nil
+	it = NewIterator(disk, "c", false, 1)
+	for it.Next() {
+		paths = append(paths, it.Entry().Path)
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []string{"c/d.txt", "c/e"}, paths)
+
+	it = NewIterator(disk, "missing", false, 1)
+	assert.False(t, it.Next())
+	assert.Error(t, it.Err())
+}
+
+func TestGlob(t *testing.T) {
+	disk := NewMemoryDriver()
+	for _, name := range []string{"reports/a.csv", "reports/2024/b.csv", "reports/2024/c.txt", "d.csv"} {
+		assert.NoError(t, disk.Put(name, strings.NewReader(name)))
+	}
+
+	matches, err := Glob(disk, "reports/*.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"reports/a.csv"}, matches)
+
+	matches, err = Glob(disk, "reports/**/*.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"reports/2024/b.csv", "reports/a.csv"}, matches)
+
+	matches, err = Glob(disk, "*.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"d.csv"}, matches)
+
+	matches, err = Glob(disk, "missing/*.csv")
+	assert.NoError(t, err)
+	assert.Empty(t, matches)
+
+	_, err = Glob(disk, "reports/[.csv")
+	assert.Error(t, err)
+}
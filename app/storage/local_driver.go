@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"bytes"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
@@ -12,42 +13,117 @@ import (
 	"time"
 )
 
+// LocalDriver فایل‌ها را در یک دایرکتوری روی دیسک نگهداری می‌کند. تمام مسیرها به BasePath محدود می‌شوند.
 type LocalDriver struct {
-	BasePath string
+	BasePath    string
+	Permissions Permissions
 }
 
 func NewLocalDriver(basePath string) *LocalDriver {
-	return &LocalDriver{BasePath: basePath}
+	return &LocalDriver{BasePath: basePath, Permissions: DefaultPermissions()}
+}
+
+// path مسیر کامل فایل را برمی‌گرداند و از خروج آن از BasePath جلوگیری می‌کند
+func (ld *LocalDriver) path(path string) (string, error) {
+	return SafeJoin(ld.BasePath, path)
 }
 
 func (ld *LocalDriver) Put(path string, content io.ReadSeeker) error {
-	filePath := filepath.Join(ld.BasePath, path)
-	file, err := os.Create(filePath)
+	return ld.PutStream(path, content)
+}
+
+// PutStream محتوا را ابتدا در یک فایل موقت می‌نویسد و سپس آن را جایگزین فایل مقصد می‌کند،
+// بنابراین خوانندگان هیچ‌گاه فایل نیمه‌کاره نمی‌بینند. دایرکتوری‌های والد در صورت نیاز ساخته می‌شوند.
+// فایل جایگزین شده سطح دسترسی خود را حفظ می‌کند و فایل‌های جدید عمومی هستند.
+func (ld *LocalDriver) PutStream(path string, content io.Reader) error {
+	filePath, err := ld.path(path)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-	_, err = io.Copy(file, content)
-	return err
+
+	dir := filepath.Dir(filePath)
+	if err := os.MkdirAll(dir, ld.Permissions.Dir[VisibilityPublic]); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".tmp-"+filepath.Base(filePath)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	mode := ld.Permissions.File[VisibilityPublic]
+	if fileInfo, err := os.Stat(filePath); err == nil {
+		mode = fileInfo.Mode().Perm()
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filePath)
 }
 
+// Get کل محتوای فایل را می‌خواند و فایل را می‌بندد؛ برای فایل‌های بزرگ از ReadStream استفاده کنید
 func (ld *LocalDriver) Get(path string) (io.Reader, error) {
-	filePath := filepath.Join(ld.BasePath, path)
+	filePath, err := ld.path(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
+func (ld *LocalDriver) ReadStream(path string) (io.ReadCloser, error) {
+	filePath, err := ld.path(path)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(filePath)
+}
+
+func (ld *LocalDriver) ReadRange(path string, offset int64, length int64) (io.ReadCloser, error) {
+	filePath, err := ld.path(path)
+	if err != nil {
+		return nil, err
+	}
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, err
 	}
-	return file, nil
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return limitStream(file, length), nil
 }
 
 func (ld *LocalDriver) Delete(path string) error {
-	filePath := filepath.Join(ld.BasePath, path)
+	filePath, err := ld.path(path)
+	if err != nil {
+		return err
+	}
 	return os.Remove(filePath)
 }
 
 func (ld *LocalDriver) Exists(path string) (bool, error) {
-	filePath := filepath.Join(ld.BasePath, path)
-	_, err := os.Stat(filePath)
+	filePath, err := ld.path(path)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(filePath)
 	if os.IsNotExist(err) {
 		return false, nil
 	}
@@ -55,7 +131,10 @@ func (ld *LocalDriver) Exists(path string) (bool, error) {
 }
 
 func (ld *LocalDriver) List(directory string) ([]string, error) {
-	dirPath := filepath.Join(ld.BasePath, directory)
+	dirPath, err := ld.path(directory)
+	if err != nil {
+		return nil, err
+	}
 	files, err := os.ReadDir(dirPath)
 	if err != nil {
 		return nil, err
@@ -67,6 +146,79 @@ func (ld *LocalDriver) List(directory string) ([]string, error) {
 	return fileNames, nil
 }
 
+func (ld *LocalDriver) Files(directory string, recursive bool) ([]string, error) {
+	return listFiles(ld, directory, recursive)
+}
+
+func (ld *LocalDriver) Directories(directory string) ([]string, error) {
+	return listDirectories(ld, directory)
+}
+
+// ListContents دایرکتوری را از دیسک می‌خواند و سپس صفحه درخواست شده را جدا می‌کند
+func (ld *LocalDriver) ListContents(directory string, recursive bool, cursor string, limit int) (Page, error) {
+	dirPath, err := ld.path(directory)
+	if err != nil {
+		return Page{}, err
+	}
+	root := filepath.Clean(ld.BasePath)
+
+	entry := func(p string, info os.FileInfo) (Entry, error) {
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return Entry{}, err
+		}
+		return Entry{
+			Path:         filepath.ToSlash(rel),
+			IsDir:        info.IsDir(),
+			Size:         info.Size(),
+			LastModified: info.ModTime(),
+		}, nil
+	}
+
+	var entries []Entry
+	if !recursive {
+		files, err := os.ReadDir(dirPath)
+		if err != nil {
+			return Page{}, err
+		}
+		for _, file := range files {
+			info, err := file.Info()
+			if err != nil {
+				return Page{}, err
+			}
+			e, err := entry(filepath.Join(dirPath, file.Name()), info)
+			if err != nil {
+				return Page{}, err
+			}
+			entries = append(entries, e)
+		}
+		return paginate(entries, cursor, limit), nil
+	}
+
+	err = filepath.WalkDir(dirPath, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		e, err := entry(p, info)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil {
+		return Page{}, err
+	}
+	return paginate(entries, cursor, limit), nil
+}
+
 func (ld *LocalDriver) Missing(path string) (bool, error) {
 	exists, err := ld.Exists(path)
 	if err != nil {
@@ -80,11 +232,17 @@ func (ld *LocalDriver) Download(path string) (io.Reader, error) {
 }
 
 func (ld *LocalDriver) URL(path string) (string, error) {
+	if _, err := ld.path(path); err != nil {
+		return "", err
+	}
 	return fmt.Sprintf("%s/%s", ld.BasePath, path), nil
 }
 
 func (ld *LocalDriver) TemporaryURL(path string, expiresIn int64) (string, error) {
-	filePath := filepath.Join(ld.BasePath, path)
+	filePath, err := ld.path(path)
+	if err != nil {
+		return "", err
+	}
 	fileInfo, err := os.Stat(filePath)
 	if err != nil {
 		return "", err
@@ -120,7 +278,10 @@ func (ld *LocalDriver) generateSignature(path string, expireTime time.Time, modT
 	return signature
 }
 func (ld *LocalDriver) Size(path string) (int64, error) {
-	filePath := filepath.Join(ld.BasePath, path)
+	filePath, err := ld.path(path)
+	if err != nil {
+		return 0, err
+	}
 	fileInfo, err := os.Stat(filePath)
 	if err != nil {
 		return 0, err
@@ -128,27 +289,143 @@ func (ld *LocalDriver) Size(path string) (int64, error) {
 	return fileInfo.Size(), nil
 }
 
+func (ld *LocalDriver) Metadata(path string) (Metadata, error) {
+	filePath, err := ld.path(path)
+	if err != nil {
+		return Metadata{}, err
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return Metadata{}, err
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return Metadata{}, err
+	}
+	if fileInfo.IsDir() {
+		return Metadata{}, fmt.Errorf("%s: %w", path, ErrIsDirectory)
+	}
+
+	mimeType, checksum, err := sniffAndHash(path, file)
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	return Metadata{
+		Path:         path,
+		Size:         fileInfo.Size(),
+		LastModified: fileInfo.ModTime(),
+		MimeType:     mimeType,
+		Checksum:     checksum,
+		Visibility:   visibilityOf(ld.Permissions.File, fileInfo.Mode()),
+	}, nil
+}
+
+func (ld *LocalDriver) Stat(path string) (Metadata, error) {
+	filePath, err := ld.path(path)
+	if err != nil {
+		return Metadata{}, err
+	}
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return Metadata{}, err
+	}
+	if fileInfo.IsDir() {
+		return Metadata{}, fmt.Errorf("%s: %w", path, ErrIsDirectory)
+	}
+	return Metadata{
+		Path:         path,
+		Size:         fileInfo.Size(),
+		LastModified: fileInfo.ModTime(),
+		MimeType:     mimeTypeByExtension(path),
+	}, nil
+}
+
+func (ld *LocalDriver) SetVisibility(path string, visibility Visibility) error {
+	if err := validateVisibility(visibility); err != nil {
+		return err
+	}
+	filePath, err := ld.path(path)
+	if err != nil {
+		return err
+	}
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+
+	modes := ld.Permissions.File
+	if fileInfo.IsDir() {
+		modes = ld.Permissions.Dir
+	}
+	return os.Chmod(filePath, modes[visibility])
+}
+
+func (ld *LocalDriver) Visibility(path string) (Visibility, error) {
+	filePath, err := ld.path(path)
+	if err != nil {
+		return "", err
+	}
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	modes := ld.Permissions.File
+	if fileInfo.IsDir() {
+		modes = ld.Permissions.Dir
+	}
+	return visibilityOf(modes, fileInfo.Mode()), nil
+}
+
 func (ld *LocalDriver) Copy(sourcePath string, destinationPath string) error {
-	srcPath := filepath.Join(ld.BasePath, sourcePath)
-	dstPath := filepath.Join(ld.BasePath, destinationPath)
-	input, err := os.Open(srcPath)
+	input, err := ld.ReadStream(sourcePath)
 	if err != nil {
 		return err
 	}
 	defer input.Close()
-	output, err := os.Create(dstPath)
+	return ld.PutStream(destinationPath, input)
+}
+
+func (ld *LocalDriver) Move(sourcePath string, destinationPath string) error {
+	srcPath, err := ld.path(sourcePath)
 	if err != nil {
 		return err
 	}
-	defer output.Close()
-	_, err = io.Copy(output, input)
-	return err
+	dstPath, err := ld.path(destinationPath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dstPath), ld.Permissions.Dir[VisibilityPublic]); err != nil {
+		return err
+	}
+	return os.Rename(srcPath, dstPath)
 }
 
-func (ld *LocalDriver) Move(sourcePath string, destinationPath string) error {
-	err := ld.Copy(sourcePath, destinationPath)
+func (ld *LocalDriver) MakeDirectory(path string) error {
+	dirPath, err := ld.path(path)
 	if err != nil {
 		return err
 	}
-	return ld.Delete(sourcePath)
+	return os.MkdirAll(dirPath, ld.Permissions.Dir[VisibilityPublic])
+}
+
+func (ld *LocalDriver) DeleteDirectory(path string) error {
+	dirPath, err := ld.path(path)
+	if err != nil {
+		return err
+	}
+	if dirPath == filepath.Clean(ld.BasePath) {
+		return fmt.Errorf("refusing to delete the storage root")
+	}
+	fileInfo, err := os.Stat(dirPath)
+	if err != nil {
+		return err
+	}
+	if !fileInfo.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
+	}
+	return os.RemoveAll(dirPath)
 }
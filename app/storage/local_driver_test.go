@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalDriver_Streams(t *testing.T) {
+	driver := NewLocalDriver(t.TempDir())
+
+	err := driver.PutStream("hello.txt", strings.NewReader("hello world"))
+	assert.NoError(t, err)
+
+	stream, err := driver.ReadStream("hello.txt")
+	assert.NoError(t, err)
+	content, err := io.ReadAll(stream)
+	assert.NoError(t, err)
+	assert.NoError(t, stream.Close())
+	assert.Equal(t, "hello world", string(content))
+
+	reader, err := driver.Get("hello.txt")
+	assert.NoError(t, err)
+	content, _ = io.ReadAll(reader)
+	assert.Equal(t, "hello world", string(content))
+}
+
+func TestLocalDriver_Metadata(t *testing.T) {
+	driver := NewLocalDriver(t.TempDir())
+	assert.NoError(t, driver.PutStream("hello.txt", strings.NewReader("hello world")))
+	assert.NoError(t, driver.PutStream("page", strings.NewReader("<html><body>hi</body></html>")))
+
+	meta, err := driver.Metadata("hello.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello.txt", meta.Path)
+	assert.Equal(t, int64(11), meta.Size)
+	assert.False(t, meta.LastModified.IsZero())
+	assert.Equal(t, "text/plain; charset=utf-8", meta.MimeType)
+	assert.Equal(t, "5eb63bbbe01eeed093cb22bb8f5acdc3", meta.Checksum)
+
+	// بدون پسوند، نوع فایل از محتوای آن تشخیص داده می‌شود
+	meta, err = driver.Metadata("page")
+	assert.NoError(t, err)
+	assert.Equal(t, "text/html; charset=utf-8", meta.MimeType)
+
+	_, err = driver.Metadata("missing.txt")
+	assert.Error(t, err)
+}
+
+func TestLocalDriver_Visibility(t *testing.T) {
+	driver := NewLocalDriver(t.TempDir())
+	assert.NoError(t, driver.PutStream("secret.txt", strings.NewReader("secret")))
+
+	assert.NoError(t, driver.SetVisibility("secret.txt", VisibilityPrivate))
+	visibility, err := driver.Visibility("secret.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, VisibilityPrivate, visibility)
+
+	assert.NoError(t, driver.SetVisibility("secret.txt", VisibilityPublic))
+	meta, err := driver.Metadata("secret.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, VisibilityPublic, meta.Visibility)
+
+	// بازنویسی فایل خصوصی آن را عمومی نمی‌کند
+	assert.NoError(t, driver.SetVisibility("secret.txt", VisibilityPrivate))
+	assert.NoError(t, driver.PutStream("secret.txt", strings.NewReader("new secret")))
+	visibility, err = driver.Visibility("secret.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, VisibilityPrivate, visibility)
+
+	assert.Error(t, driver.SetVisibility("secret.txt", Visibility("hidden")))
+}
+
+func TestLocalDriver_Directories(t *testing.T) {
+	driver := NewLocalDriver(t.TempDir())
+
+	assert.NoError(t, driver.MakeDirectory("avatars/thumbs"))
+	assert.NoError(t, driver.PutStream("avatars/thumbs/a.png", strings.NewReader("png")))
+
+	files, err := driver.List("avatars")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"thumbs"}, files)
+
+	assert.NoError(t, driver.DeleteDirectory("avatars"))
+	exists, err := driver.Exists("avatars")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	assert.Error(t, driver.DeleteDirectory(""))
+	assert.Error(t, driver.DeleteDirectory("missing"))
+}
+
+func TestLocalDriver_Listing(t *testing.T) {
+	driver := NewLocalDriver(t.TempDir())
+	for _, name := range []string{"a.txt", "c/d.txt", "c/e/f.txt"} {
+		assert.NoError(t, driver.Put(name, strings.NewReader(name)))
+	}
+
+	files, err := driver.Files("", false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.txt"}, files)
+
+	files, err = driver.Files("c", true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c/d.txt", "c/e/f.txt"}, files)
+
+	directories, err := driver.Directories("c")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c/e"}, directories)
+
+	_, err = driver.Files("../", true)
+	assert.True(t, errors.Is(err, ErrPathOutsideRoot))
+}
+
+func TestLocalDriver_Confinement(t *testing.T) {
+	parent := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(parent, "secret.txt"), []byte("secret"), 0644))
+
+	root := filepath.Join(parent, "disk")
+	driver := NewLocalDriver(root)
+
+	_, err := driver.Get("../secret.txt")
+	assert.ErrorIs(t, err, ErrPathOutsideRoot)
+	_, err = driver.ReadStream("nested/../../secret.txt")
+	assert.ErrorIs(t, err, ErrPathOutsideRoot)
+	_, err = driver.Exists("../secret.txt")
+	assert.ErrorIs(t, err, ErrPathOutsideRoot)
+	assert.ErrorIs(t, driver.Delete("../secret.txt"), ErrPathOutsideRoot)
+	assert.ErrorIs(t, driver.PutStream("../evil.txt", strings.NewReader("x")), ErrPathOutsideRoot)
+	assert.ErrorIs(t, driver.Copy("../secret.txt", "copy.txt"), ErrPathOutsideRoot)
+	assert.ErrorIs(t, driver.Move("a.txt", "../moved.txt"), ErrPathOutsideRoot)
+	assert.ErrorIs(t, driver.DeleteDirectory(".."), ErrPathOutsideRoot)
+
+	_, err = os.Stat(filepath.Join(parent, "evil.txt"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(parent, "secret.txt"))
+	assert.NoError(t, err)
+}
+
+func TestLocalDriver_AtomicWrites(t *testing.T) {
+	root := t.TempDir()
+	driver := NewLocalDriver(root)
+
+	// دایرکتوری‌های والد به صورت خودکار ساخته می‌شوند
+	assert.NoError(t, driver.Put("a/b/c.txt", strings.NewReader("first")))
+	assert.NoError(t, driver.Put("a/b/c.txt", strings.NewReader("second")))
+
+	content, err := os.ReadFile(filepath.Join(root, "a", "b", "c.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "second", string(content))
+
+	// فایل موقت نباید باقی بماند، حتی اگر نوشتن با خطا متوقف شود
+	assert.Error(t, driver.PutStream("a/b/d.txt", iotest.ErrReader(errors.New("broken"))))
+	files, err := driver.List("a/b")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c.txt"}, files)
+
+	assert.NoError(t, driver.Move("a/b/c.txt", "x/y/z.txt"))
+	exists, _ := driver.Exists("x/y/z.txt")
+	assert.True(t, exists)
+	missing, _ := driver.Missing("a/b/c.txt")
+	assert.True(t, missing)
+
+	assert.NoError(t, driver.Copy("x/y/z.txt", "copies/z.txt"))
+	content, err = os.ReadFile(filepath.Join(root, "copies", "z.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "second", string(content))
+}
+
+func TestLocalDriver_Permissions(t *testing.T) {
+	root := t.TempDir()
+	driver := NewLocalDriver(root)
+
+	permissions, err := ParsePermissions(map[string]interface{}{
+		"file": map[string]interface{}{"public": "0664", "private": "0640"},
+		"dir":  map[string]interface{}{"private": "0750"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), permissions.Dir[VisibilityPublic])
+	driver.Permissions = permissions
+
+	assert.NoError(t, driver.Put("a.txt", strings.NewReader("a")))
+	info, err := os.Stat(filepath.Join(root, "a.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0664), info.Mode().Perm())
+
+	assert.NoError(t, driver.SetVisibility("a.txt", VisibilityPrivate))
+	info, _ = os.Stat(filepath.Join(root, "a.txt"))
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+	visibility, err := driver.Visibility("a.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, VisibilityPrivate, visibility)
+
+	assert.NoError(t, driver.MakeDirectory("private"))
+	assert.NoError(t, driver.SetVisibility("private", VisibilityPrivate))
+	info, _ = os.Stat(filepath.Join(root, "private"))
+	assert.Equal(t, os.FileMode(0750), info.Mode().Perm())
+
+	_, err = ParsePermissions(map[string]interface{}{
+		"file": map[string]interface{}{"public": "rw-r--r--"},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,386 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryDriver فایل‌ها را در حافظه نگهداری می‌کند و برای تست‌ها مناسب است
+type MemoryDriver struct {
+	mutex sync.RWMutex
+	files map[string]*memoryFile
+	dirs  map[string]bool
+}
+
+type memoryFile struct {
+	data         []byte
+	lastModified time.Time
+	visibility   Visibility
+}
+
+func NewMemoryDriver() *MemoryDriver {
+	return &MemoryDriver{
+		files: make(map[string]*memoryFile),
+		dirs:  make(map[string]bool),
+	}
+}
+
+// memoryPath مسیر را یکسان‌سازی می‌کند؛ مسیرها نسبت به ریشه دیسک و بدون / ابتدایی نگهداری می‌شوند
+func memoryPath(p string) string {
+	return strings.TrimPrefix(path.Clean("/"+p), "/")
+}
+
+func notExist(op string, p string) error {
+	return &os.PathError{Op: op, Path: p, Err: os.ErrNotExist}
+}
+
+func (md *MemoryDriver) Put(path string, content io.ReadSeeker) error {
+	return md.PutStream(path, content)
+}
+
+func (md *MemoryDriver) PutStream(path string, content io.Reader) error {
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+	md.write(memoryPath(path), data)
+	return nil
+}
+
+func (md *MemoryDriver) write(key string, data []byte) {
+	md.mutex.Lock()
+	defer md.mutex.Unlock()
+
+	visibility := VisibilityPublic
+	if file, ok := md.files[key]; ok {
+		visibility = file.visibility
+	}
+	md.files[key] = &memoryFile{data: data, lastModified: time.Now(), visibility: visibility}
+}
+
+func (md *MemoryDriver) read(op string, p string) ([]byte, error) {
+	md.mutex.RLock()
+	defer md.mutex.RUnlock()
+
+	file, ok := md.files[memoryPath(p)]
+	if !ok {
+		return nil, notExist(op, p)
+	}
+	return file.data, nil
+}
+
+func (md *MemoryDriver) Get(path string) (io.Reader, error) {
+	data, err := md.read("get", path)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
+func (md *MemoryDriver) ReadStream(path string) (io.ReadCloser, error) {
+	data, err := md.read("read", path)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (md *MemoryDriver) ReadRange(path string, offset int64, length int64) (io.ReadCloser, error) {
+	data, err := md.read("read", path)
+	if err != nil {
+		return nil, err
+	}
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	data = data[offset:]
+	if length >= 0 && length < int64(len(data)) {
+		data = data[:length]
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (md *MemoryDriver) Delete(path string) error {
+	md.mutex.Lock()
+	defer md.mutex.Unlock()
+
+	key := memoryPath(path)
+	if _, ok := md.files[key]; !ok {
+		return notExist("delete", path)
+	}
+	delete(md.files, key)
+	return nil
+}
+
+func (md *MemoryDriver) Exists(path string) (bool, error) {
+	md.mutex.RLock()
+	defer md.mutex.RUnlock()
+
+	key := memoryPath(path)
+	if _, ok := md.files[key]; ok {
+		return true, nil
+	}
+	return md.isDirectory(key), nil
+}
+
+// isDirectory دایرکتوری‌ای که صریحا ساخته شده یا فایلی درون آن وجود دارد
+func (md *MemoryDriver) isDirectory(key string) bool {
+	if key == "" || md.dirs[key] {
+		return true
+	}
+	prefix := key + "/"
+	for name := range md.files {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	for name := range md.dirs {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// List نام فایل‌ها و زیردایرکتوری‌های مستقیم دایرکتوری را به ترتیب الفبا برمی‌گرداند
+func (md *MemoryDriver) List(directory string) ([]string, error) {
+	md.mutex.RLock()
+	defer md.mutex.RUnlock()
+
+	key := memoryPath(directory)
+	if !md.isDirectory(key) {
+		return nil, notExist("list", directory)
+	}
+
+	prefix := ""
+	if key != "" {
+		prefix = key + "/"
+	}
+
+	seen := make(map[string]bool)
+	collect := func(name string) {
+		if !strings.HasPrefix(name, prefix) || name == key {
+			return
+		}
+		child, _, _ := strings.Cut(strings.TrimPrefix(name, prefix), "/")
+		seen[child] = true
+	}
+	for name := range md.files {
+		collect(name)
+	}
+	for name := range md.dirs {
+		collect(name)
+	}
+
+	var names []string
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (md *MemoryDriver) Files(directory string, recursive bool) ([]string, error) {
+	return listFiles(md, directory, recursive)
+}
+
+func (md *MemoryDriver) Directories(directory string) ([]string, error) {
+	return listDirectories(md, directory)
+}
+
+func (md *MemoryDriver) ListContents(directory string, recursive bool, cursor string, limit int) (Page, error) {
+	names, err := md.List(directory)
+	if err != nil {
+		return Page{}, err
+	}
+
+	md.mutex.RLock()
+	defer md.mutex.RUnlock()
+
+	key := memoryPath(directory)
+	prefix := ""
+	if key != "" {
+		prefix = key + "/"
+	}
+
+	var entries []Entry
+	if recursive {
+		for name, file := range md.files {
+			if strings.HasPrefix(name, prefix) {
+				entries = append(entries, Entry{Path: name, Size: int64(len(file.data)), LastModified: file.lastModified})
+			}
+		}
+		return paginate(entries, cursor, limit), nil
+	}
+
+	for _, name := range names {
+		entryPath := prefix + name
+		if file, ok := md.files[entryPath]; ok {
+			entries = append(entries, Entry{Path: entryPath, Size: int64(len(file.data)), LastModified: file.lastModified})
+		} else {
+			entries = append(entries, Entry{Path: entryPath, IsDir: true})
+		}
+	}
+	return paginate(entries, cursor, limit), nil
+}
+
+func (md *MemoryDriver) Missing(path string) (bool, error) {
+	exists, err := md.Exists(path)
+	if err != nil {
+		return false, err
+	}
+	return !exists, nil
+}
+
+func (md *MemoryDriver) Download(path string) (io.Reader, error) {
+	return md.Get(path)
+}
+
+func (md *MemoryDriver) URL(path string) (string, error) {
+	return "/" + memoryPath(path), nil
+}
+
+func (md *MemoryDriver) TemporaryURL(path string, expiresIn int64) (string, error) {
+	if _, err := md.read("url", path); err != nil {
+		return "", err
+	}
+	expireTime := time.Now().Add(time.Duration(expiresIn) * time.Second)
+	return fmt.Sprintf("/%s?expires=%d", memoryPath(path), expireTime.Unix()), nil
+}
+
+func (md *MemoryDriver) Size(path string) (int64, error) {
+	data, err := md.read("size", path)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(data)), nil
+}
+
+func (md *MemoryDriver) Metadata(path string) (Metadata, error) {
+	md.mutex.RLock()
+	file, ok := md.files[memoryPath(path)]
+	md.mutex.RUnlock()
+	if !ok {
+		return Metadata{}, notExist("metadata", path)
+	}
+
+	mimeType := mimeTypeByExtension(path)
+	if mimeType == "" {
+		mimeType = http.DetectContentType(file.data)
+	}
+	sum := md5.Sum(file.data)
+
+	return Metadata{
+		Path:         path,
+		Size:         int64(len(file.data)),
+		LastModified: file.lastModified,
+		MimeType:     mimeType,
+		Checksum:     hex.EncodeToString(sum[:]),
+		Visibility:   file.visibility,
+	}, nil
+}
+
+func (md *MemoryDriver) Stat(path string) (Metadata, error) {
+	md.mutex.RLock()
+	file, ok := md.files[memoryPath(path)]
+	md.mutex.RUnlock()
+	if !ok {
+		return Metadata{}, notExist("stat", path)
+	}
+	return Metadata{
+		Path:         path,
+		Size:         int64(len(file.data)),
+		LastModified: file.lastModified,
+		MimeType:     mimeTypeByExtension(path),
+	}, nil
+}
+
+func (md *MemoryDriver) SetVisibility(path string, visibility Visibility) error {
+	if err := validateVisibility(visibility); err != nil {
+		return err
+	}
+
+	md.mutex.Lock()
+	defer md.mutex.Unlock()
+
+	file, ok := md.files[memoryPath(path)]
+	if !ok {
+		return notExist("chmod", path)
+	}
+	file.visibility = visibility
+	return nil
+}
+
+func (md *MemoryDriver) Visibility(path string) (Visibility, error) {
+	md.mutex.RLock()
+	defer md.mutex.RUnlock()
+
+	file, ok := md.files[memoryPath(path)]
+	if !ok {
+		return "", notExist("stat", path)
+	}
+	return file.visibility, nil
+}
+
+func (md *MemoryDriver) Copy(sourcePath string, destinationPath string) error {
+	data, err := md.read("copy", sourcePath)
+	if err != nil {
+		return err
+	}
+	md.write(memoryPath(destinationPath), append([]byte(nil), data...))
+	return nil
+}
+
+func (md *MemoryDriver) Move(sourcePath string, destinationPath string) error {
+	if err := md.Copy(sourcePath, destinationPath); err != nil {
+		return err
+	}
+	return md.Delete(sourcePath)
+}
+
+func (md *MemoryDriver) MakeDirectory(path string) error {
+	md.mutex.Lock()
+	defer md.mutex.Unlock()
+
+	key := memoryPath(path)
+	if key != "" {
+		md.dirs[key] = true
+	}
+	return nil
+}
+
+func (md *MemoryDriver) DeleteDirectory(path string) error {
+	md.mutex.Lock()
+	defer md.mutex.Unlock()
+
+	key := memoryPath(path)
+	if key == "" {
+		return fmt.Errorf("refusing to delete the storage root")
+	}
+	if !md.isDirectory(key) {
+		return notExist("delete", path)
+	}
+
+	prefix := key + "/"
+	for name := range md.files {
+		if strings.HasPrefix(name, prefix) {
+			delete(md.files, name)
+		}
+	}
+	for name := range md.dirs {
+		if name == key || strings.HasPrefix(name, prefix) {
+			delete(md.dirs, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryDriver(t *testing.T) {
+	driver := NewMemoryDriver()
+
+	assert.NoError(t, driver.Put("a/b.txt", strings.NewReader("hello")))
+	assert.NoError(t, driver.PutStream("/a/c/d.txt", strings.NewReader("world")))
+
+	stream, err := driver.ReadStream("a/b.txt")
+	assert.NoError(t, err)
+	content, _ := io.ReadAll(stream)
+	assert.NoError(t, stream.Close())
+	assert.Equal(t, "hello", string(content))
+
+	exists, _ := driver.Exists("a/c")
+	assert.True(t, exists)
+	missing, _ := driver.Missing("a/x.txt")
+	assert.True(t, missing)
+
+	files, err := driver.List("a")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b.txt", "c"}, files)
+
+	_, err = driver.Get("missing.txt")
+	assert.True(t, os.IsNotExist(err))
+
+	meta, err := driver.Metadata("a/b.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), meta.Size)
+	assert.Equal(t, "5d41402abc4b2a76b9719d911017c592", meta.Checksum)
+	assert.Equal(t, VisibilityPublic, meta.Visibility)
+
+	assert.NoError(t, driver.SetVisibility("a/b.txt", VisibilityPrivate))
+	visibility, _ := driver.Visibility("a/b.txt")
+	assert.Equal(t, VisibilityPrivate, visibility)
+
+	assert.NoError(t, driver.Move("a/b.txt", "moved.txt"))
+	size, err := driver.Size("moved.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), size)
+
+	assert.NoError(t, driver.MakeDirectory("empty"))
+	files, _ = driver.List("")
+	assert.Equal(t, []string{"a", "empty", "moved.txt"}, files)
+
+	assert.NoError(t, driver.DeleteDirectory("a"))
+	exists, _ = driver.Exists("a/c/d.txt")
+	assert.False(t, exists)
+	assert.Error(t, driver.DeleteDirectory(""))
+}
+
+func TestFake(t *testing.T) {
+	original := NewMemoryDriver()
+	RegisterDriver("uploads", original)
+	assert.NoError(t, SetDefaultDriver("uploads"))
+	defer func() {
+		delete(Registry, "uploads")
+		DefaultDriverName, DefaultDriver = "", nil
+	}()
+
+	fake := Fake("uploads")
+	assert.Same(t, fake, DefaultDriver)
+
+	assert.NoError(t, DefaultDriver.Put("avatars/1.png", strings.NewReader("png")))
+	assert.True(t, fake.AssertExists(t, "avatars/1.png"))
+	assert.True(t, fake.AssertMissing(t, "avatars/2.png"))
+	assert.True(t, fake.AssertContent(t, "avatars/1.png", "png"))
+
+	recorder := &recordingTB{}
+	assert.False(t, fake.AssertExists(recorder, "avatars/2.png"))
+	assert.False(t, fake.AssertMissing(recorder, "avatars/1.png"))
+	assert.False(t, fake.AssertContent(recorder, "avatars/1.png", "jpg"))
+	assert.False(t, fake.AssertDirectoryEmpty(recorder, "avatars"))
+	assert.Len(t, recorder.errors, 4)
+
+	fake.Restore()
+	assert.Same(t, original, Registry["uploads"])
+	assert.Same(t, original, DefaultDriver)
+	exists, _ := original.Exists("avatars/1.png")
+	assert.False(t, exists)
+}
+
+type recordingTB struct {
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, format)
+}
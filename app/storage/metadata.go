@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"time"
+)
+
+// Visibility سطح دسترسی یک فایل
+type Visibility string
+
+const (
+	// VisibilityPublic فایل برای همه قابل خواندن است
+	VisibilityPublic Visibility = "public"
+	// VisibilityPrivate فایل فقط برای برنامه قابل دسترسی است
+	VisibilityPrivate Visibility = "private"
+)
+
+// Metadata اطلاعات یک فایل
+type Metadata struct {
+	Path         string     `json:"path"`
+	Size         int64      `json:"size"`
+	LastModified time.Time  `json:"last_modified"`
+	MimeType     string     `json:"mime_type"`
+	Checksum     string     `json:"checksum"`
+	Visibility   Visibility `json:"visibility"`
+}
+
+// validateVisibility بررسی می‌کند که سطح دسترسی معتبر باشد
+func validateVisibility(visibility Visibility) error {
+	if visibility != VisibilityPublic && visibility != VisibilityPrivate {
+		return fmt.Errorf("invalid visibility: %s", visibility)
+	}
+	return nil
+}
+
+// mimeTypeByExtension نوع MIME را بر اساس پسوند فایل تعیین می‌کند
+func mimeTypeByExtension(path string) string {
+	return mime.TypeByExtension(filepath.Ext(path))
+}
+
+// sniffAndHash نوع MIME و checksum محتوا را با یک بار خواندن آن محاسبه می‌کند؛ ۵۱۲ بایت اول برای تشخیص نوع فایل کافی است
+func sniffAndHash(path string, content io.Reader) (string, string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(content, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", "", err
+	}
+	head = head[:n]
+
+	hash := md5.New()
+	hash.Write(head)
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", "", err
+	}
+
+	mimeType := mimeTypeByExtension(path)
+	if mimeType == "" {
+		mimeType = http.DetectContentType(head)
+	}
+	return mimeType, hex.EncodeToString(hash.Sum(nil)), nil
+}
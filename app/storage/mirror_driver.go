@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// MirrorDriver هر نوشتن را روی تمام دیسک‌ها انجام می‌دهد و خواندن را از اولین دیسکی که موفق شود.
+// اگر نوشتن روی یکی از دیسک‌ها شکست بخورد، عملیات روی بقیه دیسک‌ها ادامه پیدا می‌کند و تمام خطاها برگردانده می‌شوند.
+type MirrorDriver struct {
+	disks []Storage
+}
+
+func NewMirrorDriver(disks ...Storage) (*MirrorDriver, error) {
+	if len(disks) == 0 {
+		return nil, fmt.Errorf("mirror driver requires at least one disk")
+	}
+	return &MirrorDriver{disks: disks}, nil
+}
+
+// write عملیات را روی تمام دیسک‌ها اجرا می‌کند
+func (md *MirrorDriver) write(fn func(disk Storage) error) error {
+	var errs []error
+	for i, disk := range md.disks {
+		if err := fn(disk); err != nil {
+			errs = append(errs, fmt.Errorf("mirror disk %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// mirrorRead عملیات را روی دیسک‌ها به ترتیب اجرا می‌کند تا یکی موفق شود
+func mirrorRead[T any](md *MirrorDriver, fn func(disk Storage) (T, error)) (T, error) {
+	var errs []error
+	for i, disk := range md.disks {
+		result, err := fn(disk)
+		if err == nil {
+			return result, nil
+		}
+		errs = append(errs, fmt.Errorf("mirror disk %d: %w", i, err))
+	}
+	var zero T
+	return zero, errors.Join(errs...)
+}
+
+func (md *MirrorDriver) Put(path string, content io.ReadSeeker) error {
+	return md.write(func(disk Storage) error {
+		if _, err := content.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		return disk.Put(path, content)
+	})
+}
+
+// PutStream stream فقط یک بار قابل خواندن است، بنابراین ابتدا در یک فایل موقت ذخیره می‌شود
+func (md *MirrorDriver) PutStream(path string, content io.Reader) error {
+	if len(md.disks) == 1 {
+		return md.disks[0].PutStream(path, content)
+	}
+
+	tmp, err := os.CreateTemp("", "weiser-mirror-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err := io.Copy(tmp, content); err != nil {
+		return err
+	}
+	return md.Put(path, tmp)
+}
+
+func (md *MirrorDriver) Get(path string) (io.Reader, error) {
+	return mirrorRead(md, func(disk Storage) (io.Reader, error) { return disk.Get(path) })
+}
+
+func (md *MirrorDriver) ReadStream(path string) (io.ReadCloser, error) {
+	return mirrorRead(md, func(disk Storage) (io.ReadCloser, error) { return disk.ReadStream(path) })
+}
+
+func (md *MirrorDriver) ReadRange(path string, offset int64, length int64) (io.ReadCloser, error) {
+	return mirrorRead(md, func(disk Storage) (io.ReadCloser, error) { return ReadRange(disk, path, offset, length) })
+}
+
+func (md *MirrorDriver) Delete(path string) error {
+	return md.write(func(disk Storage) error { return disk.Delete(path) })
+}
+
+func (md *MirrorDriver) Exists(path string) (bool, error) {
+	return mirrorRead(md, func(disk Storage) (bool, error) { return disk.Exists(path) })
+}
+
+func (md *MirrorDriver) List(directory string) ([]string, error) {
+	return mirrorRead(md, func(disk Storage) ([]string, error) { return disk.List(directory) })
+}
+
+func (md *MirrorDriver) Files(directory string, recursive bool) ([]string, error) {
+	return mirrorRead(md, func(disk Storage) ([]string, error) { return disk.Files(directory, recursive) })
+}
+
+func (md *MirrorDriver) Directories(directory string) ([]string, error) {
+	return mirrorRead(md, func(disk Storage) ([]string, error) { return disk.Directories(directory) })
+}
+
+// ListContents همیشه از دیسک اول خوانده می‌شود، چون cursor هر دیسک فقط برای همان دیسک معتبر است
+func (md *MirrorDriver) ListContents(directory string, recursive bool, cursor string, limit int) (Page, error) {
+	return md.disks[0].ListContents(directory, recursive, cursor, limit)
+}
+
+func (md *MirrorDriver) Missing(path string) (bool, error) {
+	return mirrorRead(md, func(disk Storage) (bool, error) { return disk.Missing(path) })
+}
+
+func (md *MirrorDriver) Download(path string) (io.Reader, error) {
+	return mirrorRead(md, func(disk Storage) (io.Reader, error) { return disk.Download(path) })
+}
+
+func (md *MirrorDriver) URL(path string) (string, error) {
+	return mirrorRead(md, func(disk Storage) (string, error) { return disk.URL(path) })
+}
+
+func (md *MirrorDriver) TemporaryURL(path string, expiresIn int64) (string, error) {
+	return mirrorRead(md, func(disk Storage) (string, error) { return disk.TemporaryURL(path, expiresIn) })
+}
+
+func (md *MirrorDriver) Size(path string) (int64, error) {
+	return mirrorRead(md, func(disk Storage) (int64, error) { return disk.Size(path) })
+}
+
+func (md *MirrorDriver) Metadata(path string) (Metadata, error) {
+	return mirrorRead(md, func(disk Storage) (Metadata, error) { return disk.Metadata(path) })
+}
+
+func (md *MirrorDriver) Stat(path string) (Metadata, error) {
+	return mirrorRead(md, func(disk Storage) (Metadata, error) { return Stat(disk, path) })
+}
+
+func (md *MirrorDriver) SetVisibility(path string, visibility Visibility) error {
+	return md.write(func(disk Storage) error { return disk.SetVisibility(path, visibility) })
+}
+
+func (md *MirrorDriver) Visibility(path string) (Visibility, error) {
+	return mirrorRead(md, func(disk Storage) (Visibility, error) { return disk.Visibility(path) })
+}
+
+func (md *MirrorDriver) Copy(sourcePath string, destinationPath string) error {
+	return md.write(func(disk Storage) error { return disk.Copy(sourcePath, destinationPath) })
+}
+
+func (md *MirrorDriver) Move(sourcePath string, destinationPath string) error {
+	return md.write(func(disk Storage) error { return disk.Move(sourcePath, destinationPath) })
+}
+
+func (md *MirrorDriver) MakeDirectory(path string) error {
+	return md.write(func(disk Storage) error { return disk.MakeDirectory(path) })
+}
+
+func (md *MirrorDriver) DeleteDirectory(path string) error {
+	return md.write(func(disk Storage) error { return disk.DeleteDirectory(path) })
+}
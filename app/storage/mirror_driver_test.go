@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMirrorDriver(t *testing.T) {
+	primary, backup := NewMemoryDriver(), NewMemoryDriver()
+	mirror, err := NewMirrorDriver(primary, backup)
+	assert.NoError(t, err)
+
+	assert.NoError(t, mirror.Put("a.txt", strings.NewReader("a")))
+	assert.NoError(t, mirror.PutStream("b.txt", io.MultiReader(strings.NewReader("b"))))
+	for _, disk := range []*MemoryDriver{primary, backup} {
+		files, _ := disk.List("")
+		assert.Equal(t, []string{"a.txt", "b.txt"}, files)
+	}
+
+	// خواندن از اولین دیسکی که موفق شود
+	assert.NoError(t, primary.Delete("b.txt"))
+	reader, err := mirror.Get("b.txt")
+	assert.NoError(t, err)
+	content, _ := io.ReadAll(reader)
+	assert.Equal(t, "b", string(content))
+
+	_, err = mirror.Get("missing.txt")
+	assert.Error(t, err)
+
+	assert.NoError(t, mirror.Move("a.txt", "c.txt"))
+	exists, _ := backup.Exists("c.txt")
+	assert.True(t, exists)
+
+	_, err = NewMirrorDriver()
+	assert.Error(t, err)
+}
+
+func TestMirrorDriver_PartialFailure(t *testing.T) {
+	primary := NewMemoryDriver()
+	mirror, err := NewMirrorDriver(NewReadOnlyDriver(NewMemoryDriver()), primary)
+	assert.NoError(t, err)
+
+	// نوشتن روی بقیه دیسک‌ها ادامه پیدا می‌کند و خطا برگردانده می‌شود
+	err = mirror.PutStream("a.txt", strings.NewReader("a"))
+	assert.True(t, errors.Is(err, ErrReadOnly))
+	exists, _ := primary.Exists("a.txt")
+	assert.True(t, exists)
+}
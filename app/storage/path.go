@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrPathOutsideRoot زمانی برگردانده می‌شود که مسیر داده شده از ریشه دیسک خارج شود
+var ErrPathOutsideRoot = errors.New("storage: path is outside the disk root")
+
+// SafeJoin مسیر کاربر را به ریشه اضافه می‌کند و اگر مسیر حاصل (مثلا با ../) از ریشه خارج شود خطا برمی‌گرداند
+func SafeJoin(root string, path string) (string, error) {
+	if strings.ContainsRune(path, 0) {
+		return "", fmt.Errorf("%w: %q", ErrPathOutsideRoot, path)
+	}
+
+	root = filepath.Clean(root)
+	fullPath := filepath.Join(root, filepath.FromSlash(path))
+
+	rel, err := filepath.Rel(root, fullPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %q", ErrPathOutsideRoot, path)
+	}
+	return fullPath, nil
+}
+
+// remoteJoin مانند SafeJoin برای مسیرهای سرورهای راه دور است که همیشه از / استفاده می‌کنند
+func remoteJoin(root string, p string) (string, error) {
+	if strings.ContainsRune(p, 0) {
+		return "", fmt.Errorf("%w: %q", ErrPathOutsideRoot, p)
+	}
+
+	root = path.Clean("/" + root)
+	fullPath := path.Join(root, p)
+	if fullPath != root && !strings.HasPrefix(fullPath, strings.TrimSuffix(root, "/")+"/") {
+		return "", fmt.Errorf("%w: %q", ErrPathOutsideRoot, p)
+	}
+	return fullPath, nil
+}
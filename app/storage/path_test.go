@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSafeJoin(t *testing.T) {
+	root := filepath.Join("var", "storage")
+
+	for path, expected := range map[string]string{
+		"a.txt":             filepath.Join(root, "a.txt"),
+		"dir/../a.txt":      filepath.Join(root, "a.txt"),
+		"/etc/passwd":       filepath.Join(root, "etc", "passwd"),
+		"":                  root,
+		"..foo/bar.txt":     filepath.Join(root, "..foo", "bar.txt"),
+		"./nested/./b.json": filepath.Join(root, "nested", "b.json"),
+	} {
+		fullPath, err := SafeJoin(root, path)
+		assert.NoError(t, err, path)
+		assert.Equal(t, expected, fullPath, path)
+	}
+
+	for _, path := range []string{"..", "../secret.txt", "dir/../../secret.txt", "a\x00b"} {
+		_, err := SafeJoin(root, path)
+		assert.ErrorIs(t, err, ErrPathOutsideRoot, path)
+	}
+}
+
+func TestRemoteJoin(t *testing.T) {
+	for _, c := range []struct{ root, path, expected string }{
+		{"/upload", "a.txt", "/upload/a.txt"},
+		{"/upload/", "/b/../c.txt", "/upload/c.txt"},
+		{"upload", "a.txt", "/upload/a.txt"},
+		{"", "../a.txt", "/a.txt"},
+		{"/", "x/y", "/x/y"},
+		{"/upload", "", "/upload"},
+	} {
+		fullPath, err := remoteJoin(c.root, c.path)
+		assert.NoError(t, err, c.path)
+		assert.Equal(t, c.expected, fullPath, c.path)
+	}
+
+	for _, path := range []string{"..", "../upload2/a.txt", "a/../../b"} {
+		_, err := remoteJoin("/upload", path)
+		assert.ErrorIs(t, err, ErrPathOutsideRoot, path)
+	}
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Permissions مجوز فایل‌ها و دایرکتوری‌ها را برای هر سطح دسترسی مشخص می‌کند
+type Permissions struct {
+	File map[Visibility]os.FileMode
+	Dir  map[Visibility]os.FileMode
+}
+
+// DefaultPermissions مجوزهای پیش‌فرض دیسک محلی
+func DefaultPermissions() Permissions {
+	return Permissions{
+		File: map[Visibility]os.FileMode{
+			VisibilityPublic:  0644,
+			VisibilityPrivate: 0600,
+		},
+		Dir: map[Visibility]os.FileMode{
+			VisibilityPublic:  0755,
+			VisibilityPrivate: 0700,
+		},
+	}
+}
+
+// ParsePermissions مجوزها را از پیکربندی دیسک می‌خواند؛ مقادیر تعریف نشده از پیش‌فرض گرفته می‌شوند.
+// مقادیر به صورت رشته octal مانند "0644" نوشته می‌شوند.
+func ParsePermissions(config map[string]interface{}) (Permissions, error) {
+	permissions := DefaultPermissions()
+	for kind, modes := range map[string]map[Visibility]os.FileMode{
+		"file": permissions.File,
+		"dir":  permissions.Dir,
+	} {
+		values, ok := config[kind].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, visibility := range []Visibility{VisibilityPublic, VisibilityPrivate} {
+			value, ok := values[string(visibility)]
+			if !ok {
+				continue
+			}
+			mode, err := parseFileMode(value)
+			if err != nil {
+				return Permissions{}, fmt.Errorf("invalid %s permission for %s: %w", kind, visibility, err)
+			}
+			modes[visibility] = mode
+		}
+	}
+	return permissions, nil
+}
+
+func parseFileMode(value interface{}) (os.FileMode, error) {
+	switch v := value.(type) {
+	case string:
+		mode, err := strconv.ParseUint(v, 8, 32)
+		if err != nil {
+			return 0, err
+		}
+		return os.FileMode(mode).Perm(), nil
+	case int:
+		return os.FileMode(v).Perm(), nil
+	default:
+		return 0, fmt.Errorf("unsupported value %v", value)
+	}
+}
+
+// visibilityOf سطح دسترسی مربوط به یک mode را پیدا می‌کند؛ modeهای ناشناخته بر اساس قابل خواندن بودن برای دیگران تعیین می‌شوند
+func visibilityOf(modes map[Visibility]os.FileMode, mode os.FileMode) Visibility {
+	switch mode.Perm() {
+	case modes[VisibilityPrivate]:
+		return VisibilityPrivate
+	case modes[VisibilityPublic]:
+		return VisibilityPublic
+	}
+	if mode.Perm()&0004 != 0 {
+		return VisibilityPublic
+	}
+	return VisibilityPrivate
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrUnsupported زمانی برگردانده می‌شود که درایور از عملیات درخواست شده پشتیبانی نکند
+var ErrUnsupported = errors.New("storage: operation is not supported by this driver")
+
+// errPoolTimeout زمانی برگردانده می‌شود که در مدت timeout هیچ اتصال آزادی پیدا نشود
+var errPoolTimeout = errors.New("storage: timed out waiting for a connection")
+
+const (
+	defaultRemoteTimeout        = 30 * time.Second
+	defaultRemoteMaxConnections = 4
+)
+
+// pool اتصال‌های درایورهای راه دور (SFTP و FTP) را نگهداری می‌کند تا برای هر عملیات اتصال جدیدی ساخته نشود.
+// حداکثر size اتصال همزمان باز می‌شود و بقیه درخواست‌ها تا timeout منتظر می‌مانند.
+type pool[T any] struct {
+	// dial یک اتصال جدید می‌سازد
+	dial func() (T, error)
+	// check سالم بودن یک اتصال بیکار را پیش از استفاده مجدد بررسی می‌کند
+	check func(T) error
+	// close اتصال را می‌بندد
+	close func(T) error
+	// broken مشخص می‌کند که آیا خطای یک عملیات به معنی خراب شدن اتصال است
+	broken func(error) bool
+
+	idle    chan T
+	slots   chan struct{}
+	timeout time.Duration
+}
+
+func newPool[T any](size int, timeout time.Duration) *pool[T] {
+	if size <= 0 {
+		size = defaultRemoteMaxConnections
+	}
+	if timeout <= 0 {
+		timeout = defaultRemoteTimeout
+	}
+	return &pool[T]{
+		idle:    make(chan T, size),
+		slots:   make(chan struct{}, size),
+		timeout: timeout,
+	}
+}
+
+// get یک اتصال بیکار را برمی‌گرداند یا در صورت نبودن آن اتصال جدیدی می‌سازد
+func (p *pool[T]) get() (T, error) {
+	var zero T
+
+	timer := time.NewTimer(p.timeout)
+	defer timer.Stop()
+	select {
+	case p.slots <- struct{}{}:
+	case <-timer.C:
+		return zero, errPoolTimeout
+	}
+
+	for {
+		select {
+		case conn := <-p.idle:
+			if p.check == nil || p.check(conn) == nil {
+				return conn, nil
+			}
+			p.close(conn)
+		default:
+			conn, err := p.dial()
+			if err != nil {
+				<-p.slots
+				return zero, err
+			}
+			return conn, nil
+		}
+	}
+}
+
+// put اتصال را به pool برمی‌گرداند؛ اتصالی که عملیاتش با خطای اتصال تمام شده بسته می‌شود
+func (p *pool[T]) put(conn T, err error) {
+	defer func() { <-p.slots }()
+
+	if err != nil && p.broken(err) {
+		p.close(conn)
+		return
+	}
+	select {
+	case p.idle <- conn:
+	default:
+		p.close(conn)
+	}
+}
+
+// with تابع را با یک اتصال از pool اجرا می‌کند
+func (p *pool[T]) with(fn func(T) error) error {
+	conn, err := p.get()
+	if err != nil {
+		return err
+	}
+	err = fn(conn)
+	p.put(conn, err)
+	return err
+}
+
+// Close تمام اتصال‌های بیکار را می‌بندد
+func (p *pool[T]) Close() error {
+	var errs []error
+	for {
+		select {
+		case conn := <-p.idle:
+			if err := p.close(conn); err != nil {
+				errs = append(errs, err)
+			}
+		default:
+			if len(errs) > 0 {
+				return fmt.Errorf("failed to close %d connections: %w", len(errs), errs[0])
+			}
+			return nil
+		}
+	}
+}
+
+// pooledReader پس از بسته شدن stream اتصال را به pool برمی‌گرداند
+type pooledReader[T any] struct {
+	reader io.ReadCloser
+	pool   *pool[T]
+	conn   T
+	done   bool
+}
+
+func (r *pooledReader[T]) Read(b []byte) (int, error) {
+	return r.reader.Read(b)
+}
+
+func (r *pooledReader[T]) Close() error {
+	if r.done {
+		return nil
+	}
+	r.done = true
+	err := r.reader.Close()
+	r.pool.put(r.conn, err)
+	return err
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"bytes"
+	"io"
+)
+
+// RangeReader درایورهایی که می‌توانند بخشی از فایل را بدون خواندن ابتدای آن بخوانند
+type RangeReader interface {
+	// ReadRange حداکثر length بایت را از offset می‌خواند؛ length منفی یعنی تا انتهای فایل
+	ReadRange(path string, offset int64, length int64) (io.ReadCloser, error)
+}
+
+// ReadRange بخشی از فایل را از هر دیسکی می‌خواند. اگر درایور RangeReader را پیاده‌سازی نکرده باشد
+// ابتدای فایل رد می‌شود (با Seek در صورت امکان).
+func ReadRange(disk Storage, path string, offset int64, length int64) (io.ReadCloser, error) {
+	if reader, ok := disk.(RangeReader); ok {
+		return reader.ReadRange(path, offset, length)
+	}
+
+	stream, err := disk.ReadStream(path)
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		if seeker, ok := stream.(io.Seeker); ok {
+			_, err = seeker.Seek(offset, io.SeekStart)
+		} else if _, err = io.CopyN(io.Discard, stream, offset); err == io.EOF {
+			err = nil
+		}
+		if err != nil {
+			stream.Close()
+			return nil, err
+		}
+	}
+	return limitStream(stream, length), nil
+}
+
+// limitStream خواندن از stream را به length بایت محدود می‌کند و بستن آن را حفظ می‌کند
+func limitStream(stream io.ReadCloser, length int64) io.ReadCloser {
+	if length < 0 {
+		return stream
+	}
+	return &limitedStream{Reader: io.LimitReader(stream, length), Closer: stream}
+}
+
+type limitedStream struct {
+	io.Reader
+	io.Closer
+}
+
+// emptyStream برای بازه‌هایی با طول صفر استفاده می‌شود
+func emptyStream() io.ReadCloser {
+	return io.NopCloser(bytes.NewReader(nil))
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readRange محتوای یک بازه را به صورت رشته برمی‌گرداند
+func readRange(t *testing.T, disk Storage, offset int64, length int64) string {
+	stream, err := ReadRange(disk, "docs/a.txt", offset, length)
+	if !assert.NoError(t, err) {
+		return ""
+	}
+	defer stream.Close()
+	content, err := io.ReadAll(stream)
+	assert.NoError(t, err)
+	return string(content)
+}
+
+func TestReadRange(t *testing.T) {
+	disks := map[string]Storage{
+		"local":  NewLocalDriver(t.TempDir()),
+		"memory": NewMemoryDriver(),
+		"s3":     newTestS3Driver(t),
+		"scoped": NewScopedDriver(NewMemoryDriver(), "uploads"),
+		// دیسک‌هایی که RangeReader نیستند از ReadStream استفاده می‌کنند
+		"seek fallback":    struct{ Storage }{NewLocalDriver(t.TempDir())},
+		"discard fallback": struct{ Storage }{NewMemoryDriver()},
+	}
+
+	for name, disk := range disks {
+		t.Run(name, func(t *testing.T) {
+			assert.NoError(t, disk.Put("docs/a.txt", strings.NewReader("hello world")))
+
+			assert.Equal(t, "hello world", readRange(t, disk, 0, -1))
+			assert.Equal(t, "world", readRange(t, disk, 6, -1))
+			assert.Equal(t, "lo w", readRange(t, disk, 3, 4))
+			assert.Equal(t, "world", readRange(t, disk, 6, 100))
+			assert.Equal(t, "", readRange(t, disk, 6, 0))
+
+			_, err := ReadRange(disk, "docs/missing.txt", 0, 1)
+			assert.Error(t, err)
+		})
+	}
+}
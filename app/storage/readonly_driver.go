@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrReadOnly با errors.Is برای تشخیص خطای نوشتن روی دیسک فقط خواندنی استفاده می‌شود
+var ErrReadOnly = errors.New("storage: disk is read-only")
+
+// ReadOnlyError خطای عملیات نوشتن روی دیسک فقط خواندنی
+type ReadOnlyError struct {
+	Op   string
+	Path string
+}
+
+func (e *ReadOnlyError) Error() string {
+	return fmt.Sprintf("storage: %s %s: disk is read-only", e.Op, e.Path)
+}
+
+func (e *ReadOnlyError) Unwrap() error {
+	return ErrReadOnly
+}
+
+// ReadOnlyDriver عملیات خواندن را به دیسک اصلی می‌سپارد و تمام عملیات نوشتن را با ReadOnlyError رد می‌کند
+type ReadOnlyDriver struct {
+	disk Storage
+}
+
+func NewReadOnlyDriver(disk Storage) *ReadOnlyDriver {
+	return &ReadOnlyDriver{disk: disk}
+}
+
+func (rd *ReadOnlyDriver) Put(path string, content io.ReadSeeker) error {
+	return &ReadOnlyError{Op: "put", Path: path}
+}
+
+func (rd *ReadOnlyDriver) PutStream(path string, content io.Reader) error {
+	return &ReadOnlyError{Op: "put", Path: path}
+}
+
+func (rd *ReadOnlyDriver) Get(path string) (io.Reader, error) {
+	return rd.disk.Get(path)
+}
+
+func (rd *ReadOnlyDriver) ReadStream(path string) (io.ReadCloser, error) {
+	return rd.disk.ReadStream(path)
+}
+
+func (rd *ReadOnlyDriver) ReadRange(path string, offset int64, length int64) (io.ReadCloser, error) {
+	return ReadRange(rd.disk, path, offset, length)
+}
+
+func (rd *ReadOnlyDriver) Delete(path string) error {
+	return &ReadOnlyError{Op: "delete", Path: path}
+}
+
+func (rd *ReadOnlyDriver) Exists(path string) (bool, error) {
+	return rd.disk.Exists(path)
+}
+
+func (rd *ReadOnlyDriver) List(directory string) ([]string, error) {
+	return rd.disk.List(directory)
+}
+
+func (rd *ReadOnlyDriver) Files(directory string, recursive bool) ([]string, error) {
+	return rd.disk.Files(directory, recursive)
+}
+
+func (rd *ReadOnlyDriver) Directories(directory string) ([]string, error) {
+	return rd.disk.Directories(directory)
+}
+
+func (rd *ReadOnlyDriver) ListContents(directory string, recursive bool, cursor string, limit int) (Page, error) {
+	return rd.disk.ListContents(directory, recursive, cursor, limit)
+}
+
+func (rd *ReadOnlyDriver) Missing(path string) (bool, error) {
+	return rd.disk.Missing(path)
+}
+
+func (rd *ReadOnlyDriver) Download(path string) (io.Reader, error) {
+	return rd.disk.Download(path)
+}
+
+func (rd *ReadOnlyDriver) URL(path string) (string, error) {
+	return rd.disk.URL(path)
+}
+
+func (rd *ReadOnlyDriver) TemporaryURL(path string, expiresIn int64) (string, error) {
+	return rd.disk.TemporaryURL(path, expiresIn)
+}
+
+func (rd *ReadOnlyDriver) Size(path string) (int64, error) {
+	return rd.disk.Size(path)
+}
+
+func (rd *ReadOnlyDriver) Metadata(path string) (Metadata, error) {
+	return rd.disk.Metadata(path)
+}
+
+func (rd *ReadOnlyDriver) Stat(path string) (Metadata, error) {
+	return Stat(rd.disk, path)
+}
+
+func (rd *ReadOnlyDriver) SetVisibility(path string, visibility Visibility) error {
+	return &ReadOnlyError{Op: "chmod", Path: path}
+}
+
+func (rd *ReadOnlyDriver) Visibility(path string) (Visibility, error) {
+	return rd.disk.Visibility(path)
+}
+
+func (rd *ReadOnlyDriver) Copy(sourcePath string, destinationPath string) error {
+	return &ReadOnlyError{Op: "copy", Path: destinationPath}
+}
+
+func (rd *ReadOnlyDriver) Move(sourcePath string, destinationPath string) error {
+	return &ReadOnlyError{Op: "move", Path: sourcePath}
+}
+
+func (rd *ReadOnlyDriver) MakeDirectory(path string) error {
+	return &ReadOnlyError{Op: "mkdir", Path: path}
+}
+
+func (rd *ReadOnlyDriver) DeleteDirectory(path string) error {
+	return &ReadOnlyError{Op: "rmdir", Path: path}
+}
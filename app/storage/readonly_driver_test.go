@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadOnlyDriver(t *testing.T) {
+	disk := NewMemoryDriver()
+	assert.NoError(t, disk.Put("a.txt", strings.NewReader("a")))
+	readonly := NewReadOnlyDriver(disk)
+
+	exists, err := readonly.Exists("a.txt")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	size, err := readonly.Size("a.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), size)
+
+	for _, err := range []error{
+		readonly.Put("b.txt", strings.NewReader("b")),
+		readonly.PutStream("b.txt", strings.NewReader("b")),
+		readonly.Delete("a.txt"),
+		readonly.SetVisibility("a.txt", VisibilityPrivate),
+		readonly.Copy("a.txt", "b.txt"),
+		readonly.Move("a.txt", "b.txt"),
+		readonly.MakeDirectory("dir"),
+		readonly.DeleteDirectory("dir"),
+	} {
+		assert.ErrorIs(t, err, ErrReadOnly)
+	}
+
+	var readOnlyErr *ReadOnlyError
+	assert.True(t, errors.As(readonly.Delete("a.txt"), &readOnlyErr))
+	assert.Equal(t, "delete", readOnlyErr.Op)
+	assert.Equal(t, "a.txt", readOnlyErr.Path)
+	assert.Equal(t, "storage: delete a.txt: disk is read-only", readOnlyErr.Error())
+
+	files, _ := disk.List("")
+	assert.Equal(t, []string{"a.txt"}, files)
+}
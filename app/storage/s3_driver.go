@@ -1,48 +1,169 @@
 package storage
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"io"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 )
 
+// allUsersGroup گروهی که دسترسی عمومی به آن داده می‌شود
+const allUsersGroup = "http://acs.amazonaws.com/groups/global/AllUsers"
+
 type S3Driver struct {
 	Client *s3.S3
 	Bucket string
+	// PartSize اندازه هر بخش در آپلود چندبخشی؛ فایل‌های بزرگ‌تر از آن به صورت چندبخشی آپلود می‌شوند
+	PartSize int64
+	// Concurrency تعداد بخش‌هایی که همزمان آپلود می‌شوند
+	Concurrency int
 }
 
-func NewS3Driver(bucket string) (*S3Driver, error) {
-	sess, err := session.NewSession(&aws.Config{
-		Region: aws.String("us-west-2"),
-	})
+// defaultS3Region زمانی استفاده می‌شود که region در پیکربندی مشخص نشده باشد
+const defaultS3Region = "us-west-2"
+
+// S3Config پیکربندی اتصال به S3 یا سرویس‌های سازگار با آن مانند MinIO
+type S3Config struct {
+	Bucket string
+	Region string
+	// Endpoint آدرس سرویس سازگار با S3؛ خالی به معنی AWS است
+	Endpoint string
+	// Key و Secret اعتبارنامه ثابت؛ اگر خالی باشند از زنجیره پیش‌فرض AWS (متغیرهای محیطی، فایل‌ها و ...) استفاده می‌شود
+	Key          string
+	Secret       string
+	SessionToken string
+	// UsePathStyle آدرس‌ها را به شکل endpoint/bucket/key می‌سازد که MinIO به آن نیاز دارد
+	UsePathStyle bool
+	// PartSize و Concurrency آپلود چندبخشی را تنظیم می‌کنند؛ صفر یعنی مقادیر پیش‌فرض AWS (۵ مگابایت و ۵ بخش همزمان)
+	PartSize    int64
+	Concurrency int
+}
+
+func NewS3Driver(config S3Config) (*S3Driver, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("s3 bucket is required")
+	}
+	if config.PartSize > 0 && config.PartSize < s3manager.MinUploadPartSize {
+		return nil, fmt.Errorf("s3 part size must be at least %d bytes", s3manager.MinUploadPartSize)
+	}
+
+	awsConfig := &aws.Config{
+		Region:           aws.String(config.Region),
+		S3ForcePathStyle: aws.Bool(config.UsePathStyle),
+	}
+	if config.Region == "" {
+		awsConfig.Region = aws.String(defaultS3Region)
+	}
+	if config.Endpoint != "" {
+		awsConfig.Endpoint = aws.String(config.Endpoint)
+	}
+	if config.Key != "" {
+		awsConfig.Credentials = credentials.NewStaticCredentials(config.Key, config.Secret, config.SessionToken)
+	}
+
+	sess, err := session.NewSession(awsConfig)
 	if err != nil {
 		return nil, err
 	}
 	return &S3Driver{
-		Client: s3.New(sess),
-		Bucket: bucket,
+		Client:      s3.New(sess),
+		Bucket:      config.Bucket,
+		PartSize:    config.PartSize,
+		Concurrency: config.Concurrency,
 	}, nil
 }
 
 func (s3d *S3Driver) Put(path string, content io.ReadSeeker) error {
-	_, err := s3d.Client.PutObject(&s3.PutObjectInput{
+	input := &s3.PutObjectInput{
 		Bucket: aws.String(s3d.Bucket),
 		Key:    aws.String(path),
 		Body:   content,
-	})
+	}
+	if mimeType := mimeTypeByExtension(path); mimeType != "" {
+		input.ContentType = aws.String(mimeType)
+	}
+	_, err := s3d.Client.PutObject(input)
+	return err
+}
+
+// PutStream محتوا را بخش به بخش آپلود می‌کند، بنابراین نیازی به Seek ندارد. محتوای بزرگ‌تر از PartSize با آپلود
+// چندبخشی S3 ارسال می‌شود و در صورت خطا بخش‌های آپلود شده حذف می‌شوند.
+func (s3d *S3Driver) PutStream(path string, content io.Reader) error {
+	input := &s3manager.UploadInput{
+		Bucket: aws.String(s3d.Bucket),
+		Key:    aws.String(path),
+		Body:   content,
+	}
+	if mimeType := mimeTypeByExtension(path); mimeType != "" {
+		input.ContentType = aws.String(mimeType)
+	}
+	_, err := s3manager.NewUploaderWithClient(s3d.Client, func(uploader *s3manager.Uploader) {
+		if s3d.PartSize > 0 {
+			uploader.PartSize = s3d.PartSize
+		}
+		if s3d.Concurrency > 0 {
+			uploader.Concurrency = s3d.Concurrency
+		}
+	}).Upload(input)
 	return err
 }
 
+// Get کل محتوای فایل را می‌خواند و پاسخ را می‌بندد؛ برای فایل‌های بزرگ از ReadStream استفاده کنید
 func (s3d *S3Driver) Get(path string) (io.Reader, error) {
+	body, err := s3d.ReadStream(path)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
+func (s3d *S3Driver) ReadStream(path string) (io.ReadCloser, error) {
 	result, err := s3d.Client.GetObject(&s3.GetObjectInput{
 		Bucket: aws.String(s3d.Bucket),
 		Key:    aws.String(path),
 	})
 	if err != nil {
-		return nil, err
+		return nil, s3Error("read", path, err)
+	}
+	return result.Body, nil
+}
+
+// ReadRange فقط بازه‌ی درخواستی را با هدر Range از S3 دریافت می‌کند
+func (s3d *S3Driver) ReadRange(path string, offset int64, length int64) (io.ReadCloser, error) {
+	if length == 0 {
+		return emptyStream(), nil
+	}
+	byteRange := fmt.Sprintf("bytes=%d-", offset)
+	if length > 0 {
+		byteRange += strconv.FormatInt(offset+length-1, 10)
+	}
+	result, err := s3d.Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(s3d.Bucket),
+		Key:    aws.String(path),
+		Range:  aws.String(byteRange),
+	})
+	if err != nil {
+		return nil, s3Error("read", path, err)
 	}
 	return result.Body, nil
 }
@@ -61,28 +182,81 @@ func (s3d *S3Driver) Exists(path string) (bool, error) {
 		Key:    aws.String(path),
 	})
 	if err != nil {
+		if err = s3Error("stat", path, err); errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
 		return false, err
 	}
 	return true, nil
 }
 
+// List نام فایل‌ها و زیردایرکتوری‌های مستقیم را مانند درایور محلی برمی‌گرداند
 func (s3d *S3Driver) List(directory string) ([]string, error) {
-	params := &s3.ListObjectsInput{
-		Bucket: aws.String(s3d.Bucket),
-		Prefix: aws.String(directory),
+	var names []string
+	it := NewIterator(s3d, directory, false, defaultPageSize)
+	for it.Next() {
+		names = append(names, path.Base(it.Entry().Path))
+	}
+	return names, it.Err()
+}
+
+func (s3d *S3Driver) Files(directory string, recursive bool) ([]string, error) {
+	return listFiles(s3d, directory, recursive)
+}
+
+func (s3d *S3Driver) Directories(directory string) ([]string, error) {
+	return listDirectories(s3d, directory)
+}
+
+// ListContents از صفحه‌بندی خود S3 استفاده می‌کند و cursor همان continuation token است.
+// فهرست غیربازگشتی با جداکننده / خوانده می‌شود تا پیشوندهای مشترک به عنوان دایرکتوری برگردانده شوند.
+// اشیاء خالی که برای نشانه‌گذاری دایرکتوری‌ها ساخته می‌شوند در فهرست نمی‌آیند.
+func (s3d *S3Driver) ListContents(directory string, recursive bool, cursor string, limit int) (Page, error) {
+	if limit <= 0 || limit > defaultPageSize {
+		limit = defaultPageSize
+	}
+
+	input := &s3.ListObjectsV2Input{
+		Bucket:  aws.String(s3d.Bucket),
+		MaxKeys: aws.Int64(int64(limit)),
+	}
+	if dir := cleanDirectory(directory); dir != "" {
+		input.Prefix = aws.String(dir + "/")
+	}
+	if !recursive {
+		input.Delimiter = aws.String("/")
+	}
+	if cursor != "" {
+		input.ContinuationToken = aws.String(cursor)
 	}
 
-	resp, err := s3d.Client.ListObjects(params)
+	output, err := s3d.Client.ListObjectsV2(input)
 	if err != nil {
-		return nil, err
+		return Page{}, err
 	}
 
-	var files []string
-	for _, item := range resp.Contents {
-		files = append(files, *item.Key)
+	var entries []Entry
+	for _, prefix := range output.CommonPrefixes {
+		entries = append(entries, Entry{Path: strings.TrimSuffix(aws.StringValue(prefix.Prefix), "/"), IsDir: true})
+	}
+	for _, item := range output.Contents {
+		key := aws.StringValue(item.Key)
+		if strings.HasSuffix(key, "/") {
+			continue
+		}
+		entries = append(entries, Entry{
+			Path:         key,
+			Size:         aws.Int64Value(item.Size),
+			LastModified: aws.TimeValue(item.LastModified),
+		})
 	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
 
-	return files, nil
+	page := Page{Entries: entries}
+	if aws.BoolValue(output.IsTruncated) {
+		page.NextCursor = aws.StringValue(output.NextContinuationToken)
+	}
+	return page, nil
 }
 
 func (s3d *S3Driver) Missing(path string) (bool, error) {
@@ -119,11 +293,96 @@ func (s3d *S3Driver) Size(path string) (int64, error) {
 		Key:    aws.String(path),
 	})
 	if err != nil {
-		return 0, err
+		return 0, s3Error("stat", path, err)
 	}
 	return *output.ContentLength, nil
 }
 
+func (s3d *S3Driver) Metadata(path string) (Metadata, error) {
+	output, err := s3d.Client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(s3d.Bucket),
+		Key:    aws.String(path),
+	})
+	if err != nil {
+		return Metadata{}, s3Error("stat", path, err)
+	}
+
+	visibility, err := s3d.Visibility(path)
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	mimeType := aws.StringValue(output.ContentType)
+	if mimeType == "" {
+		mimeType = mimeTypeByExtension(path)
+	}
+
+	return Metadata{
+		Path:         path,
+		Size:         aws.Int64Value(output.ContentLength),
+		LastModified: aws.TimeValue(output.LastModified),
+		MimeType:     mimeType,
+		Checksum:     strings.Trim(aws.StringValue(output.ETag), `"`),
+		Visibility:   visibility,
+	}, nil
+}
+
+// Stat فقط یک درخواست HEAD می‌فرستد؛ ETag شیء به عنوان Checksum برگردانده می‌شود
+func (s3d *S3Driver) Stat(path string) (Metadata, error) {
+	output, err := s3d.Client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(s3d.Bucket),
+		Key:    aws.String(path),
+	})
+	if err != nil {
+		return Metadata{}, s3Error("stat", path, err)
+	}
+
+	mimeType := aws.StringValue(output.ContentType)
+	if mimeType == "" {
+		mimeType = mimeTypeByExtension(path)
+	}
+	return Metadata{
+		Path:         path,
+		Size:         aws.Int64Value(output.ContentLength),
+		LastModified: aws.TimeValue(output.LastModified),
+		MimeType:     mimeType,
+		Checksum:     strings.Trim(aws.StringValue(output.ETag), `"`),
+	}, nil
+}
+
+func (s3d *S3Driver) SetVisibility(path string, visibility Visibility) error {
+	if err := validateVisibility(visibility); err != nil {
+		return err
+	}
+	acl := s3.ObjectCannedACLPrivate
+	if visibility == VisibilityPublic {
+		acl = s3.ObjectCannedACLPublicRead
+	}
+	_, err := s3d.Client.PutObjectAcl(&s3.PutObjectAclInput{
+		Bucket: aws.String(s3d.Bucket),
+		Key:    aws.String(path),
+		ACL:    aws.String(acl),
+	})
+	return err
+}
+
+func (s3d *S3Driver) Visibility(path string) (Visibility, error) {
+	output, err := s3d.Client.GetObjectAcl(&s3.GetObjectAclInput{
+		Bucket: aws.String(s3d.Bucket),
+		Key:    aws.String(path),
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, grant := range output.Grants {
+		if grant.Grantee != nil && aws.StringValue(grant.Grantee.URI) == allUsersGroup &&
+			aws.StringValue(grant.Permission) == s3.PermissionRead {
+			return VisibilityPublic, nil
+		}
+	}
+	return VisibilityPrivate, nil
+}
+
 func (s3d *S3Driver) Copy(sourcePath string, destinationPath string) error {
 	_, err := s3d.Client.CopyObject(&s3.CopyObjectInput{
 		CopySource: aws.String(s3d.Bucket + "/" + sourcePath),
@@ -140,3 +399,58 @@ func (s3d *S3Driver) Move(sourcePath string, destinationPath string) error {
 	}
 	return s3d.Delete(sourcePath)
 }
+
+// MakeDirectory در S3 دایرکتوری واقعی وجود ندارد، بنابراین یک شیء خالی با پسوند / ساخته می‌شود
+func (s3d *S3Driver) MakeDirectory(path string) error {
+	_, err := s3d.Client.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(s3d.Bucket),
+		Key:    aws.String(directoryPrefix(path)),
+		Body:   bytes.NewReader(nil),
+	})
+	return err
+}
+
+// DeleteDirectory تمام اشیاء با پیشوند دایرکتوری را حذف می‌کند
+func (s3d *S3Driver) DeleteDirectory(path string) error {
+	prefix := directoryPrefix(path)
+	if prefix == "/" {
+		return fmt.Errorf("refusing to delete the storage root")
+	}
+
+	var deleteErr error
+	err := s3d.Client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(s3d.Bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		if len(page.Contents) == 0 {
+			return true
+		}
+		objects := make([]*s3.ObjectIdentifier, 0, len(page.Contents))
+		for _, item := range page.Contents {
+			objects = append(objects, &s3.ObjectIdentifier{Key: item.Key})
+		}
+		_, deleteErr = s3d.Client.DeleteObjects(&s3.DeleteObjectsInput{
+			Bucket: aws.String(s3d.Bucket),
+			Delete: &s3.Delete{Objects: objects, Quiet: aws.Bool(true)},
+		})
+		return deleteErr == nil
+	})
+	if err != nil {
+		return err
+	}
+	return deleteErr
+}
+
+// directoryPrefix مسیر دایرکتوری را به پیشوند کلیدهای S3 تبدیل می‌کند
+func directoryPrefix(path string) string {
+	return strings.Trim(path, "/") + "/"
+}
+
+// s3Error خطای «یافت نشد» S3 را به os.ErrNotExist تبدیل می‌کند تا مانند درایورهای دیگر با errors.Is قابل بررسی باشد
+func s3Error(op string, path string, err error) error {
+	var requestErr awserr.RequestFailure
+	if errors.As(err, &requestErr) && requestErr.StatusCode() == http.StatusNotFound {
+		return &os.PathError{Op: op, Path: path, Err: os.ErrNotExist}
+	}
+	return err
+}
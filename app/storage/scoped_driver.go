@@ -0,0 +1,248 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ScopedDriver تمام مسیرها را با یک پیشوند روی دیسک دیگری نگاشت می‌کند، مثلا پوشه uploads دیسک s3.
+// مسیرها نمی‌توانند با ../ از پیشوند خارج شوند.
+type ScopedDriver struct {
+	disk   Storage
+	prefix string
+}
+
+func NewScopedDriver(disk Storage, prefix string) *ScopedDriver {
+	return &ScopedDriver{disk: disk, prefix: strings.Trim(prefix, "/")}
+}
+
+// path مسیر داده شده را به مسیر روی دیسک اصلی تبدیل می‌کند
+func (sd *ScopedDriver) path(p string) (string, error) {
+	fullPath, err := remoteJoin(sd.prefix, p)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(fullPath, "/"), nil
+}
+
+// unscope پیشوند را از مسیرهایی که دیسک اصلی برمی‌گرداند حذف می‌کند
+func (sd *ScopedDriver) unscope(p string) string {
+	if sd.prefix == "" {
+		return p
+	}
+	return strings.TrimPrefix(p, sd.prefix+"/")
+}
+
+func (sd *ScopedDriver) Put(path string, content io.ReadSeeker) error {
+	scoped, err := sd.path(path)
+	if err != nil {
+		return err
+	}
+	return sd.disk.Put(scoped, content)
+}
+
+func (sd *ScopedDriver) PutStream(path string, content io.Reader) error {
+	scoped, err := sd.path(path)
+	if err != nil {
+		return err
+	}
+	return sd.disk.PutStream(scoped, content)
+}
+
+func (sd *ScopedDriver) Get(path string) (io.Reader, error) {
+	scoped, err := sd.path(path)
+	if err != nil {
+		return nil, err
+	}
+	return sd.disk.Get(scoped)
+}
+
+func (sd *ScopedDriver) ReadStream(path string) (io.ReadCloser, error) {
+	scoped, err := sd.path(path)
+	if err != nil {
+		return nil, err
+	}
+	return sd.disk.ReadStream(scoped)
+}
+
+func (sd *ScopedDriver) ReadRange(path string, offset int64, length int64) (io.ReadCloser, error) {
+	scoped, err := sd.path(path)
+	if err != nil {
+		return nil, err
+	}
+	return ReadRange(sd.disk, scoped, offset, length)
+}
+
+func (sd *ScopedDriver) Delete(path string) error {
+	scoped, err := sd.path(path)
+	if err != nil {
+		return err
+	}
+	return sd.disk.Delete(scoped)
+}
+
+func (sd *ScopedDriver) Exists(path string) (bool, error) {
+	scoped, err := sd.path(path)
+	if err != nil {
+		return false, err
+	}
+	return sd.disk.Exists(scoped)
+}
+
+func (sd *ScopedDriver) List(directory string) ([]string, error) {
+	scoped, err := sd.path(directory)
+	if err != nil {
+		return nil, err
+	}
+	return sd.disk.List(scoped)
+}
+
+func (sd *ScopedDriver) Files(directory string, recursive bool) ([]string, error) {
+	return listFiles(sd, directory, recursive)
+}
+
+func (sd *ScopedDriver) Directories(directory string) ([]string, error) {
+	return listDirectories(sd, directory)
+}
+
+// ListContents مسیرهای دیسک اصلی را بدون پیشوند برمی‌گرداند؛ cursor بدون تغییر به دیسک اصلی داده می‌شود
+func (sd *ScopedDriver) ListContents(directory string, recursive bool, cursor string, limit int) (Page, error) {
+	scoped, err := sd.path(directory)
+	if err != nil {
+		return Page{}, err
+	}
+	page, err := sd.disk.ListContents(scoped, recursive, cursor, limit)
+	if err != nil {
+		return Page{}, err
+	}
+	for i := range page.Entries {
+		page.Entries[i].Path = sd.unscope(page.Entries[i].Path)
+	}
+	return page, nil
+}
+
+func (sd *ScopedDriver) Missing(path string) (bool, error) {
+	scoped, err := sd.path(path)
+	if err != nil {
+		return false, err
+	}
+	return sd.disk.Missing(scoped)
+}
+
+func (sd *ScopedDriver) Download(path string) (io.Reader, error) {
+	scoped, err := sd.path(path)
+	if err != nil {
+		return nil, err
+	}
+	return sd.disk.Download(scoped)
+}
+
+func (sd *ScopedDriver) URL(path string) (string, error) {
+	scoped, err := sd.path(path)
+	if err != nil {
+		return "", err
+	}
+	return sd.disk.URL(scoped)
+}
+
+func (sd *ScopedDriver) TemporaryURL(path string, expiresIn int64) (string, error) {
+	scoped, err := sd.path(path)
+	if err != nil {
+		return "", err
+	}
+	return sd.disk.TemporaryURL(scoped, expiresIn)
+}
+
+func (sd *ScopedDriver) Size(path string) (int64, error) {
+	scoped, err := sd.path(path)
+	if err != nil {
+		return 0, err
+	}
+	return sd.disk.Size(scoped)
+}
+
+func (sd *ScopedDriver) Metadata(path string) (Metadata, error) {
+	scoped, err := sd.path(path)
+	if err != nil {
+		return Metadata{}, err
+	}
+	metadata, err := sd.disk.Metadata(scoped)
+	if err != nil {
+		return Metadata{}, err
+	}
+	metadata.Path = path
+	return metadata, nil
+}
+
+func (sd *ScopedDriver) Stat(path string) (Metadata, error) {
+	scoped, err := sd.path(path)
+	if err != nil {
+		return Metadata{}, err
+	}
+	metadata, err := Stat(sd.disk, scoped)
+	if err != nil {
+		return Metadata{}, err
+	}
+	metadata.Path = path
+	return metadata, nil
+}
+
+func (sd *ScopedDriver) SetVisibility(path string, visibility Visibility) error {
+	scoped, err := sd.path(path)
+	if err != nil {
+		return err
+	}
+	return sd.disk.SetVisibility(scoped, visibility)
+}
+
+func (sd *ScopedDriver) Visibility(path string) (Visibility, error) {
+	scoped, err := sd.path(path)
+	if err != nil {
+		return "", err
+	}
+	return sd.disk.Visibility(scoped)
+}
+
+func (sd *ScopedDriver) Copy(sourcePath string, destinationPath string) error {
+	source, err := sd.path(sourcePath)
+	if err != nil {
+		return err
+	}
+	destination, err := sd.path(destinationPath)
+	if err != nil {
+		return err
+	}
+	return sd.disk.Copy(source, destination)
+}
+
+func (sd *ScopedDriver) Move(sourcePath string, destinationPath string) error {
+	source, err := sd.path(sourcePath)
+	if err != nil {
+		return err
+	}
+	destination, err := sd.path(destinationPath)
+	if err != nil {
+		return err
+	}
+	return sd.disk.Move(source, destination)
+}
+
+func (sd *ScopedDriver) MakeDirectory(path string) error {
+	scoped, err := sd.path(path)
+	if err != nil {
+		return err
+	}
+	return sd.disk.MakeDirectory(scoped)
+}
+
+func (sd *ScopedDriver) DeleteDirectory(path string) error {
+	scoped, err := sd.path(path)
+	if err != nil {
+		return err
+	}
+	if scoped == sd.prefix {
+		return fmt.Errorf("refusing to delete the storage root")
+	}
+	return sd.disk.DeleteDirectory(scoped)
+}
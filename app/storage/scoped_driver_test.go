@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopedDriver(t *testing.T) {
+	disk := NewMemoryDriver()
+	scoped := NewScopedDriver(disk, "/uploads/")
+
+	assert.NoError(t, scoped.Put("avatars/1.png", strings.NewReader("png")))
+	exists, _ := disk.Exists("uploads/avatars/1.png")
+	assert.True(t, exists)
+
+	reader, err := scoped.Get("avatars/1.png")
+	assert.NoError(t, err)
+	content, _ := io.ReadAll(reader)
+	assert.Equal(t, "png", string(content))
+
+	meta, err := scoped.Metadata("avatars/1.png")
+	assert.NoError(t, err)
+	assert.Equal(t, "avatars/1.png", meta.Path)
+
+	files, err := scoped.List("")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"avatars"}, files)
+
+	url, err := scoped.URL("avatars/1.png")
+	assert.NoError(t, err)
+	assert.Equal(t, "/uploads/avatars/1.png", url)
+
+	assert.NoError(t, scoped.Move("avatars/1.png", "avatars/2.png"))
+	exists, _ = disk.Exists("uploads/avatars/2.png")
+	assert.True(t, exists)
+
+	// مسیرها نمی‌توانند از پیشوند خارج شوند
+	assert.NoError(t, disk.Put("secret.txt", strings.NewReader("secret")))
+	_, err = scoped.Get("../secret.txt")
+	assert.ErrorIs(t, err, ErrPathOutsideRoot)
+	assert.ErrorIs(t, scoped.Copy("../secret.txt", "leak.txt"), ErrPathOutsideRoot)
+	assert.Error(t, scoped.DeleteDirectory(""))
+
+	assert.NoError(t, scoped.DeleteDirectory("avatars"))
+	exists, _ = disk.Exists("secret.txt")
+	assert.True(t, exists)
+}
+
+func TestScopedDriver_Listing(t *testing.T) {
+	disk := NewMemoryDriver()
+	assert.NoError(t, disk.Put("secret.txt", strings.NewReader("s")))
+	scoped := NewScopedDriver(disk, "uploads")
+	assert.NoError(t, scoped.Put("a.txt", strings.NewReader("a")))
+	assert.NoError(t, scoped.Put("b/c.txt", strings.NewReader("c")))
+
+	files, err := scoped.List("")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.txt", "b"}, files)
+
+	files, err = scoped.Files("", true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.txt", "b/c.txt"}, files)
+
+	directories, err := scoped.Directories("")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b"}, directories)
+}
@@ -0,0 +1,587 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SFTPConfig پیکربندی اتصال به سرور SFTP
+type SFTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	// Password برای احراز هویت با رمز عبور
+	Password string
+	// PrivateKey محتوای PEM کلید خصوصی یا مسیر فایل آن برای احراز هویت با کلید
+	PrivateKey string
+	Passphrase string
+	// HostKey کلید عمومی سرور در قالب authorized_keys؛ به جای آن می‌توان KnownHosts را مشخص کرد
+	HostKey    string
+	KnownHosts string
+	// InsecureIgnoreHostKey بررسی کلید سرور را غیرفعال می‌کند و فقط برای محیط توسعه مناسب است
+	InsecureIgnoreHostKey bool
+	// Root دایرکتوری ریشه دیسک روی سرور
+	Root string
+	// URL آدرس عمومی فایل‌ها در صورت وجود
+	URL            string
+	Timeout        time.Duration
+	MaxConnections int
+	Permissions    Permissions
+}
+
+// SFTPDriver فایل‌ها را روی یک سرور SFTP نگهداری می‌کند
+type SFTPDriver struct {
+	config SFTPConfig
+	pool   *pool[*sftpConn]
+}
+
+type sftpConn struct {
+	ssh    *ssh.Client
+	client *sftp.Client
+}
+
+func NewSFTPDriver(config SFTPConfig) (*SFTPDriver, error) {
+	if config.Host == "" {
+		return nil, fmt.Errorf("sftp host is required")
+	}
+	if config.Port == 0 {
+		config.Port = 22
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultRemoteTimeout
+	}
+	if config.Permissions.File == nil || config.Permissions.Dir == nil {
+		config.Permissions = DefaultPermissions()
+	}
+
+	clientConfig, err := sshClientConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	addr := net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+	p := newPool[*sftpConn](config.MaxConnections, config.Timeout)
+	p.dial = func() (*sftpConn, error) {
+		sshClient, err := ssh.Dial("tcp", addr, clientConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to sftp server: %w", err)
+		}
+		client, err := sftp.NewClient(sshClient)
+		if err != nil {
+			sshClient.Close()
+			return nil, fmt.Errorf("failed to start sftp session: %w", err)
+		}
+		return &sftpConn{ssh: sshClient, client: client}, nil
+	}
+	p.check = func(conn *sftpConn) error {
+		_, err := conn.client.Getwd()
+		return err
+	}
+	p.close = func(conn *sftpConn) error {
+		conn.client.Close()
+		return conn.ssh.Close()
+	}
+	p.broken = sftpConnectionError
+
+	return &SFTPDriver{config: config, pool: p}, nil
+}
+
+// sshClientConfig روش‌های احراز هویت و بررسی کلید سرور را از پیکربندی می‌سازد
+func sshClientConfig(config SFTPConfig) (*ssh.ClientConfig, error) {
+	var auth []ssh.AuthMethod
+	if config.PrivateKey != "" {
+		key := []byte(config.PrivateKey)
+		if !strings.Contains(config.PrivateKey, "PRIVATE KEY") {
+			var err error
+			if key, err = os.ReadFile(config.PrivateKey); err != nil {
+				return nil, fmt.Errorf("failed to read sftp private key: %w", err)
+			}
+		}
+
+		var signer ssh.Signer
+		var err error
+		if config.Passphrase != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(config.Passphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey(key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid sftp private key: %w", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if config.Password != "" {
+		auth = append(auth, ssh.Password(config.Password))
+	}
+	if len(auth) == 0 {
+		return nil, fmt.Errorf("sftp password or private key is required")
+	}
+
+	var hostKeyCallback ssh.HostKeyCallback
+	switch {
+	case config.HostKey != "":
+		hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(config.HostKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid sftp host key: %w", err)
+		}
+		hostKeyCallback = ssh.FixedHostKey(hostKey)
+	case config.KnownHosts != "":
+		callback, err := knownhosts.New(config.KnownHosts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read known hosts: %w", err)
+		}
+		hostKeyCallback = callback
+	case config.InsecureIgnoreHostKey:
+		hostKeyCallback = ssh.InsecureIgnoreHostKey()
+	default:
+		return nil, fmt.Errorf("sftp host_key or known_hosts is required")
+	}
+
+	return &ssh.ClientConfig{
+		User:            config.Username,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         config.Timeout,
+	}, nil
+}
+
+// sftpConnectionError مشخص می‌کند که خطا ناشی از قطع شدن اتصال است؛ خطاهای پاسخ سرور (مثلا نبود فایل) اتصال را خراب نمی‌کنند
+func sftpConnectionError(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, io.EOF) ||
+		errors.Is(err, sftp.ErrSSHFxConnectionLost) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.As(err, &netErr)
+}
+
+func (sd *SFTPDriver) path(p string) (string, error) {
+	return remoteJoin(sd.config.Root, p)
+}
+
+// Close اتصال‌های باز را می‌بندد
+func (sd *SFTPDriver) Close() error {
+	return sd.pool.Close()
+}
+
+func (sd *SFTPDriver) Put(path string, content io.ReadSeeker) error {
+	return sd.PutStream(path, content)
+}
+
+func (sd *SFTPDriver) PutStream(path string, content io.Reader) error {
+	filePath, err := sd.path(path)
+	if err != nil {
+		return err
+	}
+	return sd.pool.with(func(conn *sftpConn) error {
+		return sd.write(conn.client, filePath, content)
+	})
+}
+
+// write محتوا را در یک فایل موقت می‌نویسد و سپس آن را جایگزین فایل مقصد می‌کند
+func (sd *SFTPDriver) write(client *sftp.Client, filePath string, content io.Reader) error {
+	dir := path.Dir(filePath)
+	if err := client.MkdirAll(dir); err != nil {
+		return err
+	}
+
+	tmpPath := path.Join(dir, fmt.Sprintf(".tmp-%s-%d", path.Base(filePath), time.Now().UnixNano()))
+	file, err := client.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, content); err != nil {
+		file.Close()
+		client.Remove(tmpPath)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		client.Remove(tmpPath)
+		return err
+	}
+	if err := client.Chmod(tmpPath, sd.config.Permissions.File[VisibilityPublic]); err != nil {
+		client.Remove(tmpPath)
+		return err
+	}
+	if err := sftpRename(client, tmpPath, filePath); err != nil {
+		client.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// sftpRename از posix-rename برای جایگزینی فایل موجود استفاده می‌کند و در سرورهایی که آن را ندارند
+// ابتدا فایل مقصد را حذف می‌کند
+func sftpRename(client *sftp.Client, oldPath string, newPath string) error {
+	if _, ok := client.HasExtension("posix-rename@openssh.com"); ok {
+		return client.PosixRename(oldPath, newPath)
+	}
+	if err := client.Remove(newPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return client.Rename(oldPath, newPath)
+}
+
+// Get کل محتوای فایل را می‌خواند و فایل را می‌بندد؛ برای فایل‌های بزرگ از ReadStream استفاده کنید
+func (sd *SFTPDriver) Get(path string) (io.Reader, error) {
+	stream, err := sd.ReadStream(path)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	data, err := io.ReadAll(stream)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
+// ReadStream اتصال را تا زمان بسته شدن stream در اختیار نگه می‌دارد
+func (sd *SFTPDriver) ReadStream(path string) (io.ReadCloser, error) {
+	filePath, err := sd.path(path)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := sd.pool.get()
+	if err != nil {
+		return nil, err
+	}
+	file, err := conn.client.Open(filePath)
+	if err != nil {
+		sd.pool.put(conn, err)
+		return nil, err
+	}
+	return &pooledReader[*sftpConn]{reader: file, pool: sd.pool, conn: conn}, nil
+}
+
+func (sd *SFTPDriver) ReadRange(path string, offset int64, length int64) (io.ReadCloser, error) {
+	filePath, err := sd.path(path)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := sd.pool.get()
+	if err != nil {
+		return nil, err
+	}
+	file, err := conn.client.Open(filePath)
+	if err == nil {
+		if _, err = file.Seek(offset, io.SeekStart); err != nil {
+			file.Close()
+		}
+	}
+	if err != nil {
+		sd.pool.put(conn, err)
+		return nil, err
+	}
+	return limitStream(&pooledReader[*sftpConn]{reader: file, pool: sd.pool, conn: conn}, length), nil
+}
+
+func (sd *SFTPDriver) Delete(path string) error {
+	filePath, err := sd.path(path)
+	if err != nil {
+		return err
+	}
+	return sd.pool.with(func(conn *sftpConn) error {
+		return conn.client.Remove(filePath)
+	})
+}
+
+func (sd *SFTPDriver) stat(path string) (os.FileInfo, error) {
+	filePath, err := sd.path(path)
+	if err != nil {
+		return nil, err
+	}
+	var fileInfo os.FileInfo
+	err = sd.pool.with(func(conn *sftpConn) error {
+		fileInfo, err = conn.client.Stat(filePath)
+		return err
+	})
+	return fileInfo, err
+}
+
+func (sd *SFTPDriver) Exists(path string) (bool, error) {
+	_, err := sd.stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (sd *SFTPDriver) List(directory string) ([]string, error) {
+	dirPath, err := sd.path(directory)
+	if err != nil {
+		return nil, err
+	}
+	var fileNames []string
+	err = sd.pool.with(func(conn *sftpConn) error {
+		files, err := conn.client.ReadDir(dirPath)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			fileNames = append(fileNames, file.Name())
+		}
+		return nil
+	})
+	return fileNames, err
+}
+
+func (sd *SFTPDriver) Files(directory string, recursive bool) ([]string, error) {
+	return listFiles(sd, directory, recursive)
+}
+
+func (sd *SFTPDriver) Directories(directory string) ([]string, error) {
+	return listDirectories(sd, directory)
+}
+
+func (sd *SFTPDriver) ListContents(directory string, recursive bool, cursor string, limit int) (Page, error) {
+	dirPath, err := sd.path(directory)
+	if err != nil {
+		return Page{}, err
+	}
+	root := sd.root()
+	entry := func(p string, info os.FileInfo) Entry {
+		return Entry{
+			Path:         relativePath(root, p),
+			IsDir:        info.IsDir(),
+			Size:         info.Size(),
+			LastModified: info.ModTime(),
+		}
+	}
+
+	var entries []Entry
+	err = sd.pool.with(func(conn *sftpConn) error {
+		if !recursive {
+			files, err := conn.client.ReadDir(dirPath)
+			if err != nil {
+				return err
+			}
+			for _, file := range files {
+				entries = append(entries, entry(path.Join(dirPath, file.Name()), file))
+			}
+			return nil
+		}
+
+		walker := conn.client.Walk(dirPath)
+		for walker.Step() {
+			if err := walker.Err(); err != nil {
+				return err
+			}
+			if !walker.Stat().IsDir() {
+				entries = append(entries, entry(walker.Path(), walker.Stat()))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return Page{}, err
+	}
+	return paginate(entries, cursor, limit), nil
+}
+
+func (sd *SFTPDriver) Missing(path string) (bool, error) {
+	exists, err := sd.Exists(path)
+	if err != nil {
+		return false, err
+	}
+	return !exists, nil
+}
+
+func (sd *SFTPDriver) Download(path string) (io.Reader, error) {
+	return sd.Get(path)
+}
+
+// URL فقط زمانی که آدرس عمومی برای دیسک تنظیم شده باشد قابل استفاده است
+func (sd *SFTPDriver) URL(path string) (string, error) {
+	if sd.config.URL == "" {
+		return "", ErrUnsupported
+	}
+	return strings.TrimSuffix(sd.config.URL, "/") + "/" + strings.TrimPrefix(path, "/"), nil
+}
+
+func (sd *SFTPDriver) TemporaryURL(path string, expiresIn int64) (string, error) {
+	return "", ErrUnsupported
+}
+
+func (sd *SFTPDriver) Size(path string) (int64, error) {
+	fileInfo, err := sd.stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return fileInfo.Size(), nil
+}
+
+func (sd *SFTPDriver) Metadata(path string) (Metadata, error) {
+	filePath, err := sd.path(path)
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	var metadata Metadata
+	err = sd.pool.with(func(conn *sftpConn) error {
+		file, err := conn.client.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		fileInfo, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		if fileInfo.IsDir() {
+			return fmt.Errorf("%s: %w", path, ErrIsDirectory)
+		}
+
+		mimeType, checksum, err := sniffAndHash(path, file)
+		if err != nil {
+			return err
+		}
+
+		metadata = Metadata{
+			Path:         path,
+			Size:         fileInfo.Size(),
+			LastModified: fileInfo.ModTime(),
+			MimeType:     mimeType,
+			Checksum:     checksum,
+			Visibility:   visibilityOf(sd.config.Permissions.File, fileInfo.Mode()),
+		}
+		return nil
+	})
+	return metadata, err
+}
+
+func (sd *SFTPDriver) Stat(path string) (Metadata, error) {
+	fileInfo, err := sd.stat(path)
+	if err != nil {
+		return Metadata{}, err
+	}
+	if fileInfo.IsDir() {
+		return Metadata{}, fmt.Errorf("%s: %w", path, ErrIsDirectory)
+	}
+	return Metadata{
+		Path:         path,
+		Size:         fileInfo.Size(),
+		LastModified: fileInfo.ModTime(),
+		MimeType:     mimeTypeByExtension(path),
+	}, nil
+}
+
+func (sd *SFTPDriver) SetVisibility(path string, visibility Visibility) error {
+	if err := validateVisibility(visibility); err != nil {
+		return err
+	}
+	filePath, err := sd.path(path)
+	if err != nil {
+		return err
+	}
+	return sd.pool.with(func(conn *sftpConn) error {
+		fileInfo, err := conn.client.Stat(filePath)
+		if err != nil {
+			return err
+		}
+		modes := sd.config.Permissions.File
+		if fileInfo.IsDir() {
+			modes = sd.config.Permissions.Dir
+		}
+		return conn.client.Chmod(filePath, modes[visibility])
+	})
+}
+
+func (sd *SFTPDriver) Visibility(path string) (Visibility, error) {
+	fileInfo, err := sd.stat(path)
+	if err != nil {
+		return "", err
+	}
+	modes := sd.config.Permissions.File
+	if fileInfo.IsDir() {
+		modes = sd.config.Permissions.Dir
+	}
+	return visibilityOf(modes, fileInfo.Mode()), nil
+}
+
+// Copy فایل را از طریق همان اتصال می‌خواند و در مقصد می‌نویسد
+func (sd *SFTPDriver) Copy(sourcePath string, destinationPath string) error {
+	srcPath, err := sd.path(sourcePath)
+	if err != nil {
+		return err
+	}
+	dstPath, err := sd.path(destinationPath)
+	if err != nil {
+		return err
+	}
+	return sd.pool.with(func(conn *sftpConn) error {
+		input, err := conn.client.Open(srcPath)
+		if err != nil {
+			return err
+		}
+		defer input.Close()
+		return sd.write(conn.client, dstPath, input)
+	})
+}
+
+func (sd *SFTPDriver) Move(sourcePath string, destinationPath string) error {
+	srcPath, err := sd.path(sourcePath)
+	if err != nil {
+		return err
+	}
+	dstPath, err := sd.path(destinationPath)
+	if err != nil {
+		return err
+	}
+	return sd.pool.with(func(conn *sftpConn) error {
+		if err := conn.client.MkdirAll(path.Dir(dstPath)); err != nil {
+			return err
+		}
+		return sftpRename(conn.client, srcPath, dstPath)
+	})
+}
+
+func (sd *SFTPDriver) MakeDirectory(path string) error {
+	dirPath, err := sd.path(path)
+	if err != nil {
+		return err
+	}
+	return sd.pool.with(func(conn *sftpConn) error {
+		if err := conn.client.MkdirAll(dirPath); err != nil {
+			return err
+		}
+		return conn.client.Chmod(dirPath, sd.config.Permissions.Dir[VisibilityPublic])
+	})
+}
+
+func (sd *SFTPDriver) DeleteDirectory(path string) error {
+	dirPath, err := sd.path(path)
+	if err != nil {
+		return err
+	}
+	if dirPath == sd.root() {
+		return fmt.Errorf("refusing to delete the storage root")
+	}
+	return sd.pool.with(func(conn *sftpConn) error {
+		fileInfo, err := conn.client.Stat(dirPath)
+		if err != nil {
+			return err
+		}
+		if !fileInfo.IsDir() {
+			return fmt.Errorf("%s is not a directory", path)
+		}
+		return conn.client.RemoveAll(dirPath)
+	})
+}
+
+func (sd *SFTPDriver) root() string {
+	return path.Clean("/" + sd.config.Root)
+}
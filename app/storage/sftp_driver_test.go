@@ -0,0 +1,330 @@
+package storage
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+// sftpTestServer یک سرور SSH درون برنامه با زیرسیستم SFTP که فایل‌ها را روی دیسک محلی نگه می‌دارد
+type sftpTestServer struct {
+	addr      string
+	hostKey   ssh.PublicKey
+	clientKey []byte
+	root      string
+	mutex     sync.Mutex
+	logins    int
+	conns     []net.Conn
+}
+
+func newSFTPTestServer(t *testing.T) *sftpTestServer {
+	_, hostPrivate, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	hostSigner, err := ssh.NewSignerFromKey(hostPrivate)
+	assert.NoError(t, err)
+
+	clientPublic, clientPrivate, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	authorizedKey, err := ssh.NewPublicKey(clientPublic)
+	assert.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(clientPrivate, "")
+	assert.NoError(t, err)
+
+	server := &sftpTestServer{
+		hostKey:   hostSigner.PublicKey(),
+		clientKey: pem.EncodeToMemory(block),
+		root:      t.TempDir(),
+	}
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() == "weiser" && string(password) == "secret" {
+				return nil, nil
+			}
+			return nil, errors.New("invalid password")
+		},
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) == string(authorizedKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("unknown key")
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	server.addr = listener.Addr().String()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn, config)
+		}
+	}()
+	return server
+}
+
+func (s *sftpTestServer) serve(conn net.Conn, config *ssh.ServerConfig) {
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	s.mutex.Lock()
+	s.logins++
+	s.conns = append(s.conns, conn)
+	s.mutex.Unlock()
+
+	go ssh.DiscardRequests(requests)
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go func() {
+			for req := range channelRequests {
+				ok := req.Type == "subsystem" && string(req.Payload[4:]) == "sftp"
+				req.Reply(ok, nil)
+				if ok {
+					server, err := sftp.NewServer(channel)
+					if err != nil {
+						channel.Close()
+						return
+					}
+					server.Serve()
+					server.Close()
+				}
+			}
+		}()
+	}
+}
+
+func (s *sftpTestServer) loginCount() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.logins
+}
+
+// dropConnections تمام اتصال‌های فعلی را از سمت سرور قطع می‌کند
+func (s *sftpTestServer) dropConnections() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+func (s *sftpTestServer) config() SFTPConfig {
+	host, port, _ := net.SplitHostPort(s.addr)
+	portNumber, _ := net.LookupPort("tcp", port)
+	return SFTPConfig{
+		Host:     host,
+		Port:     portNumber,
+		Username: "weiser",
+		Password: "secret",
+		HostKey:  string(ssh.MarshalAuthorizedKey(s.hostKey)),
+		Root:     s.root,
+		Timeout:  5 * time.Second,
+	}
+}
+
+func TestSFTPDriver(t *testing.T) {
+	server := newSFTPTestServer(t)
+	driver, err := NewSFTPDriver(server.config())
+	assert.NoError(t, err)
+	defer driver.Close()
+
+	// دایرکتوری‌های والد به صورت خودکار ساخته می‌شوند
+	assert.NoError(t, driver.Put("reports/2024/jan.csv", strings.NewReader("a,b\n1,2\n")))
+	content, err := os.ReadFile(filepath.Join(server.root, "reports", "2024", "jan.csv"))
+	assert.NoError(t, err)
+	assert.Equal(t, "a,b\n1,2\n", string(content))
+
+	reader, err := driver.Get("reports/2024/jan.csv")
+	assert.NoError(t, err)
+	data, _ := io.ReadAll(reader)
+	assert.Equal(t, "a,b\n1,2\n", string(data))
+
+	assert.NoError(t, driver.PutStream("reports/2024/jan.csv", strings.NewReader("replaced")))
+	size, err := driver.Size("reports/2024/jan.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8), size)
+
+	exists, err := driver.Exists("reports/2024")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	missing, err := driver.Missing("reports/2025")
+	assert.NoError(t, err)
+	assert.True(t, missing)
+
+	meta, err := driver.Metadata("reports/2024/jan.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8), meta.Size)
+	assert.Equal(t, "text/csv; charset=utf-8", meta.MimeType)
+	assert.Equal(t, VisibilityPublic, meta.Visibility)
+
+	assert.NoError(t, driver.SetVisibility("reports/2024/jan.csv", VisibilityPrivate))
+	visibility, err := driver.Visibility("reports/2024/jan.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, VisibilityPrivate, visibility)
+
+	assert.NoError(t, driver.Copy("reports/2024/jan.csv", "backup/jan.csv"))
+	assert.NoError(t, driver.Move("reports/2024/jan.csv", "archive/jan.csv"))
+	files, err := driver.List("")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"reports", "backup", "archive"}, files)
+
+	stream, err := driver.ReadStream("backup/jan.csv")
+	assert.NoError(t, err)
+	data, _ = io.ReadAll(stream)
+	assert.NoError(t, stream.Close())
+	assert.Equal(t, "replaced", string(data))
+
+	assert.NoError(t, driver.MakeDirectory("empty/nested"))
+	assert.NoError(t, driver.DeleteDirectory("reports"))
+	assert.NoError(t, driver.Delete("archive/jan.csv"))
+	files, err = driver.List("")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"backup", "archive", "empty"}, files)
+
+	_, err = driver.Get("missing.txt")
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+	_, err = driver.URL("backup/jan.csv")
+	assert.ErrorIs(t, err, ErrUnsupported)
+
+	// تمام عملیات‌ها از یک اتصال مشترک استفاده کرده‌اند
+	assert.Equal(t, 1, server.loginCount())
+}
+
+func TestSFTPDriver_Listing(t *testing.T) {
+	server := newSFTPTestServer(t)
+	driver, err := NewSFTPDriver(server.config())
+	assert.NoError(t, err)
+	defer driver.Close()
+
+	for _, name := range []string{"a.txt", "c/d.txt", "c/e/f.txt"} {
+		assert.NoError(t, driver.Put(name, strings.NewReader(name)))
+	}
+
+	files, err := driver.Files("", true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.txt", "c/d.txt", "c/e/f.txt"}, files)
+
+	directories, err := driver.Directories("c")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c/e"}, directories)
+}
+
+func TestSFTPDriver_Confinement(t *testing.T) {
+	server := newSFTPTestServer(t)
+	driver, err := NewSFTPDriver(server.config())
+	assert.NoError(t, err)
+	defer driver.Close()
+
+	assert.ErrorIs(t, driver.Put("../escape.txt", strings.NewReader("x")), ErrPathOutsideRoot)
+	_, err = driver.Get("a/../../escape.txt")
+	assert.ErrorIs(t, err, ErrPathOutsideRoot)
+	assert.Error(t, driver.DeleteDirectory(""))
+	_, err = os.Stat(filepath.Join(filepath.Dir(server.root), "escape.txt"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSFTPDriver_KeyAuthentication(t *testing.T) {
+	server := newSFTPTestServer(t)
+	config := server.config()
+	config.Password = ""
+	config.PrivateKey = string(server.clientKey)
+
+	driver, err := NewSFTPDriver(config)
+	assert.NoError(t, err)
+	defer driver.Close()
+	assert.NoError(t, driver.Put("key.txt", strings.NewReader("key")))
+
+	// کلید خصوصی می‌تواند از فایل نیز خوانده شود
+	keyFile := filepath.Join(t.TempDir(), "id_ed25519")
+	assert.NoError(t, os.WriteFile(keyFile, server.clientKey, 0600))
+	config.PrivateKey = keyFile
+	driver, err = NewSFTPDriver(config)
+	assert.NoError(t, err)
+	defer driver.Close()
+	exists, err := driver.Exists("key.txt")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestSFTPDriver_HostKeyVerification(t *testing.T) {
+	server := newSFTPTestServer(t)
+	config := server.config()
+
+	otherPublic, _, _ := ed25519.GenerateKey(rand.Reader)
+	otherKey, _ := ssh.NewPublicKey(otherPublic)
+	config.HostKey = string(ssh.MarshalAuthorizedKey(otherKey))
+
+	driver, err := NewSFTPDriver(config)
+	assert.NoError(t, err)
+	_, err = driver.Exists("a.txt")
+	assert.Error(t, err)
+
+	config.HostKey = ""
+	_, err = NewSFTPDriver(config)
+	assert.Error(t, err)
+
+	config.Password = ""
+	config.InsecureIgnoreHostKey = true
+	_, err = NewSFTPDriver(config)
+	assert.Error(t, err)
+}
+
+func TestSFTPDriver_Pool(t *testing.T) {
+	server := newSFTPTestServer(t)
+	config := server.config()
+	config.MaxConnections = 1
+	config.Timeout = 200 * time.Millisecond
+
+	driver, err := NewSFTPDriver(config)
+	assert.NoError(t, err)
+	defer driver.Close()
+	assert.NoError(t, driver.Put("a.txt", strings.NewReader("a")))
+
+	// stream باز اتصال را نگه می‌دارد، بنابراین عملیات دیگر پس از timeout با خطا مواجه می‌شود
+	stream, err := driver.ReadStream("a.txt")
+	assert.NoError(t, err)
+	_, err = driver.Exists("a.txt")
+	assert.ErrorIs(t, err, errPoolTimeout)
+	assert.NoError(t, stream.Close())
+	assert.NoError(t, stream.Close())
+
+	exists, err := driver.Exists("a.txt")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	// اتصال قطع شده پیش از استفاده مجدد شناسایی و جایگزین می‌شود
+	server.dropConnections()
+	exists, err = driver.Exists("a.txt")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, 2, server.loginCount())
+}
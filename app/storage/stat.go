@@ -0,0 +1,27 @@
+package storage
+
+import "errors"
+
+// ErrIsDirectory وقتی برگردانده می‌شود که اطلاعات یک فایل از مسیر یک دایرکتوری خواسته شود
+var ErrIsDirectory = errors.New("storage: path is a directory")
+
+// Stater درایورهایی که اطلاعات فایل را بدون خواندن محتوای آن برمی‌گردانند
+type Stater interface {
+	// Stat مانند Metadata است ولی محتوای فایل را نمی‌خواند: نوع MIME از پسوند تعیین می‌شود،
+	// Checksum فقط وقتی پر می‌شود که درایور آن را ذخیره کرده باشد و Visibility خالی است
+	Stat(path string) (Metadata, error)
+}
+
+// Stat اطلاعات فایل را از هر دیسکی بدون خواندن محتوای آن برمی‌گرداند. اگر درایور Stater را
+// پیاده‌سازی نکرده باشد فقط اندازه و نوع MIME فایل مشخص است.
+func Stat(disk Storage, path string) (Metadata, error) {
+	if stater, ok := disk.(Stater); ok {
+		return stater.Stat(path)
+	}
+
+	size, err := disk.Size(path)
+	if err != nil {
+		return Metadata{}, err
+	}
+	return Metadata{Path: path, Size: size, MimeType: mimeTypeByExtension(path)}, nil
+}
@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStat(t *testing.T) {
+	disks := map[string]Storage{
+		"local":  NewLocalDriver(t.TempDir()),
+		"memory": NewMemoryDriver(),
+		"s3":     newTestS3Driver(t),
+		"scoped": NewScopedDriver(NewMemoryDriver(), "uploads"),
+		// دیسک‌هایی که Stater نیستند فقط اندازه فایل را برمی‌گردانند
+		"fallback": struct{ Storage }{NewMemoryDriver()},
+	}
+
+	for name, disk := range disks {
+		t.Run(name, func(t *testing.T) {
+			assert.NoError(t, disk.Put("docs/a.txt", strings.NewReader("hello world")))
+
+			metadata, err := Stat(disk, "docs/a.txt")
+			assert.NoError(t, err)
+			assert.Equal(t, "docs/a.txt", metadata.Path)
+			assert.Equal(t, int64(11), metadata.Size)
+			assert.Equal(t, "text/plain; charset=utf-8", metadata.MimeType)
+			if name != "fallback" {
+				assert.False(t, metadata.LastModified.IsZero())
+			}
+			// فقط S3 checksum را ذخیره می‌کند
+			assert.Equal(t, name == "s3", metadata.Checksum != "")
+
+			_, err = Stat(disk, "docs/missing.txt")
+			assert.Error(t, err)
+		})
+	}
+}
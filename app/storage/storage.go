@@ -7,15 +7,36 @@ import (
 // Storage interface برای مدیریت فایل‌ها
 type Storage interface {
 	Put(path string, content io.ReadSeeker) error
+	// PutStream محتوای یک stream را بدون نیاز به Seek ذخیره می‌کند
+	PutStream(path string, content io.Reader) error
 	Get(path string) (io.Reader, error)
+	// ReadStream فایل را به صورت stream باز می‌کند؛ فراخواننده باید آن را Close کند
+	ReadStream(path string) (io.ReadCloser, error)
 	Delete(path string) error
 	Exists(path string) (bool, error)
+	// List نام فایل‌ها و زیردایرکتوری‌های مستقیم دایرکتوری را برمی‌گرداند
 	List(directory string) ([]string, error)
+	// Files مسیر فایل‌های دایرکتوری را نسبت به ریشه دیسک برمی‌گرداند؛ با recursive فایل‌های زیردایرکتوری‌ها نیز برگردانده می‌شوند
+	Files(directory string, recursive bool) ([]string, error)
+	// Directories مسیر زیردایرکتوری‌های مستقیم دایرکتوری را نسبت به ریشه دیسک برمی‌گرداند
+	Directories(directory string) ([]string, error)
+	// ListContents یک صفحه از فهرست دایرکتوری را برمی‌گرداند؛ برای پیمایش تمام صفحه‌ها از NewIterator استفاده کنید
+	ListContents(directory string, recursive bool, cursor string, limit int) (Page, error)
 	Missing(path string) (bool, error)
 	Download(path string) (io.Reader, error)
 	URL(path string) (string, error)
 	TemporaryURL(path string, expiresIn int64) (string, error)
 	Size(path string) (int64, error)
+	// Metadata اندازه، زمان آخرین تغییر، نوع MIME و checksum فایل را برمی‌گرداند
+	Metadata(path string) (Metadata, error)
+	// SetVisibility سطح دسترسی فایل را تنظیم می‌کند
+	SetVisibility(path string, visibility Visibility) error
+	// Visibility سطح دسترسی فایل را برمی‌گرداند
+	Visibility(path string) (Visibility, error)
 	Copy(sourcePath string, destinationPath string) error
 	Move(sourcePath string, destinationPath string) error
+	// MakeDirectory یک دایرکتوری ایجاد می‌کند
+	MakeDirectory(path string) error
+	// DeleteDirectory دایرکتوری و تمام محتویات آن را حذف می‌کند
+	DeleteDirectory(path string) error
 }
@@ -3,28 +3,33 @@ package storage
 import (
 	"fmt"
 
+	"github.com/mousav1/weiser/app/cache"
+	"github.com/spf13/cast"
 	"github.com/spf13/viper"
 )
 
 // InitializeStorage بارگذاری و ثبت Driverها
 func InitializeStorage(defaultDriverName string, disksConfig *viper.Viper) error {
-	// ثبت Driverها بر اساس پیکربندی
+	builder := &diskBuilder{
+		configs:  make(map[string]map[string]interface{}),
+		drivers:  make(map[string]Storage),
+		building: make(map[string]bool),
+	}
 	for name, diskConfig := range disksConfig.AllSettings() {
-		var driver Storage
-		var err error
+		disk, ok := diskConfig.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid configuration for disk %s", name)
+		}
+		builder.configs[name] = disk
+	}
 
-		disk := diskConfig.(map[string]interface{})
-		switch disk["driver"] {
-		case "local":
-			driver = NewLocalDriver(disk["base_path"].(string))
-		case "s3":
-			driver, err = NewS3Driver(disk["bucket"].(string))
-			if err != nil {
-				return fmt.Errorf("failed to create S3 driver: %w", err)
-			}
-		default:
-			return fmt.Errorf("unknown storage driver: %s", disk["driver"])
+	// ثبت Driverها بر اساس پیکربندی؛ دیسک‌هایی که به دیسک دیگری وابسته‌اند پس از آن ساخته می‌شوند
+	for name := range builder.configs {
+		if _, err := builder.build(name); err != nil {
+			return err
 		}
+	}
+	for name, driver := range builder.drivers {
 		RegisterDriver(name, driver)
 	}
 
@@ -39,3 +44,170 @@ func InitializeStorage(defaultDriverName string, disksConfig *viper.Viper) error
 
 	return nil
 }
+
+// diskBuilder دیسک‌ها را به ترتیب وابستگی می‌سازد
+type diskBuilder struct {
+	configs  map[string]map[string]interface{}
+	drivers  map[string]Storage
+	building map[string]bool
+}
+
+// build دیسک را می‌سازد؛ دیسکی که در پیکربندی نیست ممکن است پیش‌تر با RegisterDriver ثبت شده باشد
+func (b *diskBuilder) build(name string) (Storage, error) {
+	if driver, ok := b.drivers[name]; ok {
+		return driver, nil
+	}
+	disk, ok := b.configs[name]
+	if !ok {
+		if driver, ok := Registry[name]; ok {
+			return driver, nil
+		}
+		return nil, fmt.Errorf("disk not found: %s", name)
+	}
+	if b.building[name] {
+		return nil, fmt.Errorf("disk %s has a circular reference", name)
+	}
+	b.building[name] = true
+	defer delete(b.building, name)
+
+	driver, err := b.newDriver(name, disk)
+	if err != nil {
+		return nil, err
+	}
+	b.drivers[name] = driver
+	return driver, nil
+}
+
+func (b *diskBuilder) newDriver(name string, disk map[string]interface{}) (Storage, error) {
+	var driver Storage
+	var err error
+
+	switch disk["driver"] {
+	case "local":
+		localDriver := NewLocalDriver(disk["base_path"].(string))
+		if permissions, ok := disk["permissions"].(map[string]interface{}); ok {
+			localDriver.Permissions, err = ParsePermissions(permissions)
+			if err != nil {
+				return nil, fmt.Errorf("failed to configure local driver %s: %w", name, err)
+			}
+		}
+		driver = localDriver
+	case "s3":
+		driver, err = NewS3Driver(S3Config{
+			Bucket:       cast.ToString(disk["bucket"]),
+			Region:       cast.ToString(disk["region"]),
+			Endpoint:     cast.ToString(disk["endpoint"]),
+			Key:          cast.ToString(disk["key"]),
+			Secret:       cast.ToString(disk["secret"]),
+			SessionToken: cast.ToString(disk["token"]),
+			UsePathStyle: cast.ToBool(disk["use_path_style"]),
+			PartSize:     cast.ToInt64(disk["part_size"]),
+			Concurrency:  cast.ToInt(disk["concurrency"]),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create S3 driver: %w", err)
+		}
+	case "sftp":
+		config, configErr := sftpConfig(disk)
+		if configErr != nil {
+			return nil, fmt.Errorf("failed to configure SFTP driver %s: %w", name, configErr)
+		}
+		driver, err = NewSFTPDriver(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create SFTP driver: %w", err)
+		}
+	case "ftp":
+		driver, err = NewFTPDriver(ftpConfig(disk))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create FTP driver: %w", err)
+		}
+	case "memory":
+		driver = NewMemoryDriver()
+	case "scoped":
+		inner, err := b.build(cast.ToString(disk["disk"]))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create scoped driver %s: %w", name, err)
+		}
+		driver = NewScopedDriver(inner, cast.ToString(disk["prefix"]))
+	case "readonly":
+		inner, err := b.build(cast.ToString(disk["disk"]))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create readonly driver %s: %w", name, err)
+		}
+		driver = NewReadOnlyDriver(inner)
+	case "mirror":
+		var disks []Storage
+		for _, diskName := range cast.ToStringSlice(disk["disks"]) {
+			inner, err := b.build(diskName)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create mirror driver %s: %w", name, err)
+			}
+			disks = append(disks, inner)
+		}
+		driver, err = NewMirrorDriver(disks...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create mirror driver %s: %w", name, err)
+		}
+	case "cached":
+		inner, err := b.build(cast.ToString(disk["disk"]))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cached driver %s: %w", name, err)
+		}
+		store := cache.GetCacheInstance()
+		if storeName := cast.ToString(disk["store"]); storeName != "" {
+			if store, err = cache.Store(storeName); err != nil {
+				return nil, fmt.Errorf("failed to create cached driver %s: %w", name, err)
+			}
+		}
+		driver, err = NewCachedDriver(inner, store, name, cast.ToDuration(disk["ttl"]))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cached driver %s: %w", name, err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s", disk["driver"])
+	}
+	return driver, nil
+}
+
+// sftpConfig پیکربندی SFTP را از تنظیمات دیسک می‌خواند
+func sftpConfig(disk map[string]interface{}) (SFTPConfig, error) {
+	config := SFTPConfig{
+		Host:                  cast.ToString(disk["host"]),
+		Port:                  cast.ToInt(disk["port"]),
+		Username:              cast.ToString(disk["username"]),
+		Password:              cast.ToString(disk["password"]),
+		PrivateKey:            cast.ToString(disk["private_key"]),
+		Passphrase:            cast.ToString(disk["passphrase"]),
+		HostKey:               cast.ToString(disk["host_key"]),
+		KnownHosts:            cast.ToString(disk["known_hosts"]),
+		InsecureIgnoreHostKey: cast.ToBool(disk["insecure_ignore_host_key"]),
+		Root:                  cast.ToString(disk["root"]),
+		URL:                   cast.ToString(disk["url"]),
+		Timeout:               cast.ToDuration(disk["timeout"]),
+		MaxConnections:        cast.ToInt(disk["max_connections"]),
+		Permissions:           DefaultPermissions(),
+	}
+	if permissions, ok := disk["permissions"].(map[string]interface{}); ok {
+		var err error
+		if config.Permissions, err = ParsePermissions(permissions); err != nil {
+			return SFTPConfig{}, err
+		}
+	}
+	return config, nil
+}
+
+// ftpConfig پیکربندی FTP را از تنظیمات دیسک می‌خواند
+func ftpConfig(disk map[string]interface{}) FTPConfig {
+	return FTPConfig{
+		Host:           cast.ToString(disk["host"]),
+		Port:           cast.ToInt(disk["port"]),
+		Username:       cast.ToString(disk["username"]),
+		Password:       cast.ToString(disk["password"]),
+		TLS:            cast.ToBool(disk["tls"]),
+		DisableEPSV:    cast.ToBool(disk["disable_epsv"]),
+		Root:           cast.ToString(disk["root"]),
+		URL:            cast.ToString(disk["url"]),
+		Timeout:        cast.ToDuration(disk["timeout"]),
+		MaxConnections: cast.ToInt(disk["max_connections"]),
+	}
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mousav1/weiser/app/cache"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitializeStorage(t *testing.T) {
+	config := viper.New()
+	config.SetConfigType("yaml")
+	assert.NoError(t, config.ReadConfig(strings.NewReader(`
+memory:
+  driver: memory
+partners:
+  driver: sftp
+  host: "127.0.0.1"
+  username: "weiser"
+  password: "secret"
+  insecure_ignore_host_key: true
+  root: "/upload"
+  timeout: "5s"
+  max_connections: 2
+  permissions:
+    file:
+      public: "0664"
+legacy:
+  driver: ftp
+  host: "127.0.0.1"
+  port: 2121
+  root: "/files"
+  username: "weiser"
+  password: "secret"
+  tls: true
+  timeout: "10s"
+`)))
+	defer func() {
+		for _, name := range []string{"memory", "partners", "legacy"} {
+			delete(Registry, name)
+		}
+		DefaultDriverName, DefaultDriver = "", nil
+	}()
+
+	assert.NoError(t, InitializeStorage("memory", config))
+	assert.IsType(t, &MemoryDriver{}, DefaultDriver)
+
+	sftpDriver, ok := Registry["partners"].(*SFTPDriver)
+	assert.True(t, ok)
+	assert.Equal(t, 22, sftpDriver.config.Port)
+	assert.Equal(t, 5*time.Second, sftpDriver.config.Timeout)
+	assert.Equal(t, "/upload", sftpDriver.config.Root)
+	assert.Equal(t, 2, cap(sftpDriver.pool.slots))
+	assert.Equal(t, VisibilityPublic, visibilityOf(sftpDriver.config.Permissions.File, 0664))
+
+	ftpDriver, ok := Registry["legacy"].(*FTPDriver)
+	assert.True(t, ok)
+	assert.Equal(t, 2121, ftpDriver.config.Port)
+	assert.True(t, ftpDriver.config.TLS)
+	assert.Equal(t, 10*time.Second, ftpDriver.config.Timeout)
+	assert.Equal(t, defaultRemoteMaxConnections, cap(ftpDriver.pool.slots))
+
+	// مسیرهای FTP نیز به ریشه دیسک محدود هستند
+	_, err := ftpDriver.Get("../etc/passwd")
+	assert.ErrorIs(t, err, ErrPathOutsideRoot)
+
+	config.Set("broken", map[string]interface{}{"driver": "sftp", "host": "127.0.0.1"})
+	assert.Error(t, InitializeStorage("memory", config))
+}
+
+func TestInitializeStorage_Decorators(t *testing.T) {
+	cache.RegisterStore("storage-test", cache.NewCache(cache.NewInMemoryCache(nil), time.Minute))
+	defer cache.Reset()
+
+	config := viper.New()
+	config.SetConfigType("yaml")
+	assert.NoError(t, config.ReadConfig(strings.NewReader(`
+archive:
+  driver: readonly
+  disk: uploads
+uploads:
+  driver: scoped
+  disk: primary
+  prefix: uploads
+primary:
+  driver: memory
+backup:
+  driver: memory
+both:
+  driver: mirror
+  disks: [primary, backup]
+fast:
+  driver: cached
+  disk: both
+  store: storage-test
+  ttl: "1m"
+`)))
+	names := []string{"archive", "uploads", "primary", "backup", "both", "fast"}
+	defer func() {
+		for _, name := range names {
+			delete(Registry, name)
+		}
+		DefaultDriverName, DefaultDriver = "", nil
+	}()
+
+	assert.NoError(t, InitializeStorage("uploads", config))
+	assert.IsType(t, &ScopedDriver{}, DefaultDriver)
+	assert.IsType(t, &CachedDriver{}, Registry["fast"])
+
+	assert.NoError(t, DefaultDriver.Put("a.txt", strings.NewReader("a")))
+	exists, _ := Registry["primary"].Exists("uploads/a.txt")
+	assert.True(t, exists)
+	exists, _ = Registry["archive"].Exists("a.txt")
+	assert.True(t, exists)
+	assert.ErrorIs(t, Registry["archive"].Delete("a.txt"), ErrReadOnly)
+
+	assert.NoError(t, Registry["fast"].Put("b.txt", strings.NewReader("b")))
+	exists, _ = Registry["backup"].Exists("b.txt")
+	assert.True(t, exists)
+
+	config.Set("loop", map[string]interface{}{"driver": "scoped", "disk": "loop"})
+	assert.ErrorContains(t, InitializeStorage("uploads", config), "circular reference")
+
+	config.Set("loop", map[string]interface{}{"driver": "readonly", "disk": "missing"})
+	assert.ErrorContains(t, InitializeStorage("uploads", config), "disk not found: missing")
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/flosch/pongo2"
 	"github.com/gofiber/fiber/v2"
+	"github.com/mousav1/weiser/app/session"
 	"github.com/spf13/viper"
 )
 
@@ -43,7 +44,13 @@ type Pongo2View struct {
 // Render renders the HTML view with the given data
 func (v *HTMLView) Render(c *fiber.Ctx, data ViewData) error {
 	c.Set("Content-Type", "text/html; charset=utf-8")
-	viewData := struct{ Data ViewData }{Data: data}
+	old, flash, token := sessionVariables(c)
+	viewData := struct {
+		Data      ViewData
+		Old       map[string]interface{}
+		Flash     map[string]interface{}
+		CSRFToken string
+	}{Data: data, Old: old, Flash: flash, CSRFToken: token}
 	if err := v.Template.Execute(c, viewData); err != nil {
 		return fmt.Errorf("could not render HTML template: %w", err)
 	}
@@ -53,12 +60,23 @@ func (v *HTMLView) Render(c *fiber.Ctx, data ViewData) error {
 // Render renders the Pongo2 view with the given data
 func (v *Pongo2View) Render(c *fiber.Ctx, data ViewData) error {
 	c.Set("Content-Type", "text/html; charset=utf-8")
-	if err := v.Template.ExecuteWriter(pongo2.Context{"Data": data}, c); err != nil {
+	old, flash, token := sessionVariables(c)
+	if err := v.Template.ExecuteWriter(pongo2.Context{"Data": data, "old": old, "flash": flash, "csrf_token": token}, c); err != nil {
 		return fmt.Errorf("could not render Pongo2 template: %w", err)
 	}
 	return nil
 }
 
+// sessionVariables returns the old input, flash data and CSRF token of the
+// current session, so that forms can be re-filled after a failed validation.
+func sessionVariables(c *fiber.Ctx) (map[string]interface{}, map[string]interface{}, string) {
+	sessionData, err := session.FromContext(c)
+	if err != nil {
+		return map[string]interface{}{}, map[string]interface{}{}, ""
+	}
+	return sessionData.OldInput(), sessionData.FlashData(), sessionData.Token()
+}
+
 // NewView creates a new view based on the template engine configuration
 func NewView(pattern string) (View, error) {
 	engine := viper.GetString("template_engine")
@@ -11,8 +11,13 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/compress"
 	"github.com/mousav1/weiser/app/cache"
+	"github.com/mousav1/weiser/app/console"
+	"github.com/mousav1/weiser/app/cookies"
+	"github.com/mousav1/weiser/app/encryption"
 	kernel "github.com/mousav1/weiser/app/http"
 	middleware "github.com/mousav1/weiser/app/http/middlewares"
+	"github.com/mousav1/weiser/app/http/request"
+	"github.com/mousav1/weiser/app/http/validation"
 	"github.com/mousav1/weiser/app/session"
 	"github.com/mousav1/weiser/app/storage"
 	"github.com/mousav1/weiser/database"
@@ -37,9 +42,16 @@ func SetupApp() (*fiber.App, *fasthttp.Server, error) {
 	log.SetReportCaller(true)
 
 	// set config
-	viper.SetConfigFile("config/config.yaml")
-	if err := viper.ReadInConfig(); err != nil {
-		return nil, nil, fmt.Errorf("failed to read configuration file: %w", err)
+	if err := loadConfig(); err != nil {
+		return nil, nil, err
+	}
+
+	// Apply the cookie defaults of the configuration
+	cookies.LoadConfig()
+
+	// Initialize the encryption service used by encrypted and signed cookies
+	if err := encryption.Initialize(); err != nil {
+		log.Warn("encryption is disabled: ", err)
 	}
 
 	// Connect to the database
@@ -53,13 +65,16 @@ func SetupApp() (*fiber.App, *fasthttp.Server, error) {
 	}
 	defer sqlDB.Close()
 
+	// Let the unique and exists validation rules query the database
+	validation.SetDatabase(db)
+
 	// Initialize the session manager
 	if err := session.InitSessionManager(); err != nil {
 		return nil, nil, fmt.Errorf("failed to initialize session manager: %w", err)
 	}
 	go middleware.StartSessionCleaner()
 
-	// Create a new in-memory cache with a default expiration of 1 minute
+	// Initialize the cache stores with a default expiration of 1 minute
 	err = cache.InitializeCache(time.Minute, nil)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create cache: %w", err)
@@ -73,7 +88,10 @@ func SetupApp() (*fiber.App, *fasthttp.Server, error) {
 	}
 
 	// Create the Fiber app
-	app := fiber.New(fiber.Config{})
+	app := fiber.New(appConfig())
+
+	// Reject bodies over the limit before they are read, see appConfig
+	app.Use(middleware.BodyLimit(app.Config().BodyLimit))
 
 	// add middlewares
 	for _, myMiddleware := range kernel.Middleware {
@@ -112,9 +130,7 @@ func SetupApp() (*fiber.App, *fasthttp.Server, error) {
 		port = "3000"
 	}
 
-	server := &fasthttp.Server{
-		Handler: app.Handler(),
-	}
+	server := newServer(app)
 
 	//Log the start of the application
 	log.WithFields(log.Fields{
@@ -124,6 +140,73 @@ func SetupApp() (*fiber.App, *fasthttp.Server, error) {
 	return app, server, nil
 }
 
+// appConfig returns the configuration of the Fiber app. The body limit leaves
+// room for an upload of upload.max_size bytes and the rest of its multipart
+// form, and bodies over the server's in-memory limit are streamed, so that
+// resumable upload chunks are not buffered whole.
+func appConfig() fiber.Config {
+	limit := fiber.DefaultBodyLimit
+	if maxSize := viper.GetInt("upload.max_size"); maxSize > 0 && maxSize+formOverhead > limit {
+		limit = maxSize + formOverhead
+	}
+
+	return fiber.Config{
+		ErrorHandler:                 request.ErrorHandler,
+		BodyLimit:                    limit,
+		StreamRequestBody:            true,
+		DisablePreParseMultipartForm: true,
+	}
+}
+
+// formOverhead is the room left for the multipart headers and the other
+// fields of an upload form.
+const formOverhead = 1 << 20
+
+// newServer returns the server that serves app with the body limits of its
+// configuration. The app's own settings only apply to app.Listen.
+func newServer(app *fiber.App) *fasthttp.Server {
+	config := app.Config()
+	return &fasthttp.Server{
+		Handler:                      app.Handler(),
+		MaxRequestBodySize:           config.BodyLimit,
+		StreamRequestBody:            config.StreamRequestBody,
+		DisablePreParseMultipartForm: config.DisablePreParseMultipartForm,
+	}
+}
+
+// SetupConsole loads the configuration and the services needed by the
+// console command in args, so that commands such as key:generate run without
+// a cache or storage connection.
+func SetupConsole(args []string) error {
+	if err := loadConfig(); err != nil {
+		return err
+	}
+
+	for _, service := range console.Services(args) {
+		switch service {
+		case console.ServiceCache:
+			if err := cache.InitializeCache(time.Minute, nil); err != nil {
+				return fmt.Errorf("failed to create cache: %w", err)
+			}
+		case console.ServiceStorage:
+			if err := storage.InitializeStorage(viper.GetString("storage.default"), viper.Sub("storage.disks")); err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// loadConfig reads the application configuration file.
+func loadConfig() error {
+	viper.SetConfigFile("config/config.yaml")
+	if err := viper.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read configuration file: %w", err)
+	}
+	return nil
+}
+
 // ShutdownServer gracefully shuts down the server.
 func ShutdownServer(server *fasthttp.Server) {
 	quit := make(chan os.Signal, 1)
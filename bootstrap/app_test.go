@@ -0,0 +1,83 @@
+package bootstrap
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mousav1/weiser/app/file"
+	middleware "github.com/mousav1/weiser/app/http/middlewares"
+	"github.com/mousav1/weiser/app/storage"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+func TestServer_LargeUploads(t *testing.T) {
+	viper.Set("upload.max_size", 20<<20)
+	defer viper.Set("upload.max_size", nil)
+
+	disk := storage.NewMemoryDriver()
+	uploader := file.NewUploader(disk, file.UploadOptions{MaxSize: 20 << 20, StagingPath: t.TempDir()})
+	app := fiber.New(appConfig())
+	app.Use(middleware.BodyLimit(app.Config().BodyLimit))
+	app.Post("/upload", uploader.Handler())
+	uploader.Resumable(app.Group("/uploads"))
+
+	ln := fasthttputil.NewInmemoryListener()
+	server := newServer(app)
+	go server.Serve(ln)
+	defer server.Shutdown()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(context.Context, string, string) (net.Conn, error) {
+			return ln.Dial()
+		},
+	}}
+	send := func(req *http.Request) *http.Response {
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	content := append([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), make([]byte, 6<<20)...)
+
+	// A form upload larger than fasthttp's 4 MB default reaches the handler
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	part, _ := writer.CreateFormFile("file", "photo.png")
+	part.Write(content)
+	writer.Close()
+	req, _ := http.NewRequest(fiber.MethodPost, "http://test/upload", bytes.NewReader(form.Bytes()))
+	req.Header.Set(fiber.HeaderContentType, writer.FormDataContentType())
+	assert.Equal(t, fiber.StatusCreated, send(req).StatusCode)
+
+	// So does a resumable upload chunk of the same size
+	req, _ = http.NewRequest(fiber.MethodPost, "http://test/uploads", nil)
+	req.Header.Set("Tus-Resumable", "1.0.0")
+	req.Header.Set("Upload-Length", strconv.Itoa(len(content)))
+	resp := send(req)
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	location := resp.Header.Get("Location")
+	uploadPath := location[strings.Index(location, "/uploads/"):]
+
+	req, _ = http.NewRequest(fiber.MethodPatch, "http://test"+uploadPath, bytes.NewReader(content))
+	req.Header.Set("Tus-Resumable", "1.0.0")
+	req.Header.Set(fiber.HeaderContentType, "application/offset+octet-stream")
+	req.Header.Set("Upload-Offset", "0")
+	resp = send(req)
+	assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, strconv.Itoa(len(content)), resp.Header.Get("Upload-Offset"))
+
+	// Bodies over the limit are still rejected
+	req, _ = http.NewRequest(fiber.MethodPost, "http://test/upload", bytes.NewReader(make([]byte, 22<<20)))
+	req.Header.Set(fiber.HeaderContentType, writer.FormDataContentType())
+	assert.Equal(t, fiber.StatusRequestEntityTooLarge, send(req).StatusCode)
+}
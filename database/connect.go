@@ -203,7 +203,7 @@ func closeRedis() error {
 
 // migrateAndSeed performs database migrations and seeds.
 func migrateAndSeed() error {
-	if err := DB.AutoMigrate(&models.User{}); err != nil {
+	if err := DB.AutoMigrate(&models.User{}, &models.Session{}); err != nil {
 		return errors.Wrap(err, "failed to migrate the schema")
 	}
 	return nil
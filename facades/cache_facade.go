@@ -1,36 +1,82 @@
 package facades
 
 import (
+	"errors"
 	"time"
 
 	"github.com/mousav1/weiser/app/cache"
 )
 
 // CacheFacade provides a simplified interface to interact with the cache.
-type CacheFacade struct{}
+// The zero value works with the default store.
+type CacheFacade struct {
+	store string
+}
+
+// Store returns a facade bound to the named store.
+func (cf *CacheFacade) Store(name string) *CacheFacade {
+	return &CacheFacade{store: name}
+}
 
 func (cf *CacheFacade) Get(key string, value interface{}) error {
-	return cache.GetCacheInstance().Get(key, value)
+	c, err := cf.resolve()
+	if err != nil {
+		return err
+	}
+	return c.Get(key, value)
 }
 
 func (cf *CacheFacade) Set(key string, value interface{}, expiration time.Duration) error {
-	return cache.GetCacheInstance().Set(key, value, expiration)
+	c, err := cf.resolve()
+	if err != nil {
+		return err
+	}
+	return c.Set(key, value, expiration)
 }
 
 func (cf *CacheFacade) Delete(key string) error {
-	return cache.GetCacheInstance().Delete(key)
+	c, err := cf.resolve()
+	if err != nil {
+		return err
+	}
+	return c.Delete(key)
 }
 
 func (cf *CacheFacade) Exists(key string) (bool, error) {
-	return cache.GetCacheInstance().Exists(key)
+	c, err := cf.resolve()
+	if err != nil {
+		return false, err
+	}
+	return c.Exists(key)
 }
 
 func (cf *CacheFacade) Flush() error {
-	return cache.GetCacheInstance().Flush()
+	c, err := cf.resolve()
+	if err != nil {
+		return err
+	}
+	return c.Flush()
 }
 
 func (cf *CacheFacade) Stats() (cache.CacheStats, error) {
-	return cache.GetCacheInstance().Stats()
+	c, err := cf.resolve()
+	if err != nil {
+		return cache.CacheStats{}, err
+	}
+	return c.Stats()
+}
+
+// resolve returns the store the facade is bound to.
+func (cf *CacheFacade) resolve() (*cache.Cache, error) {
+	if cf.store != "" {
+		return cache.Store(cf.store)
+	}
+
+	c := cache.GetCacheInstance()
+	if c == nil {
+		return nil, errors.New("cache is not initialized")
+	}
+	return c, nil
 }
 
 func NewCacheFacade() *CacheFacade {
@@ -11,8 +11,18 @@ import (
 type CookieFacade struct{}
 
 // Set sets a cookie with the given name, value, and expiration time.
-func (cf *CookieFacade) Set(c *fiber.Ctx, name string, value string, expire time.Time) {
-	cookies.SetCookie(c, name, value, expire)
+func (cf *CookieFacade) Set(c *fiber.Ctx, name string, value string, expire time.Time, opts ...cookies.Option) {
+	cookies.SetCookie(c, name, value, expire, opts...)
+}
+
+// Queue queues a cookie to be added to the response of the request.
+func (cf *CookieFacade) Queue(c *fiber.Ctx, name string, value string, opts ...cookies.Option) {
+	cookies.Queue(c, name, value, opts...)
+}
+
+// Forget queues the removal of a cookie from the client.
+func (cf *CookieFacade) Forget(c *fiber.Ctx, name string, opts ...cookies.Option) {
+	cookies.Forget(c, name, opts...)
 }
 
 // Get retrieves a cookie by name.
@@ -20,6 +30,26 @@ func (cf *CookieFacade) Get(c *fiber.Ctx, name string) (string, error) {
 	return cookies.GetCookie(c, name)
 }
 
+// SetEncrypted sets a cookie whose value is encrypted with the application key.
+func (cf *CookieFacade) SetEncrypted(c *fiber.Ctx, name string, value string, expire time.Time) error {
+	return cookies.SetEncrypted(c, name, value, expire)
+}
+
+// GetEncrypted retrieves and decrypts an encrypted cookie.
+func (cf *CookieFacade) GetEncrypted(c *fiber.Ctx, name string) (string, error) {
+	return cookies.GetEncrypted(c, name)
+}
+
+// SetSigned sets a cookie whose value is signed with the application key.
+func (cf *CookieFacade) SetSigned(c *fiber.Ctx, name string, value string, expire time.Time) error {
+	return cookies.SetSigned(c, name, value, expire)
+}
+
+// GetSigned retrieves a signed cookie and verifies its signature.
+func (cf *CookieFacade) GetSigned(c *fiber.Ctx, name string) (string, error) {
+	return cookies.GetSigned(c, name)
+}
+
 func NewCookieFacade() *CookieFacade {
 	return &CookieFacade{}
 }
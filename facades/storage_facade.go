@@ -2,7 +2,10 @@ package facades
 
 import (
 	"io"
+	"mime/multipart"
 
+	"github.com/gofiber/fiber/v2"
+	"github.com/mousav1/weiser/app/file"
 	"github.com/mousav1/weiser/app/storage"
 )
 
@@ -24,11 +27,21 @@ func (sf *StorageFacade) Put(path string, content io.ReadSeeker) error {
 	return storage.DefaultDriver.Put(path, content)
 }
 
+// PutStream محتوای یک stream را در درایور پیش‌فرض ذخیره می‌کند
+func (sf *StorageFacade) PutStream(path string, content io.Reader) error {
+	return storage.DefaultDriver.PutStream(path, content)
+}
+
 // Get فایل را از درایور پیش‌فرض دریافت می‌کند
 func (sf *StorageFacade) Get(path string) (io.Reader, error) {
 	return storage.DefaultDriver.Get(path)
 }
 
+// ReadStream فایل را از درایور پیش‌فرض به صورت stream باز می‌کند؛ فراخواننده باید آن را Close کند
+func (sf *StorageFacade) ReadStream(path string) (io.ReadCloser, error) {
+	return storage.DefaultDriver.ReadStream(path)
+}
+
 // Delete فایل را از درایور پیش‌فرض حذف می‌کند
 func (sf *StorageFacade) Delete(path string) error {
 	return storage.DefaultDriver.Delete(path)
@@ -44,6 +57,31 @@ func (sf *StorageFacade) List(directory string) ([]string, error) {
 	return storage.DefaultDriver.List(directory)
 }
 
+// Files مسیر فایل‌های دایرکتوری در درایور پیش‌فرض را برمی‌گرداند
+func (sf *StorageFacade) Files(directory string, recursive bool) ([]string, error) {
+	return storage.DefaultDriver.Files(directory, recursive)
+}
+
+// Directories مسیر زیردایرکتوری‌های دایرکتوری در درایور پیش‌فرض را برمی‌گرداند
+func (sf *StorageFacade) Directories(directory string) ([]string, error) {
+	return storage.DefaultDriver.Directories(directory)
+}
+
+// ListContents یک صفحه از فهرست دایرکتوری در درایور پیش‌فرض را برمی‌گرداند
+func (sf *StorageFacade) ListContents(directory string, recursive bool, cursor string, limit int) (storage.Page, error) {
+	return storage.DefaultDriver.ListContents(directory, recursive, cursor, limit)
+}
+
+// Iterator فهرست دایرکتوری در درایور پیش‌فرض را صفحه به صفحه پیمایش می‌کند
+func (sf *StorageFacade) Iterator(directory string, recursive bool, pageSize int) *storage.Iterator {
+	return storage.NewIterator(storage.DefaultDriver, directory, recursive, pageSize)
+}
+
+// Glob فایل‌های منطبق با الگو را در درایور پیش‌فرض پیدا می‌کند
+func (sf *StorageFacade) Glob(pattern string) ([]string, error) {
+	return storage.Glob(storage.DefaultDriver, pattern)
+}
+
 // URL ایجاد URL دائمی برای دسترسی به فایل در درایور پیش‌فرض
 func (sf *StorageFacade) URL(path string) (string, error) {
 	return storage.DefaultDriver.URL(path)
@@ -59,6 +97,21 @@ func (sf *StorageFacade) Size(path string) (int64, error) {
 	return storage.DefaultDriver.Size(path)
 }
 
+// Metadata اطلاعات فایل را در درایور پیش‌فرض برمی‌گرداند
+func (sf *StorageFacade) Metadata(path string) (storage.Metadata, error) {
+	return storage.DefaultDriver.Metadata(path)
+}
+
+// SetVisibility سطح دسترسی فایل را در درایور پیش‌فرض تنظیم می‌کند
+func (sf *StorageFacade) SetVisibility(path string, visibility storage.Visibility) error {
+	return storage.DefaultDriver.SetVisibility(path, visibility)
+}
+
+// Visibility سطح دسترسی فایل را در درایور پیش‌فرض برمی‌گرداند
+func (sf *StorageFacade) Visibility(path string) (storage.Visibility, error) {
+	return storage.DefaultDriver.Visibility(path)
+}
+
 // Copy فایل را از مسیر مبدا به مسیر مقصد در درایور پیش‌فرض کپی می‌کند
 func (sf *StorageFacade) Copy(sourcePath string, destinationPath string) error {
 	return storage.DefaultDriver.Copy(sourcePath, destinationPath)
@@ -68,3 +121,42 @@ func (sf *StorageFacade) Copy(sourcePath string, destinationPath string) error {
 func (sf *StorageFacade) Move(sourcePath string, destinationPath string) error {
 	return storage.DefaultDriver.Move(sourcePath, destinationPath)
 }
+
+// MakeDirectory یک دایرکتوری در درایور پیش‌فرض ایجاد می‌کند
+func (sf *StorageFacade) MakeDirectory(path string) error {
+	return storage.DefaultDriver.MakeDirectory(path)
+}
+
+// DeleteDirectory دایرکتوری و محتویات آن را از درایور پیش‌فرض حذف می‌کند
+func (sf *StorageFacade) DeleteDirectory(path string) error {
+	return storage.DefaultDriver.DeleteDirectory(path)
+}
+
+// ReadRange بخشی از فایل را از درایور پیش‌فرض می‌خواند؛ length منفی یعنی تا انتهای فایل
+func (sf *StorageFacade) ReadRange(path string, offset int64, length int64) (io.ReadCloser, error) {
+	return storage.ReadRange(storage.DefaultDriver, path, offset, length)
+}
+
+// Stat اطلاعات فایل را بدون خواندن محتوای آن از درایور پیش‌فرض برمی‌گرداند
+func (sf *StorageFacade) Stat(path string) (storage.Metadata, error) {
+	return storage.Stat(storage.DefaultDriver, path)
+}
+
+// Download فایل را از درایور پیش‌فرض به صورت stream و با پشتیبانی از Range و درخواست‌های شرطی ارسال می‌کند
+func (sf *StorageFacade) Download(c *fiber.Ctx, path string, options file.DownloadOptions) error {
+	return file.Respond(c, storage.DefaultDriver, path, options)
+}
+
+// Uploader سرویس آپلود را بر اساس پیکربندی upload برمی‌گرداند
+func (sf *StorageFacade) Uploader() (*file.Uploader, error) {
+	return file.UploaderFromConfig()
+}
+
+// Upload فایل فرم را با نام یکتا و با بررسی محدودیت‌های اندازه و نوع در دیسک آپلود ذخیره می‌کند
+func (sf *StorageFacade) Upload(header *multipart.FileHeader) (file.UploadedFile, error) {
+	uploader, err := file.UploaderFromConfig()
+	if err != nil {
+		return file.UploadedFile{}, err
+	}
+	return uploader.Store(header)
+}
@@ -3,12 +3,24 @@ package main
 import (
 	"fmt"
 	"log"
+	"os"
 
+	"github.com/mousav1/weiser/app/console"
 	"github.com/mousav1/weiser/bootstrap"
 	"github.com/spf13/viper"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		if err := bootstrap.SetupConsole(os.Args[1:]); err != nil {
+			log.Fatalf("failed to set up the console: %s", err)
+		}
+		if err := console.Run(os.Args[1:]); err != nil {
+			log.Fatalf("%s", err)
+		}
+		return
+	}
+
 	app, server, err := bootstrap.SetupApp()
 	if err != nil {
 		log.Fatalf("failed to set up the application: %s", err)
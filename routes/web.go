@@ -5,8 +5,12 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
 	"github.com/mousav1/weiser/app/controllers"
 	"github.com/mousav1/weiser/app/cookies"
+	"github.com/mousav1/weiser/app/file"
+	middleware "github.com/mousav1/weiser/app/http/middlewares"
+	"github.com/mousav1/weiser/app/imaging"
 	"github.com/mousav1/weiser/app/repositories"
 	"github.com/mousav1/weiser/app/services"
 	"github.com/pkg/errors"
@@ -45,6 +49,46 @@ func SetupRoutes(app *fiber.App, db *gorm.DB) error {
 	app.Get("/set-session", homeController.SetSessionData)
 	app.Get("/get-session", homeController.GetSessionData)
 
+	metricsController := controllers.NewMetricsController()
+	if metricsController == nil {
+		return errors.New("failed to create metrics controller")
+	}
+
+	// Requires a session signed in through SessionManager.Login, which the
+	// application's login route has to call.
+	app.Get("/metrics/cache", middleware.Authenticate, metricsController.Cache)
+
+	uploader, err := file.UploaderFromConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to create uploader")
+	}
+
+	// Uploads write to the disk, so only users signed in through
+	// SessionManager.Login may send them. Until the application has a login
+	// route that calls it, these routes answer 401.
+	// Only starting an upload is rate limited; the HEAD, PATCH and DELETE
+	// requests of a resumable upload are as many as its chunks.
+	uploadMiddleware := []fiber.Handler{
+		middleware.Authenticate,
+		middleware.VerifyCSRFToken,
+		limiter.New(limiter.Config{
+			Max:        30,
+			Expiration: time.Minute,
+			Next: func(c *fiber.Ctx) bool {
+				return c.Method() != fiber.MethodPost
+			},
+		}),
+	}
+	app.Post("/upload", append(uploadMiddleware, uploader.Handler())...)
+	uploader.Resumable(app.Group("/uploads", uploadMiddleware...))
+
+	images, err := imaging.ServiceFromConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to create image service")
+	}
+
+	app.Get("/images/:variant/*", images.Handler())
+
 	app.Get("/set", func(c *fiber.Ctx) error {
 		username := "123"
 		expire := time.Now().Add(24 * time.Hour)